@@ -1,8 +1,16 @@
 package rest
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"math/big"
 	"net/http"
+	"net/url"
 	"reflect"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -19,16 +27,144 @@ func WithApplyCustomSchemaToType(f func(t reflect.Type, s *openapi3.Schema)) API
 	}
 }
 
+// WithApplyCustomSchemaToField enables customisation of struct fields in the OpenAPI specification,
+// e.g. based on struct tags. The function is called with the struct type, the field, and the schema
+// of the field.
+// Fields that reference a component schema are not passed to the function, since changes
+// to the component would apply to every use of it.
+func WithApplyCustomSchemaToField(f func(t reflect.Type, f reflect.StructField, s *openapi3.Schema)) APIOpts {
+	return func(api *API) {
+		api.ApplyCustomSchemaToField = f
+	}
+}
+
+// WithPathNormalization normalizes route patterns as they're registered.
+func WithPathNormalization(n PathNormalization) APIOpts {
+	return func(api *API) {
+		api.PathNormalization = n
+	}
+}
+
+// WithVersion sets the version of the API, e.g. "v1.0.0".
+func WithVersion(version string) APIOpts {
+	return func(api *API) {
+		api.Version = version
+	}
+}
+
+// WithAPIDescription sets the description of the API.
+func WithAPIDescription(description string) APIOpts {
+	return func(api *API) {
+		api.Description = description
+	}
+}
+
+// WithContact sets the contact information for the API.
+func WithContact(name, url, email string) APIOpts {
+	return func(api *API) {
+		api.Contact = &openapi3.Contact{
+			Name:  name,
+			URL:   url,
+			Email: email,
+		}
+	}
+}
+
+// WithLicense sets the license of the API.
+func WithLicense(name, url string) APIOpts {
+	return func(api *API) {
+		api.License = &openapi3.License{
+			Name: name,
+			URL:  url,
+		}
+	}
+}
+
+// WithTermsOfService sets the URL of the terms of service of the API.
+func WithTermsOfService(url string) APIOpts {
+	return func(api *API) {
+		api.TermsOfService = url
+	}
+}
+
+// WithLogger sets the logger used to report problems found while creating the
+// specification, such as unsupported parameter types.
+func WithLogger(log *slog.Logger) APIOpts {
+	return func(api *API) {
+		api.Logger = log
+	}
+}
+
+// WithEmbeddedPointerPolicy sets whether fields promoted from embedded struct pointers can be required.
+func WithEmbeddedPointerPolicy(p EmbeddedPointerPolicy) APIOpts {
+	return func(api *API) {
+		api.EmbeddedPointerPolicy = p
+	}
+}
+
+// WithOptionalityPolicy sets how pointers and the omitempty option of json tags map to
+// required and nullable properties.
+func WithOptionalityPolicy(p OptionalityPolicy) APIOpts {
+	return func(api *API) {
+		api.OptionalityPolicy = p
+	}
+}
+
+// WithNullableReferences makes pointer fields that reference component schemas nullable,
+// if they don't have the omitempty option, since nil pointers are sent as null. OpenAPI
+// 3.0 ignores nullable next to a $ref, so the reference is wrapped in an allOf:
+//
+//	manager:
+//	  nullable: true
+//	  allOf:
+//	  - $ref: '#/components/schemas/User'
+func WithNullableReferences() APIOpts {
+	return func(api *API) {
+		api.NullableReferences = true
+	}
+}
+
+// WithEmbeddedInterfacePolicy sets how embedded interfaces are added to the schema.
+func WithEmbeddedInterfacePolicy(p EmbeddedInterfacePolicy) APIOpts {
+	return func(api *API) {
+		api.EmbeddedInterfacePolicy = p
+	}
+}
+
+// WithInlinePolicy sets when schemas are added to the components of the specification
+// and referenced, and when they're inlined.
+func WithInlinePolicy(p InlinePolicy) APIOpts {
+	return func(api *API) {
+		api.InlinePolicy = p
+	}
+}
+
+// WithDurationFormat sets how time.Duration values are represented in the specification.
+func WithDurationFormat(f DurationFormat) APIOpts {
+	return func(api *API) {
+		api.KnownTypes[reflect.TypeOf(time.Duration(0))] = newDurationSchema(f)
+	}
+}
+
 // NewAPI creates a new API from the router.
 func NewAPI(name string, opts ...APIOpts) *API {
 	api := &API{
 		Name:       name,
-		KnownTypes: defaultKnownTypes,
+		KnownTypes: maps.Clone(defaultKnownTypes),
 		Routes:     make(map[Pattern]MethodToRoute),
 		// map of model name to schema.
-		models:        make(map[string]*openapi3.Schema),
-		comments:      make(map[string]map[string]string),
-		visitedModels: make(map[string]bool),
+		models:       make(map[string]*openapi3.Schema),
+		modelTypes:   make(map[string]reflect.Type),
+		overrides:    make(map[reflect.Type][]SchemaOpts),
+		modelNames:   make(map[reflect.Type]string),
+		externalRefs: make(map[reflect.Type]string),
+		coverage: &coverage{
+			called:       make(map[string]int),
+			undocumented: make(map[string]int),
+		},
+		metrics:  newMetrics(),
+		matcher:  &routeMatcher{},
+		comments: make(map[string]map[string]string),
 	}
 	for _, o := range opts {
 		o(api)
@@ -37,8 +173,25 @@ func NewAPI(name string, opts ...APIOpts) *API {
 }
 
 var defaultKnownTypes = map[reflect.Type]openapi3.Schema{
-	reflect.TypeOf(time.Time{}):  *openapi3.NewDateTimeSchema(),
-	reflect.TypeOf(&time.Time{}): *openapi3.NewDateTimeSchema().WithNullable(),
+	reflect.TypeOf(time.Time{}):      *openapi3.NewDateTimeSchema(),
+	reflect.TypeOf(&time.Time{}):     *openapi3.NewDateTimeSchema().WithNullable(),
+	reflect.TypeOf(time.Duration(0)): newDurationSchema(DurationNanoseconds),
+	reflect.TypeOf(big.Rat{}):        *newRatSchema(),
+	reflect.TypeOf(&big.Rat{}):       *newRatSchema().WithNullable(),
+}
+
+// durationPattern matches the strings accepted by time.ParseDuration.
+const durationPattern = `^([-+]?0|[-+]?([0-9]*(\.[0-9]*)?(ns|us|µs|μs|ms|s|m|h))+)$`
+
+func newDurationSchema(f DurationFormat) openapi3.Schema {
+	if f == DurationString {
+		s := openapi3.NewStringSchema().WithPattern(durationPattern)
+		s.Example = "1h30m"
+		return *s
+	}
+	s := openapi3.NewInt64Schema()
+	s.Example = int64(90 * time.Minute)
+	return *s
 }
 
 // Route models a single API route.
@@ -51,12 +204,56 @@ type Route struct {
 	Params Params
 	// Models used in the route.
 	Models Models
+	// RequestBody configures the request body of the route.
+	RequestBody RequestBody
 	// Tags used in the route.
 	Tags []string
 	// OperationID for the route.
 	OperationID string
 	// Description for the route.
 	Description string
+	// ResponseDescriptions maps from HTTP status code to the description of the response.
+	ResponseDescriptions map[int]string
+	// Entity managed by the route, and the operation that the route performs on it,
+	// used by SpecWithEntityExtensions. If empty, they're inferred.
+	Entity          string
+	EntityOperation EntityOperation
+	// Extensions added to the operation, e.g. x-amazon-apigateway-integration.
+	Extensions map[string]any
+	// RateLimit of the route, see HasRateLimit.
+	RateLimit *RateLimit
+	// IdempotencyKeyRequired is true if requests must have an Idempotency-Key header,
+	// see RequiresIdempotencyKey.
+	IdempotencyKeyRequired bool
+	// ConditionalRequests is true if the route supports the ETag, If-Match and If-None-Match
+	// headers, see SupportsConditionalRequests.
+	ConditionalRequests bool
+	// FieldsModel is the model whose properties can be selected with the fields query
+	// parameter, see HasFieldsParameter.
+	FieldsModel *Model
+	// Sort configures the sort query parameter, see HasSortParameter.
+	Sort *Sort
+	// Batch is true if the route processes a batch of items, see IsBatchEndpoint.
+	Batch bool
+	// Security requirements of the route, see HasSecurity. Any one of them must be met.
+	Security []SecurityRequirement
+	// XML is true if the request and response bodies can also be XML, see HasXML.
+	XML bool
+	// ResponseEnvelope wraps the responses of the route, instead of the API's envelope,
+	// see HasResponseEnvelope.
+	ResponseEnvelope *Model
+	// NoResponseEnvelope is true if the responses of the route aren't wrapped in the API's
+	// envelope, see HasNoResponseEnvelope.
+	NoResponseEnvelope bool
+	// ErrorCodes that the route may fail with, see MayFailWith.
+	ErrorCodes []string
+
+	// registeredPattern is the pattern prior to normalization.
+	registeredPattern string
+	// registered is true if the route was added using Route, rather than Merge.
+	registered bool
+	// matcher of the API, which is rebuilt when the path parameters change.
+	matcher *routeMatcher
 }
 
 // Params is a route parameter.
@@ -76,8 +273,13 @@ type PathParam struct {
 	// Regexp is a regular expression used to validate the param.
 	// An empty string means that no validation is applied.
 	Regexp string
-	// Type of the param (string, number, integer, boolean).
+	// Type of the param (string, number, integer, boolean), or a type added with
+	// RegisterPrimitiveType.
 	Type PrimitiveType
+	// Default value of the param, used when the param is not provided.
+	Default any
+	// Example value of the param.
+	Example any
 	// ApplyCustomSchema customises the OpenAPI schema for the path parameter.
 	ApplyCustomSchema func(s *openapi3.Parameter)
 }
@@ -93,12 +295,111 @@ type QueryParam struct {
 	Required bool
 	// AllowEmpty sets whether the querystring parameter can be empty.
 	AllowEmpty bool
-	// Type of the param (string, number, integer, boolean).
+	// Type of the param (string, number, integer, boolean), or a type added with
+	// RegisterPrimitiveType.
 	Type PrimitiveType
+	// Default value of the param, used when the param is not provided.
+	Default any
+	// Example value of the param.
+	Example any
 	// ApplyCustomSchema customises the OpenAPI schema for the query parameter.
 	ApplyCustomSchema func(s *openapi3.Parameter)
 }
 
+// PathNormalization configures how route patterns are normalized when
+// routes are registered.
+type PathNormalization struct {
+	// CollapseSlashes replaces repeated slashes with a single slash, e.g. //users becomes /users.
+	CollapseSlashes bool
+	// TrailingSlash sets whether a trailing slash is removed or added.
+	TrailingSlash TrailingSlash
+	// Lowercase converts the path to lowercase. Parameter names and regular
+	// expressions within placeholders are not modified.
+	Lowercase bool
+}
+
+// TrailingSlash sets how trailing slashes in route patterns are handled.
+type TrailingSlash int
+
+const (
+	// TrailingSlashUnchanged leaves trailing slashes as they are.
+	TrailingSlashUnchanged TrailingSlash = iota
+	// TrailingSlashStrip removes trailing slashes, e.g. /users/ becomes /users.
+	TrailingSlashStrip
+	// TrailingSlashEnforce adds a trailing slash, e.g. /users becomes /users/.
+	TrailingSlashEnforce
+)
+
+// EmbeddedPointerPolicy sets whether fields promoted from embedded struct pointers,
+// e.g. struct { *Address }, can be required.
+type EmbeddedPointerPolicy int
+
+const (
+	// EmbeddedPointerOptional makes all fields promoted from embedded struct pointers optional,
+	// since the fields are omitted from JSON when the pointer is nil.
+	EmbeddedPointerOptional EmbeddedPointerPolicy = iota
+	// EmbeddedPointerRequired keeps the required fields of the embedded struct.
+	EmbeddedPointerRequired
+)
+
+// OptionalityPolicy sets how pointers and the omitempty option of json tags map to
+// required and nullable properties.
+type OptionalityPolicy int
+
+const (
+	// OptionalityPointerOrOmitEmpty makes fields optional if they're pointers, or have
+	// the omitempty option. Pointers, slices and maps are nullable.
+	OptionalityPointerOrOmitEmpty OptionalityPolicy = iota
+	// OptionalityOmitEmpty matches the behaviour of encoding/json. Fields are optional
+	// only if they have the omitempty option, and aren't nullable, since nil values are
+	// omitted rather than sent as null. Pointers without omitempty are required, and
+	// nullable.
+	OptionalityOmitEmpty
+)
+
+// EmbeddedInterfacePolicy sets how embedded interfaces, e.g. struct { fmt.Stringer },
+// are added to the schema.
+type EmbeddedInterfacePolicy int
+
+const (
+	// EmbeddedInterfaceError returns an error, since the schema of the interface value is unknown.
+	EmbeddedInterfaceError EmbeddedInterfacePolicy = iota
+	// EmbeddedInterfaceSkip omits embedded interfaces from the schema.
+	EmbeddedInterfaceSkip
+	// EmbeddedInterfaceAny adds embedded interfaces to the schema as a property that
+	// can have any value.
+	EmbeddedInterfaceAny
+)
+
+// InlinePolicy sets when schemas are added to the components of the specification
+// and referenced, and when they're inlined.
+type InlinePolicy int
+
+const (
+	// ReferenceObjectsAndEnums references structs and enums, and inlines everything else.
+	ReferenceObjectsAndEnums InlinePolicy = iota
+	// AlwaysReference references all objects, including maps, enums and named types.
+	AlwaysReference
+	// InlineSmall inlines structs that have 3 or fewer properties.
+	InlineSmall
+	// InlineAnonymous inlines anonymous structs, e.g. struct { A string }.
+	InlineAnonymous
+)
+
+// DurationFormat sets how time.Duration values are represented in the specification.
+type DurationFormat int
+
+const (
+	// DurationNanoseconds represents durations as an integer number of nanoseconds,
+	// which is how encoding/json marshals time.Duration.
+	DurationNanoseconds DurationFormat = iota
+	// DurationString represents durations in the format used by time.ParseDuration,
+	// e.g. "1h30m", for types that marshal durations using their String method.
+	DurationString
+)
+
+// PrimitiveType is the type of a parameter. Other types can be added with
+// RegisterPrimitiveType.
 type PrimitiveType string
 
 const (
@@ -122,6 +423,16 @@ type Pattern string
 type API struct {
 	// Name of the API.
 	Name string
+	// Version of the API. Defaults to 0.0.0.
+	Version string
+	// Description of the API.
+	Description string
+	// Contact information for the API.
+	Contact *openapi3.Contact
+	// License of the API.
+	License *openapi3.License
+	// TermsOfService is a URL to the terms of service of the API.
+	TermsOfService string
 	// Routes of the API.
 	// From patterns, to methods, to route.
 	Routes map[Pattern]MethodToRoute
@@ -143,6 +454,7 @@ type API struct {
 	// KnownTypes are added to the OpenAPI specification output.
 	// The default implementation:
 	//   Maps time.Time to a string.
+	//   Maps big.Rat to a string, e.g. "617/50".
 	KnownTypes map[reflect.Type]openapi3.Schema
 
 	// comments from the package. This can be cleared once the spec has been created.
@@ -153,8 +465,128 @@ type API struct {
 	// Apply customisations to all types by ignoring the t parameter.
 	ApplyCustomSchemaToType func(t reflect.Type, s *openapi3.Schema)
 
-	// Map of types were processed in model registration
-	visitedModels map[string]bool
+	// ApplyCustomSchemaToField callback to customise the OpenAPI specification for a struct field.
+	ApplyCustomSchemaToField func(t reflect.Type, f reflect.StructField, s *openapi3.Schema)
+
+	// PathNormalization applied to route patterns as they're registered.
+	PathNormalization PathNormalization
+
+	// InlinePolicy sets when schemas are referenced, and when they're inlined.
+	InlinePolicy InlinePolicy
+
+	// EmbeddedPointerPolicy sets whether fields promoted from embedded struct pointers can be required.
+	EmbeddedPointerPolicy EmbeddedPointerPolicy
+
+	// EmbeddedInterfacePolicy sets how embedded interfaces are added to the schema.
+	EmbeddedInterfacePolicy EmbeddedInterfacePolicy
+
+	// OptionalityPolicy sets how pointers and omitempty map to required and nullable properties.
+	OptionalityPolicy OptionalityPolicy
+
+	// FormatInference sets the format of string fields from their names, see WithFormatInference.
+	FormatInference bool
+
+	// Int64Encoding sets how int64 and uint64 fields are represented, see WithInt64Encoding.
+	Int64Encoding Int64Encoding
+
+	// SensitiveWriteOnly makes fields with the sensitive tag writeOnly, see WithSensitiveWriteOnly.
+	SensitiveWriteOnly bool
+
+	// GormTagMapping adds the constraints in gorm and db tags to schemas, see WithGormTagMapping.
+	GormTagMapping bool
+
+	// SQLCModels documents nullable database/sql types as nullable values, see WithSQLCModels.
+	SQLCModels bool
+
+	// EntModels adapts the entities generated by ent, see WithEntModels.
+	EntModels bool
+
+	// NullableReferences wraps references to component schemas in a nullable allOf when
+	// the field can be null, see WithNullableReferences.
+	NullableReferences bool
+
+	// Logger used to report problems found while creating the specification.
+	// If nil, problems are not logged.
+	Logger *slog.Logger
+
+	// StrictMode returns an error from Spec if any warnings are found.
+	StrictMode bool
+
+	// SkipUnsupportedFields omits fields with unsupported types, instead of returning an error.
+	SkipUnsupportedFields bool
+
+	// SkipRequiredCheck allows schemas to list required properties that don't exist.
+	SkipRequiredCheck bool
+
+	// PruneSchemas removes component schemas that aren't used by any route from the output of Spec.
+	PruneSchemas bool
+
+	// SplitReadWriteSchemas replaces schemas with readOnly or writeOnly properties with
+	// Request and Response variants, see WithSplitReadWriteSchemas.
+	SplitReadWriteSchemas bool
+
+	// HALLinks adds a _links property to the object schemas of responses, see WithHALLinks.
+	HALLinks bool
+
+	// ResponseEnvelope wraps the responses of routes, see WithResponseEnvelope.
+	ResponseEnvelope *Model
+
+	// ValidationErrorResponse is added to routes with constraints on their requests, see
+	// WithValidationErrorResponse.
+	ValidationErrorResponse *ValidationErrorResponse
+
+	// CORS policy of the API, documented on each operation, see WithCORS.
+	CORS *CORSPolicy
+
+	// TagPackages are packages whose package comments describe the tags with the same
+	// name, see WithTagPackages.
+	TagPackages []string
+
+	// SecuritySchemes of the API by name, see WithSecurityScheme.
+	SecuritySchemes map[string]*openapi3.SecurityScheme
+
+	// GatewayProfiles add the extensions required by API gateways to the output of Spec.
+	GatewayProfiles []GatewayProfile
+
+	// IncludeSpecHash adds the hash of the specification to its info, as x-spec-hash.
+	IncludeSpecHash bool
+
+	// errorCodes is the catalog of error codes, see RegisterErrorCode.
+	errorCodes map[string]ErrorCode
+
+	// warnings found while registering models and creating the specification.
+	warnings []Warning
+	// warned is the set of warnings, used to remove duplicates.
+	warned map[Warning]bool
+
+	// modelTypes maps from model name to the type that the model was created from.
+	modelTypes map[string]reflect.Type
+	// modelNames maps from types to schema names that aren't derived from the type, e.g.
+	// of the models of a component library.
+	modelNames map[reflect.Type]string
+	// modelNameCache caches the schema names derived from types, which depend on
+	// StripPkgPaths, so it's cleared when they change.
+	modelNameCache map[reflect.Type]string
+	// modelNameCachePkgPaths are the StripPkgPaths of the names in modelNameCache.
+	modelNameCachePkgPaths []string
+	// externalRefs maps from types to references to their schemas in other documents.
+	externalRefs map[reflect.Type]string
+
+	// overrides applied to types after they're generated, see OverrideModel.
+	overrides map[reflect.Type][]SchemaOpts
+	// derived maps from the names of component schemas derived from models while the
+	// spec is generated, e.g. by PickOf, to their schemas.
+	derived map[string]derivedComponent
+
+	// coverage of the API's operations, see CoverageMiddleware.
+	coverage *coverage
+	// metrics of the API, see MetricsHandler.
+	metrics *metrics
+	// matcher matches requests to routes, for middleware.
+	matcher *routeMatcher
+
+	// errs found while registering routes, returned by Spec.
+	errs []error
 }
 
 // Merge route data into the existing configuration.
@@ -162,13 +594,41 @@ type API struct {
 // to take information that the router already knows and add it
 // to the specification.
 func (api *API) Merge(r Route) {
-	toUpdate := api.Route(string(r.Method), string(r.Pattern))
+	toUpdate := api.upsertRoute(string(r.Method), string(r.Pattern))
 	mergeMap(toUpdate.Params.Path, r.Params.Path)
 	mergeMap(toUpdate.Params.Query, r.Params.Query)
 	if toUpdate.Models.Request.Type == nil {
 		toUpdate.Models.Request = r.Models.Request
+		toUpdate.RequestBody = r.RequestBody
 	}
 	mergeMap(toUpdate.Models.Responses, r.Models.Responses)
+	mergeMap(toUpdate.ResponseDescriptions, r.ResponseDescriptions)
+	mergeMap(toUpdate.Extensions, r.Extensions)
+	if toUpdate.RateLimit == nil {
+		toUpdate.RateLimit = r.RateLimit
+	}
+	toUpdate.IdempotencyKeyRequired = toUpdate.IdempotencyKeyRequired || r.IdempotencyKeyRequired
+	toUpdate.ConditionalRequests = toUpdate.ConditionalRequests || r.ConditionalRequests
+	if toUpdate.FieldsModel == nil {
+		toUpdate.FieldsModel = r.FieldsModel
+	}
+	if toUpdate.Sort == nil {
+		toUpdate.Sort = r.Sort
+	}
+	toUpdate.Batch = toUpdate.Batch || r.Batch
+	if len(toUpdate.Security) == 0 {
+		toUpdate.Security = r.Security
+	}
+	toUpdate.XML = toUpdate.XML || r.XML
+	if toUpdate.ResponseEnvelope == nil && !toUpdate.NoResponseEnvelope {
+		toUpdate.ResponseEnvelope = r.ResponseEnvelope
+		toUpdate.NoResponseEnvelope = r.NoResponseEnvelope
+	}
+	for _, code := range r.ErrorCodes {
+		if !slices.Contains(toUpdate.ErrorCodes, code) {
+			toUpdate.ErrorCodes = append(toUpdate.ErrorCodes, code)
+		}
+	}
 }
 
 func mergeMap[TKey comparable, TValue any](into, from map[TKey]TValue) {
@@ -182,15 +642,59 @@ func mergeMap[TKey comparable, TValue any](into, from map[TKey]TValue) {
 
 // Spec creates an OpenAPI 3.0 specification document for the API.
 func (api *API) Spec() (spec *openapi3.T, err error) {
+	errs := slices.Concat(api.errs, api.getRouteConflicts())
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	start := time.Now()
+	defer func() { api.metrics.recordSpec(time.Since(start)) }()
 	spec, err = api.createOpenAPI()
 	if err != nil {
 		return
 	}
+	if api.PruneSchemas {
+		pruneUnusedSchemas(spec)
+	}
+	if err = api.applyGatewayProfiles(spec); err != nil {
+		return nil, err
+	}
+	if api.IncludeSpecHash {
+		hash, err := getSpecHash(spec)
+		if err != nil {
+			return nil, err
+		}
+		if spec.Info.Extensions == nil {
+			spec.Info.Extensions = make(map[string]any)
+		}
+		spec.Info.Extensions[specHashExtension] = hash
+	}
+	if api.StrictMode && len(api.warnings) > 0 {
+		errs := make([]error, len(api.warnings))
+		for i, w := range api.warnings {
+			errs[i] = w
+		}
+		return nil, fmt.Errorf("strict mode: %w", errors.Join(errs...))
+	}
 	return
 }
 
-// Route upserts a route to the API definition.
+// Route adds a route to the API definition.
+// The pattern is normalized according to the PathNormalization of the API.
+// Adding the same method and pattern more than once is an error, which is returned
+// by Spec, unless the route was added by Merge.
 func (api *API) Route(method, pattern string) (r *Route) {
+	r = api.upsertRoute(method, pattern)
+	if r.registered && r.registeredPattern == pattern {
+		api.errs = append(api.errs, fmt.Errorf("duplicate route %s %q", method, pattern))
+	}
+	r.registered = true
+	return r
+}
+
+// upsertRoute returns the route, adding it if it doesn't exist.
+func (api *API) upsertRoute(method, pattern string) (r *Route) {
+	registeredPattern := pattern
+	pattern = api.PathNormalization.normalize(pattern)
 	methodToRoute, ok := api.Routes[Pattern(pattern)]
 	if !ok {
 		methodToRoute = make(MethodToRoute)
@@ -199,21 +703,203 @@ func (api *API) Route(method, pattern string) (r *Route) {
 	route, ok := methodToRoute[Method(method)]
 	if !ok {
 		route = &Route{
-			Method:  Method(method),
-			Pattern: Pattern(pattern),
+			registeredPattern: registeredPattern,
+			matcher:           api.matcher,
+			Method:            Method(method),
+			Pattern:           Pattern(pattern),
 			Models: Models{
 				Responses: make(map[int]Model),
 			},
+			ResponseDescriptions: make(map[int]string),
+			Extensions:           make(map[string]any),
 			Params: Params{
-				Path:  make(map[string]PathParam),
-				Query: make(map[string]QueryParam),
+				Path:  getPathParams(pattern),
+				Query: getQueryParams(pattern),
 			},
 		}
 		methodToRoute[Method(method)] = route
+		api.matcher.invalidate()
+	}
+	if route.registeredPattern != registeredPattern {
+		api.errs = append(api.errs, fmt.Errorf("route %s %q conflicts with %s %q, both normalize to %q", method, registeredPattern, method, route.registeredPattern, pattern))
 	}
 	return route
 }
 
+// getRouteConflicts finds routes that would produce the same operation in the specification,
+// e.g. GET /users?sort=asc and GET /users, and paths that differ only by the
+// names of their placeholders, e.g. /users/{id} and /users/{userId}.
+func (api *API) getRouteConflicts() (errs []error) {
+	operationToPatterns := make(map[string][]Pattern)
+	templateToPaths := make(map[string][]string)
+	for _, pattern := range getSortedKeys(api.Routes) {
+		path := getPath(pattern)
+		for _, method := range getSortedKeys(api.Routes[pattern]) {
+			operation := string(method) + " " + path
+			operationToPatterns[operation] = append(operationToPatterns[operation], pattern)
+		}
+		template := getPathTemplate(path)
+		if !slices.Contains(templateToPaths[template], path) {
+			templateToPaths[template] = append(templateToPaths[template], path)
+		}
+	}
+	for _, operation := range getSortedKeys(operationToPatterns) {
+		if patterns := operationToPatterns[operation]; len(patterns) > 1 {
+			errs = append(errs, fmt.Errorf("duplicate route %s, registered with patterns %q", operation, patterns))
+		}
+	}
+	for _, template := range getSortedKeys(templateToPaths) {
+		if paths := templateToPaths[template]; len(paths) > 1 {
+			errs = append(errs, fmt.Errorf("overlapping paths %q only differ by parameter names", paths))
+		}
+	}
+	return errs
+}
+
+// getPathTemplate replaces the placeholders in the path with {}, e.g. /users/{id} becomes /users/{}.
+func getPathTemplate(path string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			break
+		}
+		end := findPlaceholderEnd(path, start)
+		if end < 0 {
+			break
+		}
+		sb.WriteString(path[:start])
+		sb.WriteString("{}")
+		path = path[end+1:]
+	}
+	sb.WriteString(path)
+	return sb.String()
+}
+
+func (n PathNormalization) normalize(pattern string) string {
+	path, query, hasQuery := strings.Cut(pattern, "?")
+	if n.CollapseSlashes {
+		for strings.Contains(path, "//") {
+			path = strings.ReplaceAll(path, "//", "/")
+		}
+	}
+	switch n.TrailingSlash {
+	case TrailingSlashStrip:
+		if path != "/" {
+			path = strings.TrimRight(path, "/")
+		}
+	case TrailingSlashEnforce:
+		if !strings.HasSuffix(path, "/") {
+			path += "/"
+		}
+	}
+	if n.Lowercase {
+		path = lowercaseOutsidePlaceholders(path)
+	}
+	if hasQuery {
+		return path + "?" + query
+	}
+	return path
+}
+
+func lowercaseOutsidePlaceholders(path string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			break
+		}
+		end := findPlaceholderEnd(path, start)
+		if end < 0 {
+			break
+		}
+		sb.WriteString(strings.ToLower(path[:start]))
+		sb.WriteString(path[start : end+1])
+		path = path[end+1:]
+	}
+	sb.WriteString(strings.ToLower(path))
+	return sb.String()
+}
+
+// getPathParams extracts the path parameters from placeholders in the pattern,
+// e.g. /organisation/{orgId:\d+}/user/{userId}.
+func getPathParams(pattern string) (params map[string]PathParam) {
+	params = make(map[string]PathParam)
+	path, _, _ := strings.Cut(pattern, "?")
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			return params
+		}
+		end := findPlaceholderEnd(path, start)
+		if end < 0 {
+			return params
+		}
+		name, regexp, _ := strings.Cut(path[start+1:end], ":")
+		params[name] = PathParam{
+			Regexp: regexp,
+			Type:   getPrimitiveTypeOfRegexp(regexp),
+		}
+		path = path[end+1:]
+	}
+}
+
+// getQueryParams extracts optional string query parameters from the querystring
+// of the pattern, e.g. /users?orgId=123&orderBy=field.
+func getQueryParams(pattern string) (params map[string]QueryParam) {
+	params = make(map[string]QueryParam)
+	_, query, ok := strings.Cut(pattern, "?")
+	if !ok {
+		return params
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return params
+	}
+	for k := range values {
+		params[k] = QueryParam{
+			Type: PrimitiveTypeString,
+		}
+	}
+	return params
+}
+
+// getPath returns the path of the pattern, without any querystring.
+func getPath(pattern Pattern) string {
+	path, _, _ := strings.Cut(string(pattern), "?")
+	return path
+}
+
+// findPlaceholderEnd returns the index of the brace that closes the placeholder
+// opened at start, allowing for braces within the regular expression, e.g. {code:[a-z]{3}}.
+func findPlaceholderEnd(s string, start int) int {
+	var depth int
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// integerRegexps are regular expressions that only match integers.
+var integerRegexps = []string{`\d+`, `[0-9]+`, `^\d+$`, `^[0-9]+$`}
+
+func getPrimitiveTypeOfRegexp(regexp string) PrimitiveType {
+	for _, r := range integerRegexps {
+		if regexp == r {
+			return PrimitiveTypeInteger
+		}
+	}
+	return ""
+}
+
 // Get defines a GET request route for the given pattern.
 func (api *API) Get(pattern string) (r *Route) {
 	return api.Route(http.MethodGet, pattern)
@@ -260,30 +946,94 @@ func (api *API) Trace(pattern string) (r *Route) {
 }
 
 // HasResponseModel configures a response for the route.
+// ModelOpts and ModelOptsErr customise the schema of the response for this route only.
 // Example:
 //
-//	api.Get("/user").HasResponseModel(http.StatusOK, rest.ModelOf[User]())
-func (rm *Route) HasResponseModel(status int, response Model) *Route {
+//	api.Get("/user").HasResponseModel(http.StatusOK, rest.ModelOf[User](), rest.WithDescription("The user."))
+func (rm *Route) HasResponseModel(status int, response Model, opts ...SchemaOpts) *Route {
+	response.opts = append(slices.Clip(response.opts), opts...)
 	rm.Models.Responses[status] = response
 	return rm
 }
 
-// HasResponseModel configures the request model of the route.
+// HasResponseDescription sets the description of the response with the given status.
+// Example:
+//
+//	api.Get("/user").HasResponseDescription(http.StatusNotFound, "The user was not found.")
+func (rm *Route) HasResponseDescription(status int, description string) *Route {
+	rm.ResponseDescriptions[status] = description
+	return rm
+}
+
+// HasRequestModel configures the request model of the route.
+// ModelOpts and ModelOptsErr can be passed to customise the schema of the request for
+// this route only.
 // Example:
 //
-//	api.Post("/user").HasRequestModel(http.StatusOK, rest.ModelOf[User]())
-func (rm *Route) HasRequestModel(request Model) *Route {
+//	api.Post("/user").HasRequestModel(rest.ModelOf[User](), rest.RequestRequired())
+func (rm *Route) HasRequestModel(request Model, opts ...RequestOpts) *Route {
 	rm.Models.Request = request
+	for _, o := range opts {
+		o.applyToRequest(rm)
+	}
 	return rm
 }
 
+// RequestBody configures the request body of a route.
+type RequestBody struct {
+	// Description of the request body.
+	Description string
+	// Required is true if the request body must be provided.
+	Required bool
+}
+
+// RequestOpts defines options that can be set on the request body of a route.
+// ModelOpts and ModelOptsErr are also RequestOpts, and customise the schema of the request.
+type RequestOpts interface {
+	applyToRequest(r *Route)
+}
+
+type requestBodyOpts func(r *RequestBody)
+
+func (o requestBodyOpts) applyToRequest(r *Route) {
+	o(&r.RequestBody)
+}
+
+// RequestRequired marks the request body as required.
+func RequestRequired() RequestOpts {
+	return requestBodyOpts(func(r *RequestBody) {
+		r.Required = true
+	})
+}
+
+// RequestDescription sets the description of the request body.
+func RequestDescription(description string) RequestOpts {
+	return requestBodyOpts(func(r *RequestBody) {
+		r.Description = description
+	})
+}
+
 // HasPathParameter configures a path parameter for the route.
+// Path parameters are automatically extracted from the route pattern, so
+// the Regexp and Type fields only need to be set to override the values
+// taken from the pattern.
 func (rm *Route) HasPathParameter(name string, p PathParam) *Route {
+	if existing, ok := rm.Params.Path[name]; ok {
+		if p.Regexp == "" {
+			p.Regexp = existing.Regexp
+		}
+		if p.Type == "" {
+			p.Type = existing.Type
+		}
+	}
 	rm.Params.Path[name] = p
+	rm.matcher.invalidate()
 	return rm
 }
 
 // HasQueryParameter configures a query parameter for the route.
+// Query parameters in the querystring of the route pattern are automatically
+// added as optional string parameters, and are replaced by this call.
 func (rm *Route) HasQueryParameter(name string, q QueryParam) *Route {
 	rm.Params.Query[name] = q
 	return rm
@@ -301,6 +1051,12 @@ func (rm *Route) HasOperationID(operationID string) *Route {
 	return rm
 }
 
+// HasExtension adds a specification extension to the operation, e.g. x-internal.
+func (rm *Route) HasExtension(name string, value any) *Route {
+	rm.Extensions[name] = value
+	return rm
+}
+
 // HasDescription sets the description for the route.
 func (rm *Route) HasDescription(description string) *Route {
 	rm.Description = description
@@ -322,6 +1078,12 @@ func ModelOf[T any]() Model {
 	if sm, ok := any(t).(CustomSchemaApplier); ok {
 		m.s = sm.ApplyCustomSchema
 	}
+	if sm, ok := any(t).(CustomSchemaApplierErr); ok {
+		m.sErr = sm.ApplyCustomSchemaErr
+	}
+	if sm, ok := any(t).(ContextualSchemaApplier); ok {
+		m.sCtx = sm.ApplyContextualSchema
+	}
 	return m
 }
 
@@ -332,6 +1094,12 @@ func modelFromType(t reflect.Type) Model {
 	if sm, ok := reflect.New(t).Interface().(CustomSchemaApplier); ok {
 		m.s = sm.ApplyCustomSchema
 	}
+	if sm, ok := reflect.New(t).Interface().(CustomSchemaApplierErr); ok {
+		m.sErr = sm.ApplyCustomSchemaErr
+	}
+	if sm, ok := reflect.New(t).Interface().(ContextualSchemaApplier); ok {
+		m.sCtx = sm.ApplyContextualSchema
+	}
 	return m
 }
 
@@ -340,12 +1108,34 @@ type CustomSchemaApplier interface {
 	ApplyCustomSchema(s *openapi3.Schema)
 }
 
+// CustomSchemaApplierErr is a type that customises its OpenAPI schema, and can fail.
+// The error is returned by RegisterModel and Spec. If a type implements both
+// CustomSchemaApplier and CustomSchemaApplierErr, ApplyCustomSchema is called first.
+type CustomSchemaApplierErr interface {
+	ApplyCustomSchemaErr(s *openapi3.Schema) error
+}
+
 var _ CustomSchemaApplier = Model{}
 
 // Model is a model used in one or more routes.
 type Model struct {
 	Type reflect.Type
 	s    func(s *openapi3.Schema)
+	sErr func(s *openapi3.Schema) error
+	sCtx func(ctx SchemaContext, s *openapi3.Schema)
+	// opts customise the schema of the model where it's used in a route.
+	opts []SchemaOpts
+	// contentType of the model in requests and responses. Defaults to application/json.
+	contentType string
+	// wrap the schema of the model where it's used in a route, e.g. in an envelope.
+	wrap func(api *API, ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error)
+}
+
+func (m Model) getContentType() string {
+	if m.contentType == "" {
+		return "application/json"
+	}
+	return m.contentType
 }
 
 func (m Model) ApplyCustomSchema(s *openapi3.Schema) {
@@ -354,3 +1144,12 @@ func (m Model) ApplyCustomSchema(s *openapi3.Schema) {
 	}
 	m.s(s)
 }
+
+// applyCustomSchema applies the customisation of the model's type, if it has any.
+func (m Model) applyCustomSchema(s *openapi3.Schema) error {
+	m.ApplyCustomSchema(s)
+	if m.sErr == nil {
+		return nil
+	}
+	return m.sErr(s)
+}
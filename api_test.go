@@ -0,0 +1,284 @@
+package rest
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPathNormalization(t *testing.T) {
+	tests := []struct {
+		name          string
+		normalization PathNormalization
+		patterns      []string
+		expected      []Pattern
+		expectErr     bool
+	}{
+		{
+			name:     "patterns are unchanged by default",
+			patterns: []string{"//Users/", "/users"},
+			expected: []Pattern{"//Users/", "/users"},
+		},
+		{
+			name:          "duplicate slashes can be collapsed",
+			normalization: PathNormalization{CollapseSlashes: true},
+			patterns:      []string{"//users///{id}"},
+			expected:      []Pattern{"/users/{id}"},
+		},
+		{
+			name:          "trailing slashes can be stripped",
+			normalization: PathNormalization{TrailingSlash: TrailingSlashStrip},
+			patterns:      []string{"/users/", "/", "/topics/?sort=asc"},
+			expected:      []Pattern{"/", "/topics?sort=asc", "/users"},
+		},
+		{
+			name:          "trailing slashes can be enforced",
+			normalization: PathNormalization{TrailingSlash: TrailingSlashEnforce},
+			patterns:      []string{"/users", "/topics/"},
+			expected:      []Pattern{"/topics/", "/users/"},
+		},
+		{
+			name:          "paths can be lowercased without changing placeholders",
+			normalization: PathNormalization{Lowercase: true},
+			patterns:      []string{`/Organisation/{orgId:[A-Z]{3}}/Users?Sort=asc`},
+			expected:      []Pattern{`/organisation/{orgId:[A-Z]{3}}/users?Sort=asc`},
+		},
+		{
+			name:          "patterns that normalize to the same route conflict",
+			normalization: PathNormalization{TrailingSlash: TrailingSlashStrip},
+			patterns:      []string{"/users", "/users/"},
+			expected:      []Pattern{"/users"},
+			expectErr:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("test", WithPathNormalization(test.normalization))
+			for _, pattern := range test.patterns {
+				api.Get(pattern).HasResponseModel(http.StatusOK, ModelOf[OK]())
+			}
+
+			if diff := cmp.Diff(test.expected, getSortedKeys(api.Routes)); diff != "" {
+				t.Error(diff)
+			}
+
+			_, err := api.Spec()
+			if test.expectErr && err == nil {
+				t.Error("expected error, got nil")
+			}
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRouteConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(api *API)
+		expected string
+	}{
+		{
+			name: "the same method and pattern can only be registered once",
+			setup: func(api *API) {
+				api.Get("/users/{id}").HasResponseModel(http.StatusOK, ModelOf[User]())
+				api.Get("/users/{id}").HasDescription("Get a user.")
+				api.Delete("/users/{id}").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Get("/users/{id}")
+			},
+			expected: `duplicate route GET "/users/{id}"
+duplicate route GET "/users/{id}"`,
+		},
+		{
+			name: "merged routes can be documented",
+			setup: func(api *API) {
+				api.Merge(Route{Method: http.MethodGet, Pattern: "/users/{id}"})
+				api.Get("/users/{id}").HasResponseModel(http.StatusOK, ModelOf[User]())
+			},
+		},
+		{
+			name: "methods can use patterns that differ by querystring",
+			setup: func(api *API) {
+				api.Get("/users?sort=asc").HasResponseModel(http.StatusOK, ModelOf[User]())
+				api.Post("/users").HasResponseModel(http.StatusOK, ModelOf[OK]())
+			},
+		},
+		{
+			name: "the same method and path can only be registered once",
+			setup: func(api *API) {
+				api.Get("/users?sort=asc").HasResponseModel(http.StatusOK, ModelOf[User]())
+				api.Get("/users").HasResponseModel(http.StatusOK, ModelOf[User]())
+			},
+			expected: `duplicate route GET /users, registered with patterns ["/users" "/users?sort=asc"]`,
+		},
+		{
+			name: "paths can't only differ by parameter names",
+			setup: func(api *API) {
+				api.Get("/users/{id}").HasResponseModel(http.StatusOK, ModelOf[User]())
+				api.Delete("/users/{userId}").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Get("/users/{id}/roles/{role}").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Put("/users/{userId}/roles/{roleId:\\d+}").HasResponseModel(http.StatusOK, ModelOf[OK]())
+			},
+			expected: `overlapping paths ["/users/{id}" "/users/{userId}"] only differ by parameter names
+overlapping paths ["/users/{id}/roles/{role}" "/users/{userId}/roles/{roleId:\\d+}"] only differ by parameter names`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("test")
+			test.setup(api)
+			_, err := api.Spec()
+			var actual string
+			if err != nil {
+				actual = err.Error()
+			}
+			if diff := cmp.Diff(test.expected, actual); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+	api := NewAPI("test", WithLogger(log))
+	api.Get("/users/{id}").
+		HasPathParameter("id", PathParam{
+			Type: "uuid",
+		}).
+		HasResponseModel(http.StatusOK, ModelOf[User]())
+	// The unknown type fails validation, but the log explains the cause.
+	if _, err := api.Spec(); err == nil {
+		t.Error("expected validation error, got nil")
+	}
+	expected := `level=WARN msg="path parameter \"id\" has unknown type \"uuid\"" kind=unknown-parameter-type location="GET /users/{id}"`
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected log to contain %q, got %q", expected, buf.String())
+	}
+}
+
+// withUnexportedTaggedField is created with reflection, because go vet reports
+// unexported fields with json tags.
+var withUnexportedTaggedField = reflect.StructOf([]reflect.StructField{
+	{Name: "A", Type: reflect.TypeOf(""), Tag: `json:"a"`},
+	{Name: "b", Type: reflect.TypeOf(""), Tag: `json:"b"`, PkgPath: "github.com/heimspiel/rest"},
+})
+
+func TestWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(api *API)
+		expected []Warning
+	}{
+		{
+			name: "no warnings",
+			setup: func(api *API) {
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					HasResponseDescription(http.StatusOK, "The user.")
+			},
+		},
+		{
+			name: "missing response description",
+			setup: func(api *API) {
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+			},
+			expected: []Warning{
+				{Kind: WarningMissingResponseDescription, Location: "GET /users", Message: "response 200 has no description"},
+			},
+		},
+		{
+			name: "unexported field with json tag",
+			setup: func(api *API) {
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, modelFromType(withUnexportedTaggedField)).
+					HasResponseDescription(http.StatusOK, "OK")
+			},
+			expected: []Warning{
+				{Kind: WarningSkippedField, Location: withUnexportedTaggedField.String(), Message: `field "b" has a json tag, but is not exported`},
+			},
+		},
+		{
+			name: "name collision",
+			setup: func(api *API) {
+				api.Get("/a").
+					HasResponseModel(http.StatusOK, func() Model {
+						type Collision struct{ A string }
+						return ModelOf[Collision]()
+					}()).
+					HasResponseDescription(http.StatusOK, "OK")
+				api.Get("/b").
+					HasResponseModel(http.StatusOK, func() Model {
+						type Collision struct{ B string }
+						return ModelOf[Collision]()
+					}()).
+					HasResponseDescription(http.StatusOK, "OK")
+			},
+			expected: []Warning{
+				{Kind: WarningNameCollision, Location: "Collision", Message: `types "rest.Collision" and "rest.Collision" have the same schema name`},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("test")
+			api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+			test.setup(api)
+			if _, err := api.Spec(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, api.Warnings()); diff != "" {
+				t.Error(diff)
+			}
+
+			// In strict mode, warnings are returned as errors.
+			strict := NewAPI("test", WithStrictMode())
+			strict.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+			test.setup(strict)
+			_, err := strict.Spec()
+			for _, w := range test.expected {
+				var target Warning
+				if !errors.As(err, &target) {
+					t.Fatalf("expected strict mode error to contain warnings, got %v", err)
+				}
+				if !strings.Contains(err.Error(), w.Error()) {
+					t.Errorf("expected strict mode error to contain %q, got %q", w.Error(), err.Error())
+				}
+			}
+			if len(test.expected) == 0 && err != nil {
+				t.Errorf("unexpected strict mode error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetSortedMethods(t *testing.T) {
+	api := NewAPI("test")
+	for _, method := range []string{"PURGE", http.MethodDelete, http.MethodPost, "LINK", http.MethodGet} {
+		api.Route(method, "/")
+	}
+	expected := []Method{http.MethodGet, http.MethodPost, http.MethodDelete, "LINK", "PURGE"}
+	if diff := cmp.Diff(expected, getSortedMethods(api.Routes["/"])); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestModelNamesFollowStripPkgPaths(t *testing.T) {
+	api := NewAPI("test")
+	if name, _, _ := api.RegisterModel(ModelOf[User]()); name != "github_com_heimspiel_rest_User" {
+		t.Errorf("unexpected name %q", name)
+	}
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+	if name, _, _ := api.RegisterModel(ModelOf[User]()); name != "User" {
+		t.Errorf("expected the name to change with StripPkgPaths, got %q", name)
+	}
+}
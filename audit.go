@@ -0,0 +1,90 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// AuditReport lists the differences between the routes handled by a router, and the
+// routes of an API.
+type AuditReport struct {
+	// Undocumented routes are handled by the router, but aren't in the API.
+	Undocumented []string
+	// Unhandled routes are in the API, but aren't handled by the router.
+	Unhandled []string
+}
+
+// Err returns an error listing the differences, or nil if there are none, e.g. to fail
+// a test.
+func (r AuditReport) Err() error {
+	var errs []error
+	for _, route := range r.Undocumented {
+		errs = append(errs, fmt.Errorf("%s is handled by the router, but isn't documented", route))
+	}
+	for _, route := range r.Unhandled {
+		errs = append(errs, fmt.Errorf("%s is documented, but isn't handled by the router", route))
+	}
+	return errors.Join(errs...)
+}
+
+// Audit compares the routes of the API with knownRoutes, the routes registered on the
+// HTTP router, e.g. "GET /users/{id}". Routes match if they have the same method, and
+// the same path apart from the names and regular expressions of placeholders, so
+// /users/{id:\d+} matches /users/{userID}. Use chiadapter.Routes to get the routes of
+// a chi router. Trailing slashes are ignored.
+func Audit(api *API, knownRoutes []string) (report AuditReport) {
+	known := make(map[string]string, len(knownRoutes))
+	for _, route := range knownRoutes {
+		method, path, _ := strings.Cut(route, " ")
+		known[api.getRouteShape(method, path)] = route
+	}
+	documented := make(map[string]bool)
+	for _, pattern := range getSortedKeys(api.Routes) {
+		methodToRoute := api.Routes[pattern]
+		for _, method := range getSortedMethods(methodToRoute) {
+			shape := api.getRouteShape(string(method), getPath(pattern))
+			documented[shape] = true
+			if _, ok := known[shape]; !ok {
+				report.Unhandled = append(report.Unhandled, getOperation(methodToRoute[method]))
+			}
+		}
+	}
+	for shape, route := range known {
+		if !documented[shape] {
+			report.Undocumented = append(report.Undocumented, route)
+		}
+	}
+	slices.Sort(report.Undocumented)
+	return report
+}
+
+// getRouteShape returns the method and path with the placeholders replaced by {},
+// so that routes can be compared regardless of the names given to placeholders.
+// Trailing slashes are ignored, since routers such as chi add them to the routes
+// of subrouters.
+func (api *API) getRouteShape(method, path string) string {
+	path = api.PathNormalization.normalize(path)
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	var sb strings.Builder
+	sb.WriteString(strings.ToUpper(method))
+	sb.WriteString(" ")
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			break
+		}
+		end := findPlaceholderEnd(path, start)
+		if end < 0 {
+			break
+		}
+		sb.WriteString(path[:start])
+		sb.WriteString("{}")
+		path = path[end+1:]
+	}
+	sb.WriteString(path)
+	return sb.String()
+}
@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAudit(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/users")
+	api.Get(`/users/{id:\d+}`)
+	api.Delete("/users/{id}")
+
+	report := Audit(api, []string{
+		"GET /users",
+		"GET /users/{userID}",
+		"POST /users",
+		"GET /metrics",
+	})
+
+	expected := AuditReport{
+		Undocumented: []string{"GET /metrics", "POST /users"},
+		Unhandled:    []string{"DELETE /users/{id}"},
+	}
+	if diff := cmp.Diff(expected, report); diff != "" {
+		t.Error(diff)
+	}
+	if report.Err() == nil {
+		t.Error("expected an error")
+	}
+	if err := Audit(api, []string{"GET /users", "GET /users/{id}", "DELETE /users/{id}"}).Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// awsIntegrationExtension is the extension that API Gateway reads integrations from.
+const awsIntegrationExtension = "x-amazon-apigateway-integration"
+
+// AWSIntegration is an API Gateway integration, see HasAWSIntegration.
+type AWSIntegration interface {
+	awsIntegration(rm *Route) map[string]any
+}
+
+// AWSProxyIntegration proxies requests to a Lambda function.
+type AWSProxyIntegration struct {
+	// LambdaARN of the function, e.g. arn:aws:lambda:eu-west-1:123456789012:function:users.
+	LambdaARN string
+	// Region of API Gateway. Defaults to the region of the function.
+	Region string
+	// CredentialsARN is the IAM role that API Gateway assumes to invoke the function.
+	// If empty, the function's resource policy must allow API Gateway to invoke it.
+	CredentialsARN string
+	// TimeoutMillis is the integration timeout. If zero, API Gateway's default is used.
+	TimeoutMillis int
+}
+
+func (i AWSProxyIntegration) awsIntegration(rm *Route) map[string]any {
+	region := i.Region
+	if region == "" {
+		// arn:aws:lambda:<region>:<account>:function:<name>
+		if parts := strings.Split(i.LambdaARN, ":"); len(parts) > 3 {
+			region = parts[3]
+		}
+	}
+	ext := map[string]any{
+		"type": "aws_proxy",
+		// Lambda functions are always invoked with POST.
+		"httpMethod":          http.MethodPost,
+		"uri":                 fmt.Sprintf("arn:aws:apigateway:%s:lambda:path/2015-03-31/functions/%s/invocations", region, i.LambdaARN),
+		"passthroughBehavior": "when_no_match",
+	}
+	setAWSOptions(ext, i.CredentialsARN, i.TimeoutMillis)
+	return ext
+}
+
+// HTTPProxyIntegration proxies requests to a HTTP endpoint, passing the route's path
+// parameters through.
+type HTTPProxyIntegration struct {
+	// URI of the endpoint, which can contain the route's path parameters,
+	// e.g. https://users.example.com/users/{id}.
+	URI string
+	// VPCLinkID routes requests through a VPC link, for endpoints in a private network.
+	VPCLinkID string
+	// TimeoutMillis is the integration timeout. If zero, API Gateway's default is used.
+	TimeoutMillis int
+}
+
+func (i HTTPProxyIntegration) awsIntegration(rm *Route) map[string]any {
+	ext := map[string]any{
+		"type":                "http_proxy",
+		"httpMethod":          string(rm.Method),
+		"uri":                 i.URI,
+		"passthroughBehavior": "when_no_match",
+	}
+	if i.VPCLinkID != "" {
+		ext["connectionType"] = "VPC_LINK"
+		ext["connectionId"] = i.VPCLinkID
+	}
+	if len(rm.Params.Path) > 0 {
+		requestParameters := make(map[string]any)
+		for _, name := range getSortedKeys(rm.Params.Path) {
+			requestParameters["integration.request.path."+name] = "method.request.path." + name
+		}
+		ext["requestParameters"] = requestParameters
+	}
+	setAWSOptions(ext, "", i.TimeoutMillis)
+	return ext
+}
+
+func setAWSOptions(ext map[string]any, credentialsARN string, timeoutMillis int) {
+	if credentialsARN != "" {
+		ext["credentials"] = credentialsARN
+	}
+	if timeoutMillis > 0 {
+		ext["timeoutInMillis"] = timeoutMillis
+	}
+}
+
+// HasAWSIntegration adds an x-amazon-apigateway-integration extension to the operation,
+// so that the specification can be imported into Amazon API Gateway.
+func (rm *Route) HasAWSIntegration(integration AWSIntegration) *Route {
+	return rm.HasExtension(awsIntegrationExtension, integration.awsIntegration(rm))
+}
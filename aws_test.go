@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestHasAWSIntegration(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/users/{id}").
+		HasResponseModel(http.StatusOK, ModelOf[User]()).
+		HasAWSIntegration(AWSProxyIntegration{
+			LambdaARN:     "arn:aws:lambda:eu-west-1:123456789012:function:users",
+			TimeoutMillis: 5000,
+		})
+	api.Delete("/users/{id}").
+		HasResponseModel(http.StatusOK, ModelOf[OK]()).
+		HasAWSIntegration(HTTPProxyIntegration{
+			URI:       "https://users.example.com/users/{id}",
+			VPCLinkID: "abc123",
+		})
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	pathItem := spec.Paths.Value("/users/{id}")
+
+	expectedLambda := map[string]any{
+		"type":                "aws_proxy",
+		"httpMethod":          "POST",
+		"uri":                 "arn:aws:apigateway:eu-west-1:lambda:path/2015-03-31/functions/arn:aws:lambda:eu-west-1:123456789012:function:users/invocations",
+		"passthroughBehavior": "when_no_match",
+		"timeoutInMillis":     5000,
+	}
+	if diff := cmp.Diff(expectedLambda, pathItem.Get.Extensions["x-amazon-apigateway-integration"]); diff != "" {
+		t.Error(diff)
+	}
+
+	expectedHTTP := map[string]any{
+		"type":                "http_proxy",
+		"httpMethod":          "DELETE",
+		"uri":                 "https://users.example.com/users/{id}",
+		"passthroughBehavior": "when_no_match",
+		"connectionType":      "VPC_LINK",
+		"connectionId":        "abc123",
+		"requestParameters": map[string]any{
+			"integration.request.path.id": "method.request.path.id",
+		},
+	}
+	if diff := cmp.Diff(expectedHTTP, pathItem.Delete.Extensions["x-amazon-apigateway-integration"]); diff != "" {
+		t.Error(diff)
+	}
+}
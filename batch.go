@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// BatchRequest is the request body of a batch endpoint, containing the items to process.
+type BatchRequest[T any] struct {
+	// Items to process, in order.
+	Items []T `json:"items"`
+}
+
+// ApplyCustomSchema requires at least one item.
+func (BatchRequest[T]) ApplyCustomSchema(s *openapi3.Schema) {
+	if items := s.Properties["items"]; items != nil && items.Value != nil {
+		items.Value.MinItems = 1
+		items.Value.Nullable = false
+	}
+}
+
+func (BatchRequest[T]) isBatchRequest() {}
+
+// BatchResponse is the response body of a batch endpoint, containing the result of each
+// item in the request.
+type BatchResponse[T any] struct {
+	// Results of the items, in the same order as the items of the request.
+	Items []BatchResult[T] `json:"items"`
+}
+
+func (BatchResponse[T]) isBatchResponse() {}
+
+// BatchResult is the result of processing a single item of a batch request.
+type BatchResult[T any] struct {
+	// Index of the item in the request.
+	Index int `json:"index"`
+	// Status is the HTTP status code of the item, e.g. 201 if it was created.
+	Status int `json:"status"`
+	// Data is the item that was processed, if it was successful.
+	Data *T `json:"data,omitempty"`
+	// Errors that prevented the item from being processed.
+	Errors []BatchError `json:"errors,omitempty"`
+}
+
+// BatchError is an error that prevented an item of a batch request from being processed.
+type BatchError struct {
+	// Code identifies the type of error.
+	Code string `json:"code"`
+	// Message describes the error.
+	Message string `json:"message"`
+	// Field is the JSON pointer to the field of the item that caused the error, if any.
+	Field string `json:"field,omitempty"`
+}
+
+// BatchRequestOf creates a model of a batch request containing items of type T.
+func BatchRequestOf[T any]() Model {
+	return ModelOf[BatchRequest[T]]()
+}
+
+// BatchResponseOf creates a model of a batch response containing the results of items of type T.
+func BatchResponseOf[T any]() Model {
+	return ModelOf[BatchResponse[T]]()
+}
+
+type batchRequest interface{ isBatchRequest() }
+type batchResponse interface{ isBatchResponse() }
+
+// IsBatchEndpoint marks the route as a batch endpoint. The request model must be a
+// BatchRequest, and the successful responses must be BatchResponses, which is checked
+// when the spec is generated. If no response is documented for 207 Multi-Status, it's
+// added, for when some items succeed and others fail.
+func (rm *Route) IsBatchEndpoint() *Route {
+	rm.Batch = true
+	return rm
+}
+
+// multiStatusDescription is the description of the 207 Multi-Status response of batch
+// endpoints.
+const multiStatusDescription = "Some items were processed and others failed, see the status of each item."
+
+// checkBatchEndpoint checks that the models of a batch endpoint are batch envelopes,
+// and returns the status of the successful response that the 207 Multi-Status response
+// is documented like.
+func checkBatchEndpoint(route *Route) (status int, err error) {
+	if !implements[batchRequest](route.Models.Request.Type) {
+		return 0, fmt.Errorf("batch endpoint %s %s must have a BatchRequest request model", route.Method, route.Pattern)
+	}
+	for _, s := range getSortedKeys(route.Models.Responses) {
+		if s < 200 || s > 299 {
+			continue
+		}
+		model := route.Models.Responses[s]
+		if !implements[batchResponse](model.Type) {
+			return 0, fmt.Errorf("batch endpoint %s %s must have BatchResponse models for successful responses, but %d is %v", route.Method, route.Pattern, s, model.Type)
+		}
+		status = s
+	}
+	if status == 0 {
+		return 0, fmt.Errorf("batch endpoint %s %s has no successful response model", route.Method, route.Pattern)
+	}
+	return status, nil
+}
+
+// addMultiStatusResponse documents the 207 Multi-Status response of a batch endpoint with
+// the content of its successful response, unless the route has its own.
+func addMultiStatusResponse(op *openapi3.Operation, route *Route, status int) {
+	if op.Responses.Status(http.StatusMultiStatus) != nil {
+		return
+	}
+	content := make(openapi3.Content)
+	for contentType, mt := range op.Responses.Status(status).Value.Content {
+		copied := *mt
+		content[contentType] = &copied
+	}
+	description := route.ResponseDescriptions[http.StatusMultiStatus]
+	if description == "" {
+		description = multiStatusDescription
+	}
+	op.AddResponse(http.StatusMultiStatus, openapi3.NewResponse().WithDescription(description).WithContent(content))
+}
+
+func implements[I any](t reflect.Type) bool {
+	return t != nil && t.Implements(reflect.TypeFor[I]())
+}
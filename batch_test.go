@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBatchEndpointRequiresBatchModels(t *testing.T) {
+	api := NewAPI("test")
+	api.Post("/users/batch").
+		HasRequestModel(ModelOf[[]User]()).
+		HasResponseModel(http.StatusOK, BatchResponseOf[User]()).
+		HasResponseDescription(http.StatusOK, "The users were created.").
+		IsBatchEndpoint()
+
+	_, err := api.Spec()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "must have a BatchRequest request model") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBatchEndpointDoesNotChangeRoute(t *testing.T) {
+	api := NewAPI("test")
+	route := api.Post("/users/batch").
+		HasRequestModel(BatchRequestOf[User]()).
+		HasResponseModel(http.StatusOK, BatchResponseOf[User]()).
+		HasResponseDescription(http.StatusOK, "The users were created.").
+		IsBatchEndpoint()
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Paths.Value("/users/batch").Post.Responses.Status(http.StatusMultiStatus) == nil {
+		t.Error("expected a 207 response")
+	}
+	if _, ok := route.Models.Responses[http.StatusMultiStatus]; ok {
+		t.Error("expected the route's responses to be unchanged")
+	}
+}
@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newBenchmarkTypes creates n struct types that use a set of shared named types.
+func newBenchmarkTypes(n int) (types []reflect.Type) {
+	shared := []reflect.Type{
+		reflect.TypeOf(User{}),
+		reflect.TypeOf(WithEnums{}),
+		reflect.TypeOf(TreeNode{}),
+		reflect.TypeOf(WithMaps{}),
+		reflect.TypeOf(AllBasicDataTypes{}),
+	}
+	for i := 0; i < n; i++ {
+		fields := []reflect.StructField{
+			{Name: "ID", Type: reflect.TypeOf(""), Tag: reflect.StructTag(fmt.Sprintf(`json:"id%d"`, i))},
+			{Name: "Created", Type: reflect.TypeOf(time.Time{}), Tag: `json:"created"`},
+			{Name: "Tags", Type: reflect.TypeOf([]string{}), Tag: `json:"tags"`},
+			{Name: "Labels", Type: reflect.TypeOf(map[string]string{}), Tag: `json:"labels"`},
+		}
+		for j, t := range shared {
+			fields = append(fields,
+				reflect.StructField{Name: fmt.Sprintf("Shared%d", j), Type: t, Tag: reflect.StructTag(fmt.Sprintf(`json:"shared%d"`, j))},
+				reflect.StructField{Name: fmt.Sprintf("SharedList%d", j), Type: reflect.SliceOf(t), Tag: reflect.StructTag(fmt.Sprintf(`json:"sharedList%d"`, j))},
+			)
+		}
+		types = append(types, reflect.StructOf(fields))
+	}
+	return types
+}
+
+func benchmarkSpec(b *testing.B, models int) {
+	types := newBenchmarkTypes(models)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		api := NewAPI("benchmark")
+		for j, t := range types {
+			api.Post(fmt.Sprintf("/models/%d", j)).
+				HasRequestModel(modelFromType(t)).
+				HasResponseModel(http.StatusOK, modelFromType(t)).
+				HasResponseModel(http.StatusBadRequest, ModelOf[OK]())
+		}
+		if _, err := api.Spec(); err != nil {
+			b.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func BenchmarkSpec10(b *testing.B)  { benchmarkSpec(b, 10) }
+func BenchmarkSpec100(b *testing.B) { benchmarkSpec(b, 100) }
+func BenchmarkSpec900(b *testing.B) { benchmarkSpec(b, 900) }
@@ -0,0 +1,251 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// defaultMaxCaptureBytes is the largest body captured by default.
+const defaultMaxCaptureBytes = 64 * 1024
+
+// ExampleCapture records JSON request and response bodies of an API's operations, so that
+// real traffic can be used as examples in the specification, or as test fixtures.
+// The first body seen for each request and response of an operation is kept.
+type ExampleCapture struct {
+	// Sample returns true if the request should be captured. If nil, all requests are
+	// captured until each operation has examples.
+	Sample func(r *http.Request) bool
+	// Redact is called with each captured body, decoded from JSON, and returns the
+	// body to store, e.g. with sensitive values removed.
+	Redact func(operation string, body any) any
+	// MaxBodyBytes is the largest body that's captured. Defaults to 64KiB.
+	MaxBodyBytes int
+
+	api      *API
+	m        sync.Mutex
+	examples map[string]*CapturedExamples
+}
+
+// CapturedExamples are the examples captured for an operation.
+type CapturedExamples struct {
+	// Request body of the operation.
+	Request any `json:"request,omitempty"`
+	// Responses maps from HTTP status code to the response body.
+	Responses map[int]any `json:"responses,omitempty"`
+}
+
+// NewExampleCapture creates an ExampleCapture for the API. Add its Middleware to the
+// router to capture examples.
+func NewExampleCapture(api *API) *ExampleCapture {
+	return &ExampleCapture{
+		api:      api,
+		examples: make(map[string]*CapturedExamples),
+	}
+}
+
+// Middleware captures the bodies of requests to documented operations.
+func (c *ExampleCapture) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := c.api.matchRoute(r.Method, r.URL.Path)
+		if !ok || (c.Sample != nil && !c.Sample(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		operation := getOperation(route)
+		maxBytes := c.maxBodyBytes()
+		if r.Body != nil && r.Body != http.NoBody && !c.hasRequest(operation) {
+			// Read at most one byte more than is captured, and leave the rest of the
+			// body to the handler.
+			body, err := io.ReadAll(io.LimitReader(r.Body, int64(maxBytes)+1))
+			r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+			if err == nil {
+				c.capture(operation, 0, body)
+			}
+		}
+		cw := &captureResponseWriter{
+			ResponseWriter: w,
+			status:         http.StatusOK,
+			max:            maxBytes,
+			captured:       func(status int) bool { return c.hasResponse(operation, status) },
+		}
+		next.ServeHTTP(cw, r)
+		if !cw.skip {
+			c.capture(operation, cw.status, cw.body.Bytes())
+		}
+	})
+}
+
+// hasRequest returns true if a request body of the operation was captured.
+func (c *ExampleCapture) hasRequest(operation string) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+	examples, ok := c.examples[operation]
+	return ok && examples.Request != nil
+}
+
+// hasResponse returns true if a response body of the operation with the status was captured.
+func (c *ExampleCapture) hasResponse(operation string, status int) bool {
+	c.m.Lock()
+	defer c.m.Unlock()
+	examples, ok := c.examples[operation]
+	if !ok {
+		return false
+	}
+	_, ok = examples.Responses[status]
+	return ok
+}
+
+func (c *ExampleCapture) maxBodyBytes() int {
+	if c.MaxBodyBytes > 0 {
+		return c.MaxBodyBytes
+	}
+	return defaultMaxCaptureBytes
+}
+
+// capture stores the body of a request, if status is zero, or a response.
+func (c *ExampleCapture) capture(operation string, status int, body []byte) {
+	if len(body) == 0 || len(body) > c.maxBodyBytes() {
+		return
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return
+	}
+	if c.Redact != nil {
+		v = c.Redact(operation, v)
+	}
+	c.m.Lock()
+	defer c.m.Unlock()
+	examples, ok := c.examples[operation]
+	if !ok {
+		examples = &CapturedExamples{Responses: make(map[int]any)}
+		c.examples[operation] = examples
+	}
+	if status == 0 {
+		if examples.Request == nil {
+			examples.Request = v
+		}
+		return
+	}
+	if _, ok := examples.Responses[status]; !ok {
+		examples.Responses[status] = v
+	}
+}
+
+// Examples returns the examples captured, keyed by operation, e.g. "GET /users/{id}".
+func (c *ExampleCapture) Examples() map[string]CapturedExamples {
+	c.m.Lock()
+	defer c.m.Unlock()
+	examples := make(map[string]CapturedExamples, len(c.examples))
+	for operation, e := range c.examples {
+		copied := CapturedExamples{Request: e.Request, Responses: make(map[int]any, len(e.Responses))}
+		for status, body := range e.Responses {
+			copied.Responses[status] = body
+		}
+		examples[operation] = copied
+	}
+	return examples
+}
+
+// ApplyExamples sets the captured examples on the JSON request bodies and responses of the spec.
+func (c *ExampleCapture) ApplyExamples(spec *openapi3.T) {
+	for operation, examples := range c.Examples() {
+		method, path, _ := strings.Cut(operation, " ")
+		pathItem := spec.Paths.Value(path)
+		if pathItem == nil {
+			continue
+		}
+		op := pathItem.GetOperation(method)
+		if op == nil {
+			continue
+		}
+		if op.RequestBody != nil && op.RequestBody.Value != nil && examples.Request != nil {
+			if mt := op.RequestBody.Value.Content.Get("application/json"); mt != nil {
+				mt.Example = examples.Request
+			}
+		}
+		for status, body := range examples.Responses {
+			resp := op.Responses.Status(status)
+			if resp == nil || resp.Value == nil {
+				continue
+			}
+			if mt := resp.Value.Content.Get("application/json"); mt != nil {
+				mt.Example = body
+			}
+		}
+	}
+}
+
+// WriteFixtures writes the captured examples of each operation to a JSON file in dir,
+// e.g. GET_users_{id}.json, for use as test fixtures.
+func (c *ExampleCapture) WriteFixtures(dir string) error {
+	for operation, examples := range c.Examples() {
+		data, err := json.MarshalIndent(examples, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal examples of %q: %w", operation, err)
+		}
+		name := strings.NewReplacer(" /", "_", "/", "_").Replace(operation) + ".json"
+		if err = os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return fmt.Errorf("failed to write examples of %q: %w", operation, err)
+		}
+	}
+	return nil
+}
+
+// readCloser reads the captured start of a request body followed by the rest of it.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// captureResponseWriter records the status and body of a response, unless a response
+// with the status was already captured.
+type captureResponseWriter struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	max         int
+	captured    func(status int) bool
+	wroteHeader bool
+	skip        bool
+}
+
+func (w *captureResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+		w.skip = w.captured(status)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *captureResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	// Stop buffering once the body is too large to be captured.
+	if !w.skip && w.body.Len() <= w.max {
+		w.body.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends the buffered response to the client, if the underlying writer supports it,
+// so that streamed responses aren't held back.
+func (w *captureResponseWriter) Flush() {
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap returns the underlying writer, for use by http.ResponseController.
+func (w *captureResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExampleCapture(t *testing.T) {
+	api := NewAPI("test")
+	api.Post("/users").
+		HasRequestModel(ModelOf[User]()).
+		HasResponseModel(http.StatusOK, ModelOf[User]())
+
+	capture := NewExampleCapture(api)
+	capture.Redact = func(operation string, body any) any {
+		if m, ok := body.(map[string]any); ok {
+			m["name"] = "REDACTED"
+		}
+		return body
+	}
+	h := capture.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The handler can still read the request body.
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	for _, body := range []string{`{"id":1,"name":"Alice"}`, `{"id":2,"name":"Bob"}`} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body)))
+		if w.Body.String() != body {
+			t.Errorf("expected the response body to be %q, got %q", body, w.Body.String())
+		}
+	}
+
+	expected := map[string]CapturedExamples{
+		"POST /users": {
+			Request:   map[string]any{"id": float64(1), "name": "REDACTED"},
+			Responses: map[int]any{http.StatusOK: map[string]any{"id": float64(1), "name": "REDACTED"}},
+		},
+	}
+	if diff := cmp.Diff(expected, capture.Examples()); diff != "" {
+		t.Error(diff)
+	}
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	capture.ApplyExamples(spec)
+	op := spec.Paths.Value("/users").Post
+	if diff := cmp.Diff(expected["POST /users"].Request, op.RequestBody.Value.Content.Get("application/json").Example); diff != "" {
+		t.Errorf("unexpected request example: %s", diff)
+	}
+	if diff := cmp.Diff(expected["POST /users"].Responses[http.StatusOK], op.Responses.Status(http.StatusOK).Value.Content.Get("application/json").Example); diff != "" {
+		t.Errorf("unexpected response example: %s", diff)
+	}
+
+	dir := t.TempDir()
+	if err = capture.WriteFixtures(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "POST_users.json"))
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	var fixture map[string]any
+	if err = json.Unmarshal(data, &fixture); err != nil {
+		t.Fatalf("failed to unmarshal fixture: %v", err)
+	}
+	if _, ok := fixture["request"]; !ok {
+		t.Errorf("expected the fixture to contain the request, got %s", data)
+	}
+}
+
+func TestExampleCaptureLargeBody(t *testing.T) {
+	api := NewAPI("test")
+	api.Post("/users").
+		HasRequestModel(ModelOf[User]()).
+		HasResponseModel(http.StatusOK, ModelOf[User]())
+
+	capture := NewExampleCapture(api)
+	capture.MaxBodyBytes = 16
+	h := capture.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("expected the response writer to implement http.Flusher")
+		}
+		w.Write(body)
+	}))
+	body := `{"id":1,"name":"Alice"}`
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body)))
+	if w.Body.String() != body {
+		t.Errorf("expected the response body to be %q, got %q", body, w.Body.String())
+	}
+	if len(capture.Examples()) != 0 {
+		t.Errorf("expected no examples, got %v", capture.Examples())
+	}
+}
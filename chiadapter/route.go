@@ -79,3 +79,13 @@ func getPlaceholder(s string) (name string, pattern string, ok bool) {
 	}
 	return name, pattern, true
 }
+
+// Routes returns the routes of the router, e.g. "GET /users/{id}", for use with rest.Audit.
+func Routes(src chi.Routes) (routes []string, err error) {
+	walker := func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		routes = append(routes, method+" "+route)
+		return nil
+	}
+	err = chi.Walk(src, walker)
+	return routes, err
+}
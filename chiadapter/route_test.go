@@ -30,7 +30,7 @@ func TestMerge(t *testing.T) {
 	// Assert.
 	expected := rest.Params{
 		Path: map[string]rest.PathParam{
-			"orgId":  {Regexp: `\d+`},
+			"orgId":  {Regexp: `\d+`, Type: rest.PrimitiveTypeInteger},
 			"userId": {},
 			"role":   {Description: "Role of the user"},
 		},
@@ -40,3 +40,29 @@ func TestMerge(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestRoutes(t *testing.T) {
+	router := chi.NewRouter()
+	router.Get("/users", http.NotFound)
+	router.Route("/users/{id:\\d+}", func(r chi.Router) {
+		r.Get("/", http.NotFound)
+		r.Delete("/", http.NotFound)
+	})
+	api := rest.NewAPI("test")
+	api.Get("/users")
+	api.Get("/users/{id}")
+	api.Post("/users")
+
+	routes, err := chiadapter.Routes(router)
+	if err != nil {
+		t.Fatalf("failed to get routes: %v", err)
+	}
+
+	expected := rest.AuditReport{
+		Undocumented: []string{`DELETE /users/{id:\d+}/`},
+		Unhandled:    []string{"POST /users"},
+	}
+	if diff := cmp.Diff(expected, rest.Audit(api, routes)); diff != "" {
+		t.Error(diff)
+	}
+}
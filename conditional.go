@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Names of the response components added for routes that support conditional requests.
+const (
+	notModifiedResponse        = "NotModified"
+	preconditionFailedResponse = "PreconditionFailed"
+)
+
+// SupportsConditionalRequests documents that the route supports conditional requests.
+// Successful responses have an ETag header. GET and HEAD routes accept an If-None-Match
+// header and can return 304 Not Modified, and other routes accept an If-Match header
+// and can return 412 Precondition Failed, so that clients can avoid lost updates.
+func (rm *Route) SupportsConditionalRequests() *Route {
+	rm.ConditionalRequests = true
+	return rm
+}
+
+// addConditionalRequests documents the conditional request headers and responses on the operation.
+func addConditionalRequests(spec *openapi3.T, method Method, op *openapi3.Operation) {
+	if op.Responses == nil {
+		op.Responses = openapi3.NewResponses()
+	}
+	for key, response := range op.Responses.Map() {
+		if !strings.HasPrefix(key, "2") || response.Value == nil {
+			continue
+		}
+		if response.Value.Headers == nil {
+			response.Value.Headers = make(openapi3.Headers)
+		}
+		response.Value.Headers["ETag"] = newHeader("The version of the resource, used in the If-Match and If-None-Match headers of later requests.", openapi3.NewStringSchema())
+	}
+
+	header, description := "If-Match", "Only process the request if the ETag of the resource matches."
+	status, name := http.StatusPreconditionFailed, preconditionFailedResponse
+	responseDescription := "The resource has changed since the ETag in the If-Match header was returned."
+	if method == http.MethodGet || method == http.MethodHead {
+		header, description = "If-None-Match", "Only return the resource if its ETag doesn't match, i.e. it has changed."
+		status, name = http.StatusNotModified, notModifiedResponse
+		responseDescription = "The resource hasn't changed since the ETag in the If-None-Match header was returned."
+	}
+	if spec.Components.Responses == nil {
+		spec.Components.Responses = make(openapi3.ResponseBodies)
+	}
+	spec.Components.Responses[name] = &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().WithDescription(responseDescription),
+	}
+	op.AddParameter(openapi3.NewHeaderParameter(header).
+		WithDescription(description).
+		WithSchema(openapi3.NewStringSchema()))
+	if op.Responses.Status(status) == nil {
+		op.Responses.Set(strconv.Itoa(status), &openapi3.ResponseRef{
+			Ref: "#/components/responses/" + name,
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// WithConst sets the schema to only allow the value, e.g. for the literal of a
+// discriminator, or a fixed version field. OpenAPI 3.0 has no const keyword, so the
+// value is an enum with a single value.
+//
+// Struct fields can use the const tag instead, e.g.
+//
+//	Kind string `json:"kind" const:"dog"`
+func WithConst(value any) ModelOpts {
+	return func(s *openapi3.Schema) {
+		s.Enum = []any{value}
+	}
+}
+
+// applyConstTag limits the field to the value of its const tag, if it has one. The value
+// is parsed according to the kind of the field. Referenced schemas can't be changed, so
+// they're wrapped in allOf.
+func applyConstTag(f reflect.StructField, ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+	tag, ok := f.Tag.Lookup("const")
+	if !ok {
+		return ref, nil
+	}
+	value, err := parseConstValue(f.Type, tag)
+	if err != nil {
+		return nil, err
+	}
+	ref = wrapRef(ref)
+	ref.Value.Enum = []any{value}
+	return ref, nil
+}
+
+func parseConstValue(t reflect.Type, value string) (v any, err error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Bool:
+		v, err = strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err = strconv.ParseInt(value, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err = strconv.ParseUint(value, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		v, err = strconv.ParseFloat(value, t.Bits())
+	default:
+		return nil, fmt.Errorf("const tag is not supported on %v fields", t)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid const tag %q for %v field", value, t)
+	}
+	return v, nil
+}
@@ -0,0 +1,108 @@
+package rest
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// CORSPolicy is the cross-origin resource sharing policy of the API, see WithCORS.
+type CORSPolicy struct {
+	// AllowedOrigins that can make requests, e.g. https://example.com, or * for any origin.
+	AllowedOrigins []string
+	// AllowedHeaders that can be sent in requests, e.g. Authorization.
+	AllowedHeaders []string
+	// ExposedHeaders that browsers make available to scripts, e.g. ETag.
+	ExposedHeaders []string
+	// AllowCredentials allows requests to include cookies and authorization headers.
+	AllowCredentials bool
+	// MaxAge is how long browsers can cache the result of a preflight request.
+	MaxAge time.Duration
+	// Preflight adds an OPTIONS operation to each path that doesn't have one,
+	// documenting the response to preflight requests.
+	Preflight bool
+}
+
+// WithCORS documents the CORS policy of the API. The Access-Control-* headers are
+// added to the responses of each operation, and if the policy has Preflight set,
+// OPTIONS operations are added for each path.
+func WithCORS(policy CORSPolicy) APIOpts {
+	return func(api *API) {
+		api.CORS = &policy
+	}
+}
+
+// applyCORS documents the CORS policy in the spec.
+func (api *API) applyCORS(spec *openapi3.T) {
+	p := api.CORS
+	origin := strings.Join(p.AllowedOrigins, ", ")
+	for _, path := range spec.Paths.InMatchingOrder() {
+		pathItem := spec.Paths.Value(path)
+		for _, op := range pathItem.Operations() {
+			if op.Responses == nil {
+				continue
+			}
+			for key, response := range op.Responses.Map() {
+				if key == "default" || response.Value == nil {
+					continue
+				}
+				if response.Value.Headers == nil {
+					response.Value.Headers = make(openapi3.Headers)
+				}
+				p.addResponseHeaders(response.Value.Headers, origin)
+			}
+		}
+		if p.Preflight && pathItem.Options == nil {
+			pathItem.Options = p.newPreflightOperation(pathItem, origin)
+		}
+	}
+}
+
+func (p *CORSPolicy) addResponseHeaders(headers openapi3.Headers, origin string) {
+	headers["Access-Control-Allow-Origin"] = newHeader("The origin that can read the response, one of: "+origin+".", openapi3.NewStringSchema())
+	if p.AllowCredentials {
+		headers["Access-Control-Allow-Credentials"] = newHeader("Set to true, since requests can include credentials.", openapi3.NewStringSchema().WithEnum("true"))
+	}
+	if len(p.ExposedHeaders) > 0 {
+		headers["Access-Control-Expose-Headers"] = newHeader("The headers that scripts can read: "+strings.Join(p.ExposedHeaders, ", ")+".", openapi3.NewStringSchema())
+	}
+}
+
+// newPreflightOperation documents the response to CORS preflight requests to the path.
+func (p *CORSPolicy) newPreflightOperation(pathItem *openapi3.PathItem, origin string) *openapi3.Operation {
+	var methods []string
+	for method := range pathItem.Operations() {
+		methods = append(methods, method)
+	}
+	methods = append(methods, http.MethodOptions)
+	slices.Sort(methods)
+
+	headers := make(openapi3.Headers)
+	p.addResponseHeaders(headers, origin)
+	headers["Access-Control-Allow-Methods"] = newHeader("The methods allowed for the path: "+strings.Join(methods, ", ")+".", openapi3.NewStringSchema())
+	if len(p.AllowedHeaders) > 0 {
+		headers["Access-Control-Allow-Headers"] = newHeader("The headers allowed in requests: "+strings.Join(p.AllowedHeaders, ", ")+".", openapi3.NewStringSchema())
+	}
+	if p.MaxAge > 0 {
+		headers["Access-Control-Max-Age"] = newHeader("The number of seconds that the preflight response can be cached for: "+strconv.Itoa(int(p.MaxAge.Seconds()))+".", openapi3.NewIntegerSchema())
+	}
+
+	op := openapi3.NewOperation()
+	op.Description = "CORS preflight request."
+	response := openapi3.NewResponse().WithDescription("The CORS policy of the path.")
+	response.Headers = headers
+	op.AddResponse(http.StatusNoContent, response)
+	// Preflight requests are made with the path parameters of the path's operations.
+	for _, op2 := range pathItem.Operations() {
+		for _, param := range op2.Parameters {
+			if param.Value != nil && param.Value.In == openapi3.ParameterInPath && op.Parameters.GetByInAndName(openapi3.ParameterInPath, param.Value.Name) == nil {
+				op.AddParameter(param.Value)
+			}
+		}
+	}
+	return op
+}
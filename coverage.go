@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"maps"
+	"net/http"
+	"sync"
+)
+
+const (
+	// maxUndocumentedRequests is the number of distinct undocumented requests that are
+	// counted separately, so that scans of random paths can't grow the report without bound.
+	maxUndocumentedRequests = 100
+	// otherUndocumentedRequests counts the undocumented requests over the limit.
+	otherUndocumentedRequests = "other"
+)
+
+// coverage records the operations that have been called.
+type coverage struct {
+	m            sync.Mutex
+	called       map[string]int
+	undocumented map[string]int
+}
+
+// CoverageMiddleware records which of the API's operations are called, e.g. by tests or
+// by traffic, to find operations that are never called, and requests that aren't documented.
+// See CoverageReport.
+func (api *API) CoverageMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.recordCall(r)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (api *API) recordCall(r *http.Request) {
+	api.coverage.m.Lock()
+	defer api.coverage.m.Unlock()
+	route, ok := api.matchRoute(r.Method, r.URL.Path)
+	if !ok {
+		request := r.Method + " " + r.URL.Path
+		if _, ok := api.coverage.undocumented[request]; !ok && len(api.coverage.undocumented) >= maxUndocumentedRequests {
+			request = otherUndocumentedRequests
+		}
+		api.coverage.undocumented[request]++
+		return
+	}
+	api.coverage.called[getOperation(route)]++
+}
+
+// getOperation returns the method and path of the route, e.g. "GET /users/{id}".
+func getOperation(route *Route) string {
+	return string(route.Method) + " " + getPath(route.Pattern)
+}
+
+// CoverageReport lists the operations called, and not called, since CoverageMiddleware
+// was added.
+type CoverageReport struct {
+	// Called maps from operations, e.g. "GET /users/{id}", to the number of times they were called.
+	Called map[string]int
+	// Uncalled operations, sorted by path and method.
+	Uncalled []string
+	// Undocumented maps from requests that don't match an operation, e.g. "DELETE /users/123",
+	// to the number of times they were made. Once 100 distinct requests are recorded, the
+	// rest are counted as "other".
+	Undocumented map[string]int
+}
+
+// CoverageReport returns the operations that have been called, and not called, since
+// CoverageMiddleware was added.
+func (api *API) CoverageReport() (report CoverageReport) {
+	api.coverage.m.Lock()
+	defer api.coverage.m.Unlock()
+	report.Called = maps.Clone(api.coverage.called)
+	report.Undocumented = maps.Clone(api.coverage.undocumented)
+	for _, ri := range api.RouteInfo() {
+		operation := getOperation(ri.Route)
+		if _, ok := report.Called[operation]; !ok {
+			report.Uncalled = append(report.Uncalled, operation)
+		}
+	}
+	return report
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMatchRoute(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/users")
+	api.Get("/users/{id}")
+	api.Get("/users/me")
+	api.Delete("/users/{id}")
+	api.Get("/files/{name}.{ext}")
+	api.Get("/orders/{id:\\d+}")
+	api.Get("/orders/{slug}")
+	api.Get("/flags/{enabled}").HasPathParameter("enabled", PathParam{Type: PrimitiveTypeBool})
+
+	tests := []struct {
+		method, path string
+		expected     string
+	}{
+		{method: http.MethodGet, path: "/users", expected: "GET /users"},
+		{method: http.MethodGet, path: "/users/123", expected: "GET /users/{id}"},
+		{method: http.MethodGet, path: "/users/me", expected: "GET /users/me"},
+		{method: http.MethodDelete, path: "/users/123", expected: "DELETE /users/{id}"},
+		{method: http.MethodGet, path: "/files/report.pdf", expected: "GET /files/{name}.{ext}"},
+		{method: http.MethodPost, path: "/users", expected: ""},
+		{method: http.MethodGet, path: "/users/123/posts", expected: ""},
+		{method: http.MethodGet, path: "/orders/123", expected: "GET /orders/{id:\\d+}"},
+		{method: http.MethodGet, path: "/orders/latest", expected: "GET /orders/{slug}"},
+		{method: http.MethodGet, path: "/flags/true", expected: "GET /flags/{enabled}"},
+		{method: http.MethodGet, path: "/flags/yes", expected: ""},
+	}
+	for _, test := range tests {
+		var actual string
+		if route, ok := api.matchRoute(test.method, test.path); ok {
+			actual = getOperation(route)
+		}
+		if actual != test.expected {
+			t.Errorf("%s %s: expected %q, got %q", test.method, test.path, test.expected, actual)
+		}
+	}
+	// Routes added after requests are matched are matched too.
+	api.Get("/teams/{id}")
+	if route, ok := api.matchRoute(http.MethodGet, "/teams/1"); !ok || getOperation(route) != "GET /teams/{id}" {
+		t.Errorf("expected the new route to be matched, got %v", route)
+	}
+}
+
+func TestCoverageReport(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/users")
+	api.Get("/users/{id}")
+	api.Delete("/users/{id}")
+
+	h := api.CoverageMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, target := range []string{"/users/1", "/users/2", "/users", "/unknown"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	expected := CoverageReport{
+		Called:       map[string]int{"GET /users": 1, "GET /users/{id}": 2},
+		Uncalled:     []string{"DELETE /users/{id}"},
+		Undocumented: map[string]int{"GET /unknown": 1},
+	}
+	if diff := cmp.Diff(expected, api.CoverageReport()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestCoverageReportUndocumentedLimit(t *testing.T) {
+	api := NewAPI("test")
+	h := api.CoverageMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < maxUndocumentedRequests+10; i++ {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/"+strconv.Itoa(i), nil))
+	}
+	undocumented := api.CoverageReport().Undocumented
+	if len(undocumented) != maxUndocumentedRequests+1 {
+		t.Errorf("expected %d undocumented requests, got %d", maxUndocumentedRequests+1, len(undocumented))
+	}
+	if undocumented[otherUndocumentedRequests] != 10 {
+		t.Errorf("expected 10 other requests, got %d", undocumented[otherUndocumentedRequests])
+	}
+}
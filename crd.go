@@ -0,0 +1,206 @@
+package rest
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// CRDHook customises the schema at a path of a CRD schema, e.g. ".spec.ports[]",
+// typically to add x-kubernetes extensions such as x-kubernetes-list-type. The
+// Extensions of the schema are never nil.
+type CRDHook func(path string, s *openapi3.Schema)
+
+// CRDSchema returns the schema of the model as a structural schema, suitable for the
+// openAPIV3Schema of a Kubernetes CustomResourceDefinition, so that a Go type can be
+// used in both the REST API and a CRD.
+//
+// References are inlined, since CRDs can't contain them, and recursive types return
+// an error. Schemas that allow any value are marked with x-kubernetes-preserve-unknown-fields,
+// keywords that CRDs don't support, such as readOnly, are removed, and the metadata
+// property is reduced to an object, since Kubernetes provides its schema.
+func (api *API) CRDSchema(model Model, hooks ...CRDHook) (s *openapi3.Schema, err error) {
+	name, schema, err := api.RegisterModel(model)
+	if err != nil {
+		return nil, err
+	}
+	c := crdConverter{api: api, hooks: hooks, stack: map[string]bool{name: true}}
+	s, err = c.convert("", openapi3.NewSchemaRef("", schema))
+	if err != nil {
+		return nil, err
+	}
+	if metadata, ok := s.Properties["metadata"]; ok && metadata.Value != nil {
+		s.Properties["metadata"] = openapi3.NewSchemaRef("", openapi3.NewObjectSchema())
+	}
+	return s, nil
+}
+
+type crdConverter struct {
+	api   *API
+	hooks []CRDHook
+	// stack of the schemas being inlined, used to detect recursion.
+	stack map[string]bool
+}
+
+func (c crdConverter) convert(path string, ref *openapi3.SchemaRef) (s *openapi3.Schema, err error) {
+	source, name, err := c.resolve(path, ref)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		c.stack[name] = true
+		defer delete(c.stack, name)
+	}
+	// Structural schemas must have a type, so schemas that wrap a single schema in allOf,
+	// e.g. to add a unit to a field, are merged with the schema they wrap.
+	for isAllOfWrapper(source) {
+		wrapped, name, err := c.resolve(path, source.AllOf[0])
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			c.stack[name] = true
+			defer delete(c.stack, name)
+		}
+		source = mergeAllOfWrapper(source, wrapped)
+	}
+
+	copied := *source
+	s = &copied
+	s.Extensions = maps.Clone(source.Extensions)
+	s.Required = slices.Clone(source.Required)
+	s.Enum = slices.Clone(source.Enum)
+	// Remove keywords that CRD schemas don't support.
+	s.ReadOnly, s.WriteOnly, s.Deprecated, s.AllowEmptyValue = false, false, false, false
+	s.Discriminator, s.XML = nil, nil
+	if s.AdditionalProperties.Has != nil && !*s.AdditionalProperties.Has {
+		s.AdditionalProperties.Has = nil
+	}
+
+	if source.Properties != nil {
+		s.Properties = make(openapi3.Schemas, len(source.Properties))
+		for _, name := range getSortedKeys(source.Properties) {
+			if s.Properties[name], err = c.convertRef(path+"."+name, source.Properties[name]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if s.Items, err = c.convertRef(path+"[]", source.Items); err != nil {
+		return nil, err
+	}
+	if s.AdditionalProperties.Schema, err = c.convertRef(path+"{}", source.AdditionalProperties.Schema); err != nil {
+		return nil, err
+	}
+	if s.Not, err = c.convertRef(path, source.Not); err != nil {
+		return nil, err
+	}
+	for _, refs := range []*openapi3.SchemaRefs{&s.AllOf, &s.AnyOf, &s.OneOf} {
+		converted := make(openapi3.SchemaRefs, len(*refs))
+		for i, r := range *refs {
+			if converted[i], err = c.convertRef(path, r); err != nil {
+				return nil, err
+			}
+		}
+		if len(converted) == 0 {
+			converted = nil
+		}
+		*refs = converted
+	}
+
+	if s.Extensions == nil {
+		s.Extensions = make(map[string]any)
+	}
+	if isUntyped(s) {
+		s.Extensions["x-kubernetes-preserve-unknown-fields"] = true
+	}
+	for _, hook := range c.hooks {
+		hook(path, s)
+	}
+	return s, nil
+}
+
+// resolve returns the schema of the reference, and the name of the component it refers
+// to, if any.
+func (c crdConverter) resolve(path string, ref *openapi3.SchemaRef) (s *openapi3.Schema, name string, err error) {
+	if ref.Ref == "" {
+		return ref.Value, "", nil
+	}
+	name = strings.TrimPrefix(ref.Ref, "#/components/schemas/")
+	if c.stack[name] {
+		return nil, "", fmt.Errorf("%s: recursive type %s can't be used in a CRD schema", path, name)
+	}
+	s = ref.Value
+	if s == nil {
+		s = c.api.models[name]
+	}
+	if s == nil {
+		return nil, "", fmt.Errorf("%s: reference %q can't be inlined", path, ref.Ref)
+	}
+	return s, name, nil
+}
+
+// isAllOfWrapper returns true if the schema only adds keywords, such as a description or
+// x-unit, to a single schema in allOf.
+func isAllOfWrapper(s *openapi3.Schema) bool {
+	return len(s.AllOf) == 1 && s.Properties == nil && s.Items == nil
+}
+
+// mergeAllOfWrapper returns a copy of the wrapped schema, with the keywords that are set
+// on the wrapper.
+func mergeAllOfWrapper(wrapper, wrapped *openapi3.Schema) *openapi3.Schema {
+	merged := *wrapped
+	if wrapper.Type != nil {
+		merged.Type = wrapper.Type
+	}
+	if wrapper.Title != "" {
+		merged.Title = wrapper.Title
+	}
+	if wrapper.Description != "" {
+		merged.Description = wrapper.Description
+	}
+	if wrapper.Enum != nil {
+		merged.Enum = wrapper.Enum
+	}
+	if wrapper.Default != nil {
+		merged.Default = wrapper.Default
+	}
+	if wrapper.Example != nil {
+		merged.Example = wrapper.Example
+	}
+	merged.Nullable = merged.Nullable || wrapper.Nullable
+	if len(wrapper.Extensions) > 0 {
+		merged.Extensions = maps.Clone(wrapped.Extensions)
+		if merged.Extensions == nil {
+			merged.Extensions = make(map[string]any)
+		}
+		maps.Copy(merged.Extensions, wrapper.Extensions)
+	}
+	return &merged
+}
+
+func (c crdConverter) convertRef(path string, ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	s, err := c.convert(path, ref)
+	if err != nil {
+		return nil, err
+	}
+	return openapi3.NewSchemaRef("", s), nil
+}
+
+// isUntyped returns true if the schema allows any value, which structural schemas
+// must mark with x-kubernetes-preserve-unknown-fields.
+func isUntyped(s *openapi3.Schema) bool {
+	if s.Type != nil && len(s.Type.Slice()) > 0 {
+		return false
+	}
+	if len(s.AllOf) > 0 || len(s.AnyOf) > 0 || len(s.OneOf) > 0 {
+		return false
+	}
+	_, intOrString := s.Extensions["x-kubernetes-int-or-string"]
+	return !intOrString
+}
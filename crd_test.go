@@ -0,0 +1,101 @@
+package rest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type CRDSpec struct {
+	Replicas int               `json:"replicas"`
+	Ports    []CRDPort         `json:"ports"`
+	Labels   map[string]string `json:"labels"`
+}
+
+type CRDPort struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+type CRDObjectMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type CRD struct {
+	Metadata CRDObjectMeta `json:"metadata"`
+	Spec     CRDSpec       `json:"spec"`
+}
+
+type CRDRecursive struct {
+	Children []CRDRecursive `json:"children"`
+}
+
+func TestCRDSchema(t *testing.T) {
+	api := NewAPI("test")
+	s, err := api.CRDSchema(ModelOf[CRD](), func(path string, s *openapi3.Schema) {
+		if path == ".spec.ports" {
+			s.Extensions["x-kubernetes-list-type"] = "map"
+			s.Extensions["x-kubernetes-list-map-keys"] = []string{"name"}
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to create CRD schema: %v", err)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %v", err)
+	}
+	actual := string(data)
+	expected := `{"properties":{"metadata":{"type":"object"},"spec":{"properties":{"labels":{"additionalProperties":{"type":"string"},"nullable":true,"type":"object"},"ports":{"items":{"properties":{"name":{"type":"string"},"port":{"type":"integer"}},"required":["name","port"],"type":"object"},"nullable":true,"type":"array","x-kubernetes-list-map-keys":["name"],"x-kubernetes-list-type":"map"},"replicas":{"type":"integer"}},"required":["replicas","ports","labels"],"type":"object"}},"required":["metadata","spec"],"type":"object"}`
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	// The model registered with the API is unchanged.
+	models, err := api.Models()
+	if err != nil {
+		t.Fatalf("failed to get models: %v", err)
+	}
+	if _, ok := models["github_com_heimspiel_rest_CRDSpec"].Properties["ports"].Value.Extensions["x-kubernetes-list-type"]; ok {
+		t.Error("expected the registered model to be unchanged")
+	}
+}
+
+func TestCRDSchemaRecursive(t *testing.T) {
+	api := NewAPI("test")
+	if _, err := api.CRDSchema(ModelOf[CRDRecursive]()); err == nil {
+		t.Error("expected an error for a recursive type")
+	}
+}
+
+type CRDKind string
+
+type CRDLimit struct {
+	Value int `json:"value"`
+}
+
+type CRDWithTags struct {
+	Kind  CRDKind  `json:"kind" const:"Widget"`
+	Limit CRDLimit `json:"limit" unit:"ms"`
+}
+
+func TestCRDSchemaMergesAllOfWrappers(t *testing.T) {
+	api := NewAPI("test")
+	s, err := api.CRDSchema(ModelOf[CRDWithTags]())
+	if err != nil {
+		t.Fatalf("failed to create CRD schema: %v", err)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("failed to marshal schema: %v", err)
+	}
+	actual := string(data)
+	expected := `{"properties":{"kind":{"enum":["Widget"],"type":"string"},"limit":{"description":"Unit: ms.","properties":{"value":{"type":"integer"}},"required":["value"],"type":"object","x-unit":"ms"}},"required":["kind","limit"],"type":"object"}`
+	if actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+}
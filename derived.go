@@ -0,0 +1,194 @@
+package rest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// PartialOf creates a model of T where every property is optional, and nullable, e.g.
+// for PATCH request bodies, or filters. The schema is inlined where it's used.
+func PartialOf[T any]() Model {
+	m := ModelOf[T]()
+	m.opts = []SchemaOpts{ModelOpts(withAllOptional)}
+	return m
+}
+
+// withAllOptional makes the properties of the schema optional and nullable. Properties
+// that reference other schemas are left as they are, since the referenced schema is
+// shared.
+func withAllOptional(s *openapi3.Schema) {
+	s.Required = nil
+	for _, prop := range s.Properties {
+		if prop.Ref == "" && prop.Value != nil {
+			prop.Value.Nullable = true
+		}
+	}
+}
+
+// PickOf creates a model of T that only has the named properties, e.g.
+// PickOf[User]("id", "name"). The schema is added to the components with a name derived
+// from T and the sorted properties, e.g. User_pick_id_name.
+func PickOf[T any](properties ...string) Model {
+	return derivedModelOf[T]("pick", properties, func(s *openapi3.Schema) error {
+		if err := checkProperties(s, properties); err != nil {
+			return err
+		}
+		for name := range s.Properties {
+			if !slices.Contains(properties, name) {
+				removeProperty(s, name)
+			}
+		}
+		return nil
+	})
+}
+
+// OmitOf creates a model of T without the named properties, e.g. OmitOf[User]("password").
+// The schema is added to the components with a name derived from T and the sorted
+// properties, e.g. User_omit_password.
+func OmitOf[T any](properties ...string) Model {
+	return derivedModelOf[T]("omit", properties, func(s *openapi3.Schema) error {
+		if err := checkProperties(s, properties); err != nil {
+			return err
+		}
+		for _, name := range properties {
+			removeProperty(s, name)
+		}
+		return nil
+	})
+}
+
+// derivedModelOf creates a model whose schema is derived from the schema of T. If T is a
+// component, the derived schema is a component named after it, the operation and the
+// sorted properties, e.g. User_pick_id_name. Property names can contain underscores,
+// so different models that get the same name are an error.
+func derivedModelOf[T any](operation string, properties []string, derive func(s *openapi3.Schema) error) Model {
+	properties = slices.Clone(properties)
+	slices.Sort(properties)
+	m := ModelOf[T]()
+	m.wrap = func(api *API, ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+		name := getComponentName(ref.Ref)
+		schema := ref.Value
+		if schema == nil {
+			schema, _ = api.getComponentSchema(name)
+		}
+		if schema == nil {
+			return nil, fmt.Errorf("can't derive a schema from %q", ref.Ref)
+		}
+		derived, err := cloneSchema(schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy schema %q: %w", name, err)
+		}
+		if err = derive(derived); err != nil {
+			return nil, fmt.Errorf("failed to derive schema from %v: %w", m.Type, err)
+		}
+		if ref.Ref == "" {
+			return openapi3.NewSchemaRef("", derived), nil
+		}
+		key := fmt.Sprintf("%s of %v with %q", operation, m.Type, properties)
+		return api.addDerivedComponent(name+"_"+operation+"_"+strings.Join(properties, "_"), key, derived)
+	}
+	return m
+}
+
+// checkProperties checks that the properties exist in the schema.
+func checkProperties(s *openapi3.Schema, properties []string) error {
+	for _, name := range properties {
+		if _, ok := s.Properties[name]; !ok {
+			return fmt.Errorf("%q is not a property", name)
+		}
+	}
+	return nil
+}
+
+func removeProperty(s *openapi3.Schema, name string) {
+	delete(s.Properties, name)
+	s.Required = slices.DeleteFunc(s.Required, func(r string) bool { return r == name })
+}
+
+// ExtendsOf creates a model that extends Base with the properties of Extra, using allOf,
+// so that client generators can see that it inherits from Base, e.g.
+// allOf: [{$ref: User}, {properties of Admin}]. The schema is added to the components
+// with a name derived from both, e.g. Admin_extends_User.
+func ExtendsOf[Base, Extra any]() Model {
+	m := ModelOf[Extra]()
+	m.wrap = func(api *API, ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+		base := ModelOf[Base]()
+		baseName, baseSchema, err := api.RegisterModel(base)
+		if err != nil {
+			return nil, err
+		}
+		baseRef := api.getSchemaReferenceOrValue(baseName, baseSchema)
+		name := getComponentName(ref.Ref)
+		extra := ref.Value
+		if extra == nil {
+			extra, _ = api.getComponentSchema(name)
+		}
+		if extra == nil || !extra.Type.Is(openapi3.TypeObject) {
+			return nil, fmt.Errorf("%v must be an object to extend %v", m.Type, base.Type)
+		}
+		if extra, err = cloneSchema(extra); err != nil {
+			return nil, fmt.Errorf("failed to copy schema %q: %w", name, err)
+		}
+		// The description belongs to the extended schema, not the properties added to it.
+		description := extra.Description
+		extra.Description = ""
+		extended := &openapi3.Schema{
+			Description: description,
+			AllOf:       openapi3.SchemaRefs{baseRef, openapi3.NewSchemaRef("", extra)},
+		}
+		if ref.Ref == "" {
+			return openapi3.NewSchemaRef("", extended), nil
+		}
+		key := fmt.Sprintf("%v extends %v", m.Type, base.Type)
+		return api.addDerivedComponent(name+"_extends_"+baseName, key, extended)
+	}
+	return m
+}
+
+// derivedComponent is a component schema that's derived from models while the spec is
+// generated, e.g. by PickOf, and isn't a model of the API.
+type derivedComponent struct {
+	// key describes how the schema was derived, e.g. "pick of User with [id name]".
+	key    string
+	schema *openapi3.Schema
+}
+
+// addDerivedComponent adds a derived schema to the components of the spec being generated,
+// and returns a reference to it. It's an error for a model, or a schema derived in
+// another way, to have the same name.
+func (api *API) addDerivedComponent(name, key string, schema *openapi3.Schema) (*openapi3.SchemaRef, error) {
+	if _, ok := api.models[name]; ok {
+		return nil, fmt.Errorf("the %s has the same schema name as model %q", key, name)
+	}
+	if existing, ok := api.derived[name]; ok && existing.key != key {
+		return nil, fmt.Errorf("the %s has the same schema name as the %s: %q", key, existing.key, name)
+	}
+	if _, ok := api.derived[name]; !ok {
+		api.derived[name] = derivedComponent{key: key, schema: schema}
+	}
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, nil), nil
+}
+
+// getComponentSchema returns the schema of a model or derived component.
+func (api *API) getComponentSchema(name string) (schema *openapi3.Schema, ok bool) {
+	if schema, ok = api.models[name]; ok {
+		return schema, true
+	}
+	dc, ok := api.derived[name]
+	return dc.schema, ok
+}
+
+// addDerivedComponents adds the derived schemas to the components of the spec, checking
+// that models registered after them don't have the same names.
+func (api *API) addDerivedComponents(spec *openapi3.T) error {
+	for _, name := range getSortedKeys(api.derived) {
+		if _, ok := spec.Components.Schemas[name]; ok {
+			return fmt.Errorf("the %s has the same schema name as model %q", api.derived[name].key, name)
+		}
+		spec.Components.Schemas[name] = openapi3.NewSchemaRef("", api.derived[name].schema)
+	}
+	return nil
+}
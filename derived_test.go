@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPickOfUnknownProperty(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/users").
+		HasResponseModel(http.StatusOK, PickOf[User]("id", "email")).
+		HasResponseDescription(http.StatusOK, "The users.")
+
+	_, err := api.Spec()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), `"email" is not a property`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type Person struct {
+	First     string `json:"first"`
+	Name      string `json:"name"`
+	FirstName string `json:"first_name"`
+}
+
+func TestPickOfNames(t *testing.T) {
+	t.Run("the order of properties doesn't matter", func(t *testing.T) {
+		api := NewAPI("test")
+		api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+		api.Get("/people").
+			HasResponseModel(http.StatusOK, PickOf[Person]("name", "first")).
+			HasResponseDescription(http.StatusOK, "The people.")
+		api.Get("/people/{id}").
+			HasResponseModel(http.StatusOK, PickOf[Person]("first", "name")).
+			HasResponseDescription(http.StatusOK, "The person.")
+
+		spec, err := api.Spec()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, ok := spec.Components.Schemas["Person_pick_first_name"]; !ok {
+			t.Errorf("expected a Person_pick_first_name schema, got %v", getSortedKeys(spec.Components.Schemas))
+		}
+		if _, ok := api.models["Person_pick_first_name"]; ok {
+			t.Error("expected the derived schema not to be a model")
+		}
+	})
+	t.Run("different models with the same name are an error", func(t *testing.T) {
+		api := NewAPI("test")
+		api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+		api.Get("/people").
+			HasResponseModel(http.StatusOK, PickOf[Person]("first", "name")).
+			HasResponseDescription(http.StatusOK, "The people.")
+		api.Get("/people/{id}").
+			HasResponseModel(http.StatusOK, PickOf[Person]("first_name")).
+			HasResponseDescription(http.StatusOK, "The person.")
+
+		_, err := api.Spec()
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if !strings.Contains(err.Error(), "has the same schema name") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
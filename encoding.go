@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	// contentEncodingExtension is the encoding of binary data in a string, e.g. base64.
+	// OpenAPI 3.0 has no contentEncoding keyword, so it's an extension.
+	contentEncodingExtension = "x-content-encoding"
+	// contentMediaTypeExtension is the media type of the data in a string, e.g. image/png.
+	// OpenAPI 3.0 has no contentMediaType keyword, so it's an extension.
+	contentMediaTypeExtension = "x-content-media-type"
+)
+
+// WithContentEncoding sets the encoding of the binary data in the string, e.g. base64.
+// Since OpenAPI 3.0 has no contentEncoding keyword, it's added as x-content-encoding,
+// and base64 strings also have the byte format.
+//
+// Struct fields can use the contentEncoding tag instead.
+func WithContentEncoding(encoding string) ModelOpts {
+	return func(s *openapi3.Schema) {
+		if encoding == "base64" {
+			s.Format = "byte"
+		}
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions[contentEncodingExtension] = encoding
+	}
+}
+
+// WithContentMediaType sets the media type of the data in the string, e.g. image/png.
+// Since OpenAPI 3.0 has no contentMediaType keyword, it's added as x-content-media-type.
+//
+// Struct fields can use the contentMediaType tag instead.
+func WithContentMediaType(mediaType string) ModelOpts {
+	return func(s *openapi3.Schema) {
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions[contentMediaTypeExtension] = mediaType
+	}
+}
+
+// applyContentTags sets the content encoding and media type of a string field from its
+// contentEncoding and contentMediaType tags, e.g.
+//
+//	Avatar string `json:"avatar" contentEncoding:"base64" contentMediaType:"image/png"`
+func applyContentTags(f reflect.StructField, s *openapi3.Schema) error {
+	encoding, hasEncoding := f.Tag.Lookup("contentEncoding")
+	mediaType, hasMediaType := f.Tag.Lookup("contentMediaType")
+	if !hasEncoding && !hasMediaType {
+		return nil
+	}
+	if !s.Type.Is(openapi3.TypeString) {
+		return fmt.Errorf("content tags are only supported on string fields, not %v", f.Type)
+	}
+	if hasEncoding {
+		WithContentEncoding(encoding)(s)
+	}
+	if hasMediaType {
+		WithContentMediaType(mediaType)(s)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Extensions used by Terraform provider generators, such as Speakeasy, to map operations
+// to the create, read, update and delete operations of a resource.
+const (
+	entityExtension          = "x-speakeasy-entity"
+	entityOperationExtension = "x-speakeasy-entity-operation"
+)
+
+// EntityOperation is the operation that a route performs on an entity.
+type EntityOperation string
+
+const (
+	EntityCreate EntityOperation = "create"
+	EntityRead   EntityOperation = "read"
+	EntityUpdate EntityOperation = "update"
+	EntityDelete EntityOperation = "delete"
+)
+
+// HasEntityOperation sets the entity that the route manages, e.g. "User", and the
+// operation that it performs on the entity, instead of inferring them.
+func (rm *Route) HasEntityOperation(entity string, op EntityOperation) *Route {
+	rm.Entity = entity
+	rm.EntityOperation = op
+	return rm
+}
+
+// SpecWithEntityExtensions creates an OpenAPI specification where operations and schemas
+// have the x-speakeasy-entity-operation and x-speakeasy-entity extensions, so that a
+// Terraform provider can be generated from the API.
+//
+// Unless set with HasEntityOperation, the operation is inferred from the method and path:
+// POST to a collection, e.g. /users, creates an entity, and GET, PUT, PATCH and DELETE of
+// an item, e.g. /users/{id}, read, update and delete it. The entity is the name of the
+// type of the route's successful response, or of the other routes with the same path.
+func (api *API) SpecWithEntityExtensions() (spec *openapi3.T, err error) {
+	spec, err = api.Spec()
+	if err != nil {
+		return nil, err
+	}
+	spec, err = api.cloneSpec(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy spec: %w", err)
+	}
+	for _, pattern := range getSortedKeys(api.Routes) {
+		methodToRoute := api.Routes[pattern]
+		pathItem := spec.Paths.Value(getPath(pattern))
+		pathEntity, pathType := getPathEntity(methodToRoute)
+		for _, method := range getSortedMethods(methodToRoute) {
+			route := methodToRoute[method]
+			entity, t := route.Entity, getEntityType(route)
+			if entity == "" && t != nil {
+				entity = t.Name()
+			}
+			if entity == "" {
+				entity, t = pathEntity, pathType
+			}
+			op := route.EntityOperation
+			if op == "" {
+				op = inferEntityOperation(route)
+			}
+			if entity == "" || op == "" {
+				continue
+			}
+			operation := pathItem.GetOperation(string(method))
+			if operation.Extensions == nil {
+				operation.Extensions = make(map[string]any)
+			}
+			operation.Extensions[entityOperationExtension] = entity + "#" + string(op)
+			if t == nil || t.Name() != entity {
+				continue
+			}
+			if schema, ok := spec.Components.Schemas[api.getModelName(t)]; ok && schema.Value != nil {
+				if schema.Value.Extensions == nil {
+					schema.Value.Extensions = make(map[string]any)
+				}
+				schema.Value.Extensions[entityExtension] = entity
+			}
+		}
+	}
+	return spec, nil
+}
+
+// getEntityType returns the type of the route's first successful response.
+func getEntityType(route *Route) reflect.Type {
+	for _, status := range getSortedKeys(route.Models.Responses) {
+		if status < 200 || status > 299 {
+			continue
+		}
+		t := route.Models.Responses[status].Type
+		for t != nil && t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t != nil && t.Kind() == reflect.Struct {
+			return t
+		}
+	}
+	return nil
+}
+
+// getPathEntity returns the entity of the routes of a path, preferring an explicitly
+// set entity, and then the response of the GET route.
+func getPathEntity(methodToRoute MethodToRoute) (entity string, t reflect.Type) {
+	for _, method := range getSortedMethods(methodToRoute) {
+		if route := methodToRoute[method]; route.Entity != "" {
+			return route.Entity, getEntityType(route)
+		}
+	}
+	if get, ok := methodToRoute[http.MethodGet]; ok {
+		if t = getEntityType(get); t != nil {
+			return t.Name(), t
+		}
+	}
+	return "", nil
+}
+
+func inferEntityOperation(route *Route) EntityOperation {
+	isItem := strings.HasSuffix(getPath(route.Pattern), "}")
+	switch {
+	case route.Method == http.MethodPost && !isItem:
+		return EntityCreate
+	case route.Method == http.MethodGet && isItem:
+		return EntityRead
+	case (route.Method == http.MethodPut || route.Method == http.MethodPatch) && isItem:
+		return EntityUpdate
+	case route.Method == http.MethodDelete && isItem:
+		return EntityDelete
+	}
+	return ""
+}
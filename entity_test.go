@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSpecWithEntityExtensions(t *testing.T) {
+	api := NewAPI("test")
+	api.Post("/users").HasResponseModel(http.StatusCreated, ModelOf[User]())
+	api.Get("/users").HasResponseModel(http.StatusOK, ModelOf[[]User]())
+	api.Get("/users/{id}").HasResponseModel(http.StatusOK, ModelOf[User]())
+	api.Patch("/users/{id}").HasResponseModel(http.StatusOK, ModelOf[User]())
+	api.Delete("/users/{id}").HasResponseModel(http.StatusNoContent, ModelOf[OK]()).
+		HasEntityOperation("User", EntityDelete)
+
+	spec, err := api.SpecWithEntityExtensions()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+
+	actual := make(map[string]any)
+	for path, pathItem := range spec.Paths.Map() {
+		for method, op := range pathItem.Operations() {
+			if v, ok := op.Extensions["x-speakeasy-entity-operation"]; ok {
+				actual[method+" "+path] = v
+			}
+		}
+	}
+	expected := map[string]any{
+		"POST /users":        "User#create",
+		"GET /users/{id}":    "User#read",
+		"PATCH /users/{id}":  "User#update",
+		"DELETE /users/{id}": "User#delete",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	user := spec.Components.Schemas["github_com_heimspiel_rest_User"].Value
+	if user.Extensions["x-speakeasy-entity"] != "User" {
+		t.Errorf("expected the User schema to be marked as an entity, got %v", user.Extensions)
+	}
+	if _, ok := spec.Components.Schemas["github_com_heimspiel_rest_OK"].Value.Extensions["x-speakeasy-entity"]; ok {
+		t.Error("expected the OK schema not to be marked as an entity")
+	}
+
+	// The API's own spec is unchanged.
+	spec, err = api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	if _, ok := spec.Components.Schemas["github_com_heimspiel_rest_User"].Value.Extensions["x-speakeasy-entity"]; ok {
+		t.Error("expected the API's spec to be unchanged")
+	}
+}
@@ -8,13 +8,38 @@ import (
 	"go/token"
 	"go/types"
 	"reflect"
-	"strconv"
+	"strings"
 
 	"golang.org/x/tools/go/packages"
 )
 
+// Constant is a constant of an enum type.
+type Constant struct {
+	// Name of the constant.
+	Name string
+	// Value of the constant, a string, an int, or a uint64 if it doesn't fit in an int.
+	Value any
+	// Doc is the comment of the constant. If the constant has no doc comment, its line
+	// comment is used, or the comment of its declaration if it's declared on its own.
+	Doc string
+}
+
+// Get returns the values of the constants of the type.
 func Get(ty reflect.Type) ([]any, error) {
+	constants, err := GetConstants(ty)
+	if err != nil {
+		return nil, err
+	}
 	var enum []any
+	for _, c := range constants {
+		enum = append(enum, c.Value)
+	}
+	return enum, nil
+}
+
+// GetConstants returns the constants of the type, with their comments.
+func GetConstants(ty reflect.Type) ([]Constant, error) {
+	var constants []Constant
 	config := &packages.Config{
 		Mode: packages.NeedName |
 			packages.NeedFiles |
@@ -30,31 +55,54 @@ func Get(ty reflect.Type) ([]any, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not load package %q", ty.PkgPath())
 	}
+	// When tests are included, the package is loaded a second time with its test files,
+	// so the constants of the other files are seen twice.
+	seen := map[string]bool{}
 	for _, p := range pkgs {
 		for _, syn := range p.Syntax {
 			for _, d := range syn.Decls {
-				if _, ok := d.(*ast.GenDecl); !ok {
+				gd, ok := d.(*ast.GenDecl)
+				if !ok {
 					continue
 				}
-				for _, sp := range d.(*ast.GenDecl).Specs {
+				for _, sp := range gd.Specs {
 					v, ok := sp.(*ast.ValueSpec)
 					if !ok {
 						continue
 					}
 					for _, name := range v.Names {
-						v, err := getConstantValue(ty, name, p)
+						value, err := getConstantValue(ty, name, p)
 						if err != nil {
 							return nil, err
 						}
-						if v != nil {
-							enum = append(enum, v)
+						if value != nil && !seen[name.Name] {
+							seen[name.Name] = true
+							constants = append(constants, Constant{
+								Name:  name.Name,
+								Value: value,
+								Doc:   getDoc(gd, v),
+							})
 						}
 					}
 				}
 			}
 		}
 	}
-	return enum, nil
+	return constants, nil
+}
+
+func getDoc(gd *ast.GenDecl, v *ast.ValueSpec) string {
+	if doc := strings.TrimSpace(v.Doc.Text()); doc != "" {
+		return doc
+	}
+	if comment := strings.TrimSpace(v.Comment.Text()); comment != "" {
+		return comment
+	}
+	// The comment of a group of constants describes the group, not each constant.
+	if len(gd.Specs) == 1 {
+		return strings.TrimSpace(gd.Doc.Text())
+	}
+	return ""
 }
 
 func getConstantValue(ty reflect.Type, name *ast.Ident, pkg *packages.Package) (any, error) {
@@ -67,11 +115,13 @@ func getConstantValue(ty reflect.Type, name *ast.Ident, pkg *packages.Package) (
 			return constant.StringVal(c.Val()), nil
 		}
 		if c.Val().Kind() == constant.Int {
-			n, err := strconv.Atoi(c.Val().ExactString())
-			if err != nil {
-				return nil, fmt.Errorf("could not parse enum %s value: %q", ty.Name(), c.Val().ExactString())
+			if n, exact := constant.Int64Val(c.Val()); exact && n == int64(int(n)) {
+				return int(n), nil
+			}
+			if n, exact := constant.Uint64Val(c.Val()); exact {
+				return n, nil
 			}
-			return n, nil
+			return nil, fmt.Errorf("could not parse enum %s value: %q", ty.Name(), c.Val().ExactString())
 		}
 		return c.Val().ExactString(), nil
 	}
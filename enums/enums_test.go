@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/heimspiel/rest/enums/internal/example"
 )
 
 type stringEnum string
@@ -43,6 +44,35 @@ const (
 	iotaIntEnum3
 )
 
+type documentedEnum string
+
+// documentedEnumA is documented on its own.
+const documentedEnumA documentedEnum = "a"
+
+// The comment of the group isn't used for each constant.
+const (
+	// documentedEnumB has a doc comment.
+	documentedEnumB documentedEnum = "b"
+	documentedEnumC documentedEnum = "c" // documentedEnumC has a line comment.
+	documentedEnumD documentedEnum = "d"
+)
+
+func TestGetConstants(t *testing.T) {
+	constants, err := GetConstants(reflect.TypeOf(documentedEnumA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Constant{
+		{Name: "documentedEnumA", Value: "a", Doc: "documentedEnumA is documented on its own."},
+		{Name: "documentedEnumB", Value: "b", Doc: "documentedEnumB has a doc comment."},
+		{Name: "documentedEnumC", Value: "c", Doc: "documentedEnumC has a line comment."},
+		{Name: "documentedEnumD", Value: "d"},
+	}
+	if diff := cmp.Diff(expected, constants); diff != "" {
+		t.Error(diff)
+	}
+}
+
 func TestGet(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -80,6 +110,21 @@ func TestGet(t *testing.T) {
 				int(iotaIntEnum3),
 			},
 		},
+		{
+			name:     "bit flags",
+			ty:       reflect.TypeOf(example.PermissionRead),
+			expected: []any{1, 2, 4, 7},
+		},
+		{
+			name:     "expressions and conversions across files",
+			ty:       reflect.TypeOf(example.SizeSmall),
+			expected: []any{10, 20, 40, -1, 0, 100, 5},
+		},
+		{
+			name:     "values larger than an int",
+			ty:       reflect.TypeOf(example.BigMax),
+			expected: []any{uint64(1<<64 - 1)},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,4 @@
+package example
+
+// Constants declared in test files are included while testing.
+const SizeTest Size = 5
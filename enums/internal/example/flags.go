@@ -0,0 +1,27 @@
+// Package example contains enums used to test the enums package.
+package example
+
+// Permission is a set of flags.
+type Permission uint
+
+const (
+	PermissionRead Permission = 1 << iota
+	PermissionWrite
+	PermissionDelete
+	PermissionAll = PermissionRead | PermissionWrite | PermissionDelete
+)
+
+// Size is declared with expressions and conversions.
+type Size int64
+
+const (
+	SizeSmall  Size = 10
+	SizeMedium      = SizeSmall * 2
+	SizeLarge       = Size(SizeMedium + 20)
+	SizeNone        = -SizeSmall / SizeSmall
+)
+
+// Big has values that don't fit in an int64.
+type Big uint64
+
+const BigMax Big = 1<<64 - 1
@@ -0,0 +1,7 @@
+package example
+
+// The constants of an enum can be declared in more than one file.
+const (
+	SizeHuge Size = iota * 100
+	SizeGiant
+)
@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"golang.org/x/exp/constraints"
+)
+
+// WithEnumSubset narrows the enum of a property of the model to some of its values, for
+// a single operation, e.g. when creating an order accepts fewer states than reading one
+// returns. If the property is an array, its items are narrowed. If the property doesn't
+// exist, or a value isn't in the enum, Spec returns an error.
+//
+// An enum that is a component is referenced using allOf, so the property is still
+// related to it:
+//
+//	api.Post("/orders").HasRequestModel(rest.ModelOf[Order](), rest.WithEnumSubset("status", "pending", "paid"))
+func WithEnumSubset[T ~string | constraints.Integer](property string, values ...T) ModelOptsErr {
+	return func(api *API, s *openapi3.Schema) error {
+		prop, ok := s.Properties[property]
+		if !ok {
+			return fmt.Errorf("can't narrow enum: %q is not a property", property)
+		}
+		subset, err := getEnumSubset(api, prop, values)
+		if err != nil {
+			return err
+		}
+		s.Properties[property] = subset
+		return nil
+	}
+}
+
+func getEnumSubset[T ~string | constraints.Integer](api *API, ref *openapi3.SchemaRef, values []T) (*openapi3.SchemaRef, error) {
+	if ref.Ref == "" && ref.Value != nil && ref.Value.Type.Is(openapi3.TypeArray) && ref.Value.Items != nil {
+		items, err := getEnumSubset(api, ref.Value.Items, values)
+		if err != nil {
+			return nil, err
+		}
+		array := *ref.Value
+		array.Items = items
+		return openapi3.NewSchemaRef("", &array), nil
+	}
+	subset := &openapi3.Schema{}
+	// The values of the enum are known, whether it's a component or inline.
+	var enum []any
+	if ref.Ref != "" {
+		subset.AllOf = openapi3.SchemaRefs{openapi3.NewSchemaRef(ref.Ref, nil)}
+		if component, ok := api.models[getComponentName(ref.Ref)]; ok {
+			enum = component.Enum
+		}
+	} else if ref.Value != nil {
+		*subset = *ref.Value
+		enum = subset.Enum
+	}
+	subset.Type = &openapi3.Types{openapi3.TypeString}
+	if reflect.TypeFor[T]().Kind() != reflect.String {
+		subset.Type = &openapi3.Types{openapi3.TypeInteger}
+	}
+	subset.Enum = nil
+	for _, v := range values {
+		// The subset must be within the values of the enum.
+		if enum != nil && !isEnumValue(enum, v) {
+			return nil, fmt.Errorf("can't narrow enum: %v is not one of its values", v)
+		}
+		subset.Enum = append(subset.Enum, v)
+	}
+	return openapi3.NewSchemaRef("", subset), nil
+}
+
+// isEnumValue returns true if the value is in the enum. The schema has been copied through
+// JSON, so the values are compared as text.
+func isEnumValue(enum []any, v any) bool {
+	return slices.ContainsFunc(enum, func(e any) bool {
+		return fmt.Sprint(e) == fmt.Sprint(v)
+	})
+}
@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWithEnumSubset(t *testing.T) {
+	newSchema := func() *openapi3.Schema {
+		return openapi3.NewObjectSchema().
+			WithProperty("status", &openapi3.Schema{
+				Type:        &openapi3.Types{openapi3.TypeString},
+				Description: "The status of the order.",
+				Enum:        []any{"pending", "paid", "shipped"},
+			})
+	}
+
+	t.Run("inline enums are filtered", func(t *testing.T) {
+		s := newSchema()
+		if err := WithEnumSubset("status", "paid", "shipped")(NewAPI("test"), s); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := &openapi3.Schema{
+			Type:        &openapi3.Types{openapi3.TypeString},
+			Description: "The status of the order.",
+			Enum:        []any{"paid", "shipped"},
+		}
+		if diff := cmp.Diff(expected, s.Properties["status"].Value); diff != "" {
+			t.Error(diff)
+		}
+	})
+	t.Run("values must be in inline enums", func(t *testing.T) {
+		if err := WithEnumSubset("status", "cancelled")(NewAPI("test"), newSchema()); err == nil {
+			t.Error("expected an error")
+		}
+	})
+	t.Run("values must be in referenced enums", func(t *testing.T) {
+		api := NewAPI("test")
+		name, _, err := api.RegisterModel(ModelOf[StringEnum](), WithEnumConstants[StringEnum]())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		s := openapi3.NewObjectSchema()
+		s.Properties = openapi3.Schemas{"status": openapi3.NewSchemaRef("#/components/schemas/"+name, nil)}
+		if err = WithEnumSubset("status", "cancelled")(api, s); err == nil {
+			t.Error("expected an error")
+		}
+	})
+	t.Run("the property must exist", func(t *testing.T) {
+		if err := WithEnumSubset("state", "paid")(NewAPI("test"), newSchema()); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// envelopeDataProperty is the property of a response envelope that contains the response.
+const envelopeDataProperty = "data"
+
+// WithResponseEnvelope wraps the responses of all routes in an envelope, whose fields
+// can be interfaces, e.g.
+//
+//	type Envelope struct {
+//		Data  any            `json:"data"`
+//		Error *Error         `json:"error,omitempty"`
+//		Meta  map[string]any `json:"meta,omitempty"`
+//	}
+//
+//	api := rest.NewAPI("users", rest.WithResponseEnvelope(rest.ModelOf[Envelope]()))
+//
+// The data property of the envelope is replaced by the schema of each response. If the
+// response is a component, the envelope is added as a component named after both, e.g.
+// UserEnvelope, otherwise it's inlined. Error responses, with a status code of 400 or
+// more, are left as they are, since their models usually include the envelope's fields.
+//
+// Use Route.HasResponseEnvelope and Route.HasNoResponseEnvelope to change the envelope
+// of a route.
+func WithResponseEnvelope(envelope Model) APIOpts {
+	return func(api *API) {
+		api.ResponseEnvelope = &envelope
+	}
+}
+
+// HasResponseEnvelope wraps the responses of the route in the envelope, instead of the
+// API's envelope, see WithResponseEnvelope.
+func (rm *Route) HasResponseEnvelope(envelope Model) *Route {
+	rm.ResponseEnvelope = &envelope
+	rm.NoResponseEnvelope = false
+	return rm
+}
+
+// HasNoResponseEnvelope documents the responses of the route as they are, even if the
+// API has an envelope, e.g. for health checks or file downloads.
+func (rm *Route) HasNoResponseEnvelope() *Route {
+	rm.ResponseEnvelope = nil
+	rm.NoResponseEnvelope = true
+	return rm
+}
+
+// getResponseEnvelope returns the envelope of the response of the route, if it has one.
+func (api *API) getResponseEnvelope(route *Route, status int) (envelope *Model, ok bool) {
+	if route.NoResponseEnvelope || status >= 400 {
+		return nil, false
+	}
+	if route.ResponseEnvelope != nil {
+		return route.ResponseEnvelope, true
+	}
+	return api.ResponseEnvelope, api.ResponseEnvelope != nil
+}
+
+// withResponseEnvelope returns the schema of the envelope, with its data property set to
+// the schema of the response.
+func (api *API) withResponseEnvelope(envelope Model, ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+	r := &registration{
+		inProgress: make(map[reflect.Type]bool),
+		path:       []string{envelope.Type.String()},
+		anyValues:  true,
+	}
+	envelopeName, envelopeSchema, err := api.registerModel(r, envelope)
+	if err != nil {
+		return nil, err
+	}
+	if envelopeSchema.Properties[envelopeDataProperty] == nil {
+		return nil, fmt.Errorf("response envelope %q has no %s property", envelopeName, envelopeDataProperty)
+	}
+	schema, err := cloneSchema(envelopeSchema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy schema %q: %w", envelopeName, err)
+	}
+	schema.Properties[envelopeDataProperty] = ref
+	if ref.Ref == "" {
+		return openapi3.NewSchemaRef("", schema), nil
+	}
+	name := getComponentName(ref.Ref)
+	return api.addDerivedComponent(name+envelopeName, fmt.Sprintf("envelope %q of %q", envelopeName, name), schema)
+}
@@ -0,0 +1,32 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type UserEnvelope struct {
+	User User `json:"user"`
+}
+
+func TestResponseEnvelopeNameCollision(t *testing.T) {
+	// The model is registered after the envelope of User, which has the same name.
+	api := NewAPI("test", WithResponseEnvelope(ModelOf[Envelope]()))
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+	api.Get("/a").
+		HasResponseModel(http.StatusOK, ModelOf[User]()).
+		HasResponseDescription(http.StatusOK, "The user.")
+	api.Get("/b").
+		HasNoResponseEnvelope().
+		HasResponseModel(http.StatusOK, ModelOf[UserEnvelope]()).
+		HasResponseDescription(http.StatusOK, "The user.")
+
+	_, err := api.Spec()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "has the same schema name") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
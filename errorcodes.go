@@ -0,0 +1,184 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// errorCodesExtension contains the catalog of error codes of the API, and the codes
+// of each error response.
+const errorCodesExtension = "x-error-codes"
+
+// ErrorCode is a machine-readable error that routes can fail with, see RegisterErrorCode.
+type ErrorCode struct {
+	// Code of the error, e.g. "user_not_found".
+	Code string
+	// Status is the HTTP status code of the response, e.g. http.StatusNotFound.
+	Status int
+	// Description of the error.
+	Description string
+	// Model of the body of the response. If the type is nil, the response has no body.
+	Model Model
+}
+
+// errorCatalogEntry is an error code in the x-error-codes extension of the specification.
+type errorCatalogEntry struct {
+	Code        string              `json:"code"`
+	Status      int                 `json:"status"`
+	Description string              `json:"description,omitempty"`
+	Schema      *openapi3.SchemaRef `json:"schema,omitempty"`
+}
+
+// RegisterErrorCode adds an error code to the catalog of the API, which is added to the
+// specification as x-error-codes, so that clients can handle each error. Routes that
+// declare the code with MayFailWith document the error response.
+func (api *API) RegisterErrorCode(code string, status int, description string, model Model) {
+	if _, ok := api.errorCodes[code]; ok {
+		api.errs = append(api.errs, fmt.Errorf("error code %q is already registered", code))
+		return
+	}
+	if status < 400 || status > 599 {
+		api.errs = append(api.errs, fmt.Errorf("error code %q has status %d, expected 4xx or 5xx", code, status))
+		return
+	}
+	if api.errorCodes == nil {
+		api.errorCodes = make(map[string]ErrorCode)
+	}
+	api.errorCodes[code] = ErrorCode{Code: code, Status: status, Description: description, Model: model}
+}
+
+// MayFailWith documents that the route may fail with the error codes, which must be
+// registered with RegisterErrorCode. A response is added for the status of each code,
+// unless the route already has a model for that status. Codes that share a status are
+// documented in the same response, with a oneOf of their models if they differ.
+func (rm *Route) MayFailWith(codes ...string) *Route {
+	rm.ErrorCodes = append(rm.ErrorCodes, codes...)
+	return rm
+}
+
+// addErrorCodeResponses adds the responses of the error codes that the route may fail with.
+func (api *API) addErrorCodeResponses(op *openapi3.Operation, route *Route, operation string) error {
+	byStatus := make(map[int][]ErrorCode)
+	for _, code := range route.ErrorCodes {
+		ec, ok := api.errorCodes[code]
+		if !ok {
+			return fmt.Errorf("%s: unknown error code %q", operation, code)
+		}
+		if !slices.ContainsFunc(byStatus[ec.Status], func(c ErrorCode) bool { return c.Code == code }) {
+			byStatus[ec.Status] = append(byStatus[ec.Status], ec)
+		}
+	}
+	for _, status := range getSortedKeys(byStatus) {
+		codes := byStatus[status]
+		names := make([]string, len(codes))
+		for i, ec := range codes {
+			names[i] = ec.Code
+		}
+		// Declared responses are kept, but list the codes.
+		resp := op.Responses.Status(status)
+		if resp == nil {
+			r, err := api.newErrorCodeResponse(route, codes)
+			if err != nil {
+				return err
+			}
+			description := route.ResponseDescriptions[status]
+			if description == "" {
+				description = getErrorCodesDescription(codes)
+			}
+			op.AddResponse(status, r.WithDescription(description))
+			resp = op.Responses.Status(status)
+		}
+		if resp.Value.Extensions == nil {
+			resp.Value.Extensions = make(map[string]any)
+		}
+		resp.Value.Extensions[errorCodesExtension] = names
+	}
+	return nil
+}
+
+// newErrorCodeResponse returns a response containing the models of the error codes.
+func (api *API) newErrorCodeResponse(route *Route, codes []ErrorCode) (*openapi3.Response, error) {
+	resp := openapi3.NewResponse()
+	var models []Model
+	var refs openapi3.SchemaRefs
+	for _, ec := range codes {
+		if ec.Model.Type == nil || slices.ContainsFunc(models, func(m Model) bool { return m.Type == ec.Model.Type }) {
+			continue
+		}
+		name, schema, err := api.RegisterModel(ec.Model)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, ec.Model)
+		refs = append(refs, api.getSchemaReferenceOrValue(name, schema))
+	}
+	switch len(refs) {
+	case 0:
+		return resp, nil
+	case 1:
+		return resp.WithContent(getContent(route, models[0], refs[0])), nil
+	}
+	return resp.WithContent(getContent(route, models[0], openapi3.NewSchemaRef("", &openapi3.Schema{OneOf: refs}))), nil
+}
+
+// getErrorCodesDescription describes the response of the error codes, e.g.
+// "user_not_found: The user doesn't exist."
+func getErrorCodesDescription(codes []ErrorCode) string {
+	lines := make([]string, len(codes))
+	for i, ec := range codes {
+		lines[i] = ec.Code
+		if ec.Description != "" {
+			lines[i] += ": " + ec.Description
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// addErrorCatalog adds the registered error codes to the specification as x-error-codes.
+func (api *API) addErrorCatalog(spec *openapi3.T) error {
+	if len(api.errorCodes) == 0 {
+		return nil
+	}
+	var catalog []errorCatalogEntry
+	for _, code := range getSortedKeys(api.errorCodes) {
+		ec := api.errorCodes[code]
+		entry := errorCatalogEntry{Code: ec.Code, Status: ec.Status, Description: ec.Description}
+		if ec.Model.Type != nil {
+			name, schema, err := api.RegisterModel(ec.Model)
+			if err != nil {
+				return err
+			}
+			entry.Schema = api.getSchemaReferenceOrValue(name, schema)
+		}
+		catalog = append(catalog, entry)
+	}
+	if spec.Extensions == nil {
+		spec.Extensions = make(map[string]any)
+	}
+	spec.Extensions[errorCodesExtension] = catalog
+	return nil
+}
+
+// getErrorCatalog returns the catalog of error codes in the x-error-codes extension of a
+// specification. Copies of specifications contain the catalog as JSON values.
+func getErrorCatalog(spec *openapi3.T) (catalog []errorCatalogEntry) {
+	v, ok := spec.Extensions[errorCodesExtension]
+	if !ok {
+		return nil
+	}
+	if catalog, ok := v.([]errorCatalogEntry); ok {
+		return catalog
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	if err = json.Unmarshal(data, &catalog); err != nil {
+		return nil
+	}
+	return catalog
+}
@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestErrorCodeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(api *API)
+		expected string
+	}{
+		{
+			name: "unknown codes are rejected",
+			setup: func(api *API) {
+				api.Get("/users/{id}").MayFailWith("user_not_found")
+			},
+			expected: `GET /users/{id}: unknown error code "user_not_found"`,
+		},
+		{
+			name: "codes can only be registered once",
+			setup: func(api *API) {
+				api.RegisterErrorCode("user_not_found", http.StatusNotFound, "", Model{})
+				api.RegisterErrorCode("user_not_found", http.StatusGone, "", Model{})
+			},
+			expected: `error code "user_not_found" is already registered`,
+		},
+		{
+			name: "codes must have an error status",
+			setup: func(api *API) {
+				api.RegisterErrorCode("accepted", http.StatusAccepted, "", Model{})
+			},
+			expected: `error code "accepted" has status 202, expected 4xx or 5xx`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("test")
+			test.setup(api)
+			_, err := api.Spec()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected error containing %q, got %q", test.expected, err.Error())
+			}
+		})
+	}
+}
+
+func TestErrorCatalogSchemasArePruned(t *testing.T) {
+	type UnusedError struct {
+		Code string `json:"code"`
+	}
+	tests := []struct {
+		name string
+		spec func(api *API) (*openapi3.T, error)
+	}{
+		{
+			name: "Spec",
+			spec: func(api *API) (*openapi3.T, error) {
+				return api.Spec()
+			},
+		},
+		{
+			name: "SpecWith",
+			spec: func(api *API) (*openapi3.T, error) {
+				return api.SpecWith(Filter{})
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("test", WithPruneUnusedSchemas())
+			api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+			api.RegisterErrorCode("quota_exceeded", http.StatusTooManyRequests, "", ModelOf[UnusedError]())
+			api.Get("/ok").HasResponseModel(http.StatusOK, ModelOf[OK]())
+			spec, err := test.spec(api)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			data, err := json.Marshal(spec.Extensions[errorCodesExtension])
+			if err != nil {
+				t.Fatalf("failed to marshal the catalog: %v", err)
+			}
+			var catalog []errorCatalogEntry
+			if err = json.Unmarshal(data, &catalog); err != nil {
+				t.Fatalf("failed to unmarshal the catalog: %v", err)
+			}
+			if len(catalog) != 1 || catalog[0].Schema == nil {
+				t.Fatalf("expected the catalog to have a schema, got %s", data)
+			}
+			name := getComponentName(catalog[0].Schema.Ref)
+			if _, ok := spec.Components.Schemas[name]; !ok {
+				t.Errorf("expected %q to be kept, got %v", name, getSortedKeys(spec.Components.Schemas))
+			}
+		})
+	}
+}
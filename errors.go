@@ -0,0 +1,76 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// WithSkipUnsupportedFields omits struct fields that have types which can't be
+// represented in the specification, e.g. channels and functions, and adds a
+// warning, instead of returning an error.
+func WithSkipUnsupportedFields() APIOpts {
+	return func(api *API) {
+		api.SkipUnsupportedFields = true
+	}
+}
+
+// WithSkipRequiredCheck allows schemas to list required properties that don't exist.
+// By default, Spec returns an error, since the spec would break code generators.
+func WithSkipRequiredCheck() APIOpts {
+	return func(api *API) {
+		api.SkipRequiredCheck = true
+	}
+}
+
+// UnsupportedTypeError is returned when a model contains a type that can't be
+// represented in the specification, e.g. a channel, function or complex number.
+type UnsupportedTypeError struct {
+	// Type that isn't supported.
+	Type reflect.Type
+	// Path to the type from the model being registered, e.g. "models.User.Addresses[].Callback".
+	// Elements of slices, arrays and maps are shown as [].
+	Path string
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported type %q", e.Type)
+}
+
+// ModelError is returned when the schema of a model can't be created. Use errors.As
+// to find where the problem is, e.g. to report it in a code generator.
+type ModelError struct {
+	// Path to the type that caused the error from the model being registered,
+	// e.g. "models.User.Addresses[].Street".
+	// Elements of slices, arrays and maps are shown as [].
+	Path string
+	// Type that caused the error.
+	Type reflect.Type
+	// Field is the struct field closest to the error, or nil if the error
+	// wasn't caused by a struct field. The Tag of the field is included.
+	Field *reflect.StructField
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ModelError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *ModelError) Unwrap() error {
+	return e.Err
+}
+
+// newFieldError returns a ModelError for the field of type t, e.g. for an invalid tag.
+func (r *registration) newFieldError(t reflect.Type, f reflect.StructField, err error) error {
+	return &ModelError{Path: r.getPath() + "." + f.Name, Type: t, Field: &f, Err: err}
+}
+
+// withField sets the field of the ModelError in err, if it's not already set.
+func withField(err error, f reflect.StructField) error {
+	var modelErr *ModelError
+	if errors.As(err, &modelErr) && modelErr.Field == nil {
+		modelErr.Field = &f
+	}
+	return err
+}
@@ -0,0 +1,341 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+type WithUnsupportedFieldsItem struct {
+	Name     string     `json:"name"`
+	Callback func()     `json:"callback"`
+	Number   complex128 `json:"number"`
+}
+
+type WithUnsupportedFields struct {
+	ID    string                      `json:"id"`
+	Items []WithUnsupportedFieldsItem `json:"items"`
+	Done  chan struct{}               `json:"done"`
+}
+
+func TestUnsupportedTypeError(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/").HasResponseModel(http.StatusOK, ModelOf[WithUnsupportedFields]())
+	_, err := api.Spec()
+
+	var unsupported *UnsupportedTypeError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected an UnsupportedTypeError, got %v", err)
+	}
+	if expected := "rest.WithUnsupportedFields.Items[].Callback"; unsupported.Path != expected {
+		t.Errorf("expected path %q, got %q", expected, unsupported.Path)
+	}
+	if expected := "func()"; unsupported.Type.String() != expected {
+		t.Errorf("expected type %q, got %q", expected, unsupported.Type)
+	}
+}
+
+func TestSkipUnsupportedFields(t *testing.T) {
+	api := NewAPI("test", WithSkipUnsupportedFields())
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+	api.Get("/").HasResponseModel(http.StatusOK, ModelOf[WithUnsupportedFields]())
+	if _, err := api.Spec(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"id", "items"}, getSortedKeys(api.models["WithUnsupportedFields"].Properties)); diff != "" {
+		t.Errorf("unexpected properties: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"name"}, getSortedKeys(api.models["WithUnsupportedFieldsItem"].Properties)); diff != "" {
+		t.Errorf("unexpected properties: %s", diff)
+	}
+	var skipped []string
+	for _, w := range api.Warnings() {
+		if w.Kind == WarningUnsupportedField {
+			skipped = append(skipped, w.Location+" "+w.Message)
+		}
+	}
+	expected := []string{
+		`rest.WithUnsupportedFieldsItem field "Callback" skipped: rest.WithUnsupportedFields.Items[].Callback: unsupported type "func()"`,
+		`rest.WithUnsupportedFieldsItem field "Number" skipped: rest.WithUnsupportedFields.Items[].Number: unsupported type "complex128"`,
+		`rest.WithUnsupportedFields field "Done" skipped: rest.WithUnsupportedFields.Done: unsupported type "chan struct {}"`,
+	}
+	if diff := cmp.Diff(expected, skipped); diff != "" {
+		t.Error(diff)
+	}
+}
+
+type WithInvalidMapItem struct {
+	Values map[int]string `json:"values"`
+}
+
+type WithInvalidMap struct {
+	Items []WithInvalidMapItem `json:"items"`
+}
+
+func TestModelError(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/").HasResponseModel(http.StatusOK, ModelOf[WithInvalidMap]())
+	_, err := api.Spec()
+
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) {
+		t.Fatalf("expected a ModelError, got %v", err)
+	}
+	if expected := "rest.WithInvalidMap.Items[].Values"; modelErr.Path != expected {
+		t.Errorf("expected path %q, got %q", expected, modelErr.Path)
+	}
+	if modelErr.Field == nil || modelErr.Field.Name != "Values" {
+		t.Fatalf("expected field Values, got %v", modelErr.Field)
+	}
+	if expected := "values"; modelErr.Field.Tag.Get("json") != expected {
+		t.Errorf("expected json tag %q, got %q", expected, modelErr.Field.Tag.Get("json"))
+	}
+	expected := `rest.WithInvalidMap.Items[].Values: maps must have a string key, but this map is of type "int"`
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+}
+
+type WithTaggedUnsupportedFields struct {
+	Name     string          `json:"name" title:"Name"`
+	Callback *func()         `json:"callback,omitempty" title:"Callback" description:"Called when done."`
+	Updates  []chan struct{} `json:"updates" doc:"Updates to the record."`
+}
+
+func TestTaggedUnsupportedFields(t *testing.T) {
+	var customised []string
+	opts := []APIOpts{
+		WithApplyCustomSchemaToType(func(rt reflect.Type, s *openapi3.Schema) {
+			if s == nil {
+				t.Errorf("type %v customised with a nil schema", rt)
+			}
+		}),
+		WithApplyCustomSchemaToField(func(rt reflect.Type, f reflect.StructField, s *openapi3.Schema) {
+			if s == nil {
+				t.Errorf("field %q customised with a nil schema", f.Name)
+			}
+			customised = append(customised, f.Name)
+		}),
+	}
+
+	t.Run("error", func(t *testing.T) {
+		customised = nil
+		api := NewAPI("test", opts...)
+		_, _, err := api.RegisterModel(ModelOf[WithTaggedUnsupportedFields]())
+
+		var modelErr *ModelError
+		if !errors.As(err, &modelErr) {
+			t.Fatalf("expected a ModelError, got %v", err)
+		}
+		if modelErr.Field == nil || modelErr.Field.Tag.Get("title") != "Callback" {
+			t.Errorf("expected the error to include the tagged field, got %v", modelErr.Field)
+		}
+		var unsupported *UnsupportedTypeError
+		if !errors.As(err, &unsupported) {
+			t.Errorf("expected an UnsupportedTypeError, got %v", err)
+		}
+		if len(api.models) != 0 {
+			t.Errorf("expected no models to be registered, got %v", getSortedKeys(api.models))
+		}
+	})
+	t.Run("skip", func(t *testing.T) {
+		customised = nil
+		api := NewAPI("test", append(opts, WithSkipUnsupportedFields())...)
+		_, schema, err := api.RegisterModel(ModelOf[WithTaggedUnsupportedFields]())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if diff := cmp.Diff([]string{"name"}, getSortedKeys(schema.Properties)); diff != "" {
+			t.Errorf("unexpected properties: %s", diff)
+		}
+		if diff := cmp.Diff([]string{"Name"}, customised); diff != "" {
+			t.Errorf("unexpected customised fields: %s", diff)
+		}
+	})
+}
+
+type WithMistypedRequired struct {
+	ID   string `json:"id"`
+	Name string `json:"fullName"`
+}
+
+func (WithMistypedRequired) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Required = []string{"id", "name"}
+}
+
+func TestRequiredPropertiesMustExist(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/").HasResponseModel(http.StatusOK, ModelOf[WithMistypedRequired]())
+	_, err := api.Spec()
+
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) {
+		t.Fatalf("expected a ModelError, got %v", err)
+	}
+	expected := `rest.WithMistypedRequired: required property "name" is not a property of the schema, properties are fullName, id`
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err)
+	}
+}
+
+func TestSkipRequiredCheck(t *testing.T) {
+	api := NewAPI("test", WithSkipRequiredCheck())
+	_, _, err := api.RegisterModel(ModelOf[WithMistypedRequired]())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+type WithFailingCustomSchema struct {
+	ID string `json:"id"`
+}
+
+var errCustomSchema = errors.New("the schema can't be customised")
+
+func (WithFailingCustomSchema) ApplyCustomSchemaErr(s *openapi3.Schema) error {
+	return errCustomSchema
+}
+
+type WithFailingCustomSchemaField struct {
+	Item WithFailingCustomSchema `json:"item"`
+}
+
+func TestApplyCustomSchemaErr(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/").HasResponseModel(http.StatusOK, ModelOf[WithFailingCustomSchemaField]())
+	_, err := api.Spec()
+
+	if !errors.Is(err, errCustomSchema) {
+		t.Fatalf("expected the error to be returned, got %v", err)
+	}
+	if _, _, err := NewAPI("test").RegisterModel(ModelOf[WithFailingCustomSchema]()); !errors.Is(err, errCustomSchema) {
+		t.Errorf("expected the error to be returned for the model, got %v", err)
+	}
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) {
+		t.Fatalf("expected a ModelError, got %v", err)
+	}
+	if expected := "rest.WithFailingCustomSchemaField.Item"; modelErr.Path != expected {
+		t.Errorf("expected path %q, got %q", expected, modelErr.Path)
+	}
+}
+
+type WithInvalidConst struct {
+	Version int `json:"version" const:"v2"`
+}
+
+func TestInvalidConstTag(t *testing.T) {
+	_, _, err := NewAPI("test").RegisterModel(ModelOf[WithInvalidConst]())
+
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) {
+		t.Fatalf("expected a ModelError, got %v", err)
+	}
+	if expected := "rest.WithInvalidConst.Version"; modelErr.Path != expected {
+		t.Errorf("expected path %q, got %q", expected, modelErr.Path)
+	}
+	if modelErr.Field == nil || modelErr.Field.Name != "Version" {
+		t.Errorf("expected the field to be set, got %v", modelErr.Field)
+	}
+}
+
+type WithInvalidSet struct {
+	Count int `json:"count" set:"1,2"`
+}
+
+func TestInvalidSetTag(t *testing.T) {
+	_, _, err := NewAPI("test").RegisterModel(ModelOf[WithInvalidSet]())
+
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) {
+		t.Fatalf("expected a ModelError, got %v", err)
+	}
+	if expected := "rest.WithInvalidSet.Count"; modelErr.Path != expected {
+		t.Errorf("expected path %q, got %q", expected, modelErr.Path)
+	}
+}
+
+type WithInvalidInt64Tag struct {
+	Name string `json:"name" int64:"string"`
+}
+
+func TestInvalidInt64Tag(t *testing.T) {
+	_, _, err := NewAPI("test").RegisterModel(ModelOf[WithInvalidInt64Tag]())
+
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) {
+		t.Fatalf("expected a ModelError, got %v", err)
+	}
+	if expected := "rest.WithInvalidInt64Tag.Name"; modelErr.Path != expected {
+		t.Errorf("expected path %q, got %q", expected, modelErr.Path)
+	}
+}
+
+type WithEmptyUnit struct {
+	Size int `json:"size" unit:""`
+}
+
+func TestEmptyUnitTag(t *testing.T) {
+	_, _, err := NewAPI("test").RegisterModel(ModelOf[WithEmptyUnit]())
+
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) {
+		t.Fatalf("expected a ModelError, got %v", err)
+	}
+	if expected := "rest.WithEmptyUnit.Size"; modelErr.Path != expected {
+		t.Errorf("expected path %q, got %q", expected, modelErr.Path)
+	}
+}
+
+type WithInvalidSensitiveTag struct {
+	Password string `json:"password" sensitive:"yes please"`
+}
+
+func TestInvalidSensitiveTag(t *testing.T) {
+	_, _, err := NewAPI("test").RegisterModel(ModelOf[WithInvalidSensitiveTag]())
+
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) {
+		t.Fatalf("expected a ModelError, got %v", err)
+	}
+	if expected := "rest.WithInvalidSensitiveTag.Password"; modelErr.Path != expected {
+		t.Errorf("expected path %q, got %q", expected, modelErr.Path)
+	}
+}
+
+type WithInvalidGormSize struct {
+	Name string `json:"name" gorm:"size:large"`
+}
+
+func TestInvalidGormTag(t *testing.T) {
+	_, _, err := NewAPI("test", WithGormTagMapping()).RegisterModel(ModelOf[WithInvalidGormSize]())
+
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) {
+		t.Fatalf("expected a ModelError, got %v", err)
+	}
+	if expected := "rest.WithInvalidGormSize.Name"; modelErr.Path != expected {
+		t.Errorf("expected path %q, got %q", expected, modelErr.Path)
+	}
+}
+
+type WithInvalidContentEncoding struct {
+	Size int `json:"size" contentEncoding:"base64"`
+}
+
+func TestInvalidContentTags(t *testing.T) {
+	_, _, err := NewAPI("test").RegisterModel(ModelOf[WithInvalidContentEncoding]())
+
+	var modelErr *ModelError
+	if !errors.As(err, &modelErr) {
+		t.Fatalf("expected a ModelError, got %v", err)
+	}
+	if expected := "rest.WithInvalidContentEncoding.Size"; modelErr.Path != expected {
+		t.Errorf("expected path %q, got %q", expected, modelErr.Path)
+	}
+}
@@ -51,7 +51,9 @@ func main() {
 	})
 
 	// Create the API definition.
-	api := rest.NewAPI("Messaging API")
+	api := rest.NewAPI("Messaging API",
+		rest.WithVersion("v1.0.0"),
+		rest.WithAPIDescription("Messages API"))
 
 	// Create the routes and parameters of the Router in the REST API definition with an
 	// adapter, or do it manually.
@@ -87,10 +89,6 @@ func main() {
 		log.Fatalf("failed to create spec: %v", err)
 	}
 
-	// Customise it.
-	spec.Info.Version = "v1.0.0"
-	spec.Info.Description = "Messages API"
-
 	// Attach the UI handler.
 	ui, err := swaggerui.New(spec)
 	if err != nil {
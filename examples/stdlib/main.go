@@ -19,7 +19,9 @@ func main() {
 	router.Handle("/topics", &get.Handler{})
 	router.Handle("/topic", &post.Handler{})
 
-	api := rest.NewAPI("messages")
+	api := rest.NewAPI("messages",
+		rest.WithVersion("v1.0.0"),
+		rest.WithAPIDescription("Messages API"))
 	api.StripPkgPaths = []string{"github.com/heimspiel/rest/example", "github.com/a-h/respond"}
 
 	// It's possible to customise the OpenAPI schema for each type.
@@ -45,10 +47,6 @@ func main() {
 		log.Fatalf("failed to create spec: %v", err)
 	}
 
-	// Apply any global customisation.
-	spec.Info.Version = "v1.0.0."
-	spec.Info.Description = "Messages API"
-
 	// Attach the Swagger UI handler to your router.
 	ui, err := swaggerui.New(spec)
 	if err != nil {
@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"gopkg.in/yaml.v2"
+)
+
+// WithExternalRef references the schema of the model from another document, instead of
+// generating it, e.g. "common.yaml#/components/schemas/User" for models shared between services.
+func WithExternalRef(model Model, ref string) APIOpts {
+	return func(api *API) {
+		api.externalRefs[model.Type] = ref
+	}
+}
+
+// getExternalRef returns the external reference of the schema with the given name, if it has one.
+func (api *API) getExternalRef(name string) (ref string, ok bool) {
+	t, ok := api.modelTypes[name]
+	if !ok {
+		return "", false
+	}
+	ref, ok = api.externalRefs[t]
+	return ref, ok
+}
+
+// allowExternalRefs configures the loader to resolve external references to empty
+// schemas, since the referenced documents aren't available when the spec is created.
+func (api *API) allowExternalRefs(loader *openapi3.Loader) {
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+		doc := make(map[string]any)
+		for _, ref := range api.externalRefs {
+			file, pointer, _ := strings.Cut(ref, "#")
+			if !strings.HasSuffix(location.Path, file) {
+				continue
+			}
+			parent := doc
+			segments := strings.Split(strings.Trim(pointer, "/"), "/")
+			for _, segment := range segments[:len(segments)-1] {
+				child, ok := parent[segment].(map[string]any)
+				if !ok {
+					child = make(map[string]any)
+					parent[segment] = child
+				}
+				parent = child
+			}
+			parent[segments[len(segments)-1]] = map[string]any{}
+		}
+		return json.Marshal(doc)
+	}
+}
+
+// WriteSplitSpec writes the spec as two YAML documents: one containing the paths, and one
+// containing the components. References from the paths to the components are made relative
+// to componentsFile, which is the location of the components document, e.g. "components.yaml".
+func WriteSplitSpec(spec *openapi3.T, componentsFile string, paths, components io.Writer) error {
+	data, err := spec.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("failed to marshal spec: %w", err)
+	}
+	var pathsDoc map[string]any
+	if err = json.Unmarshal(data, &pathsDoc); err != nil {
+		return fmt.Errorf("failed to unmarshal spec: %w", err)
+	}
+
+	// The components document is a valid OpenAPI document with no paths.
+	componentsDoc := map[string]any{
+		"openapi":    pathsDoc["openapi"],
+		"info":       pathsDoc["info"],
+		"paths":      map[string]any{},
+		"components": pathsDoc["components"],
+	}
+	delete(pathsDoc, "components")
+	setRefLocation(pathsDoc, componentsFile)
+
+	if err = writeYAML(paths, pathsDoc); err != nil {
+		return fmt.Errorf("failed to write paths: %w", err)
+	}
+	if err = writeYAML(components, componentsDoc); err != nil {
+		return fmt.Errorf("failed to write components: %w", err)
+	}
+	return nil
+}
+
+// setRefLocation points local references to components at the given document.
+func setRefLocation(v any, location string) {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if ref, ok := child.(string); ok && k == "$ref" && strings.HasPrefix(ref, "#/components/") {
+				v[k] = location + ref
+				continue
+			}
+			setRefLocation(child, location)
+		}
+	case []any:
+		for _, child := range v {
+			setRefLocation(child, location)
+		}
+	}
+}
+
+func writeYAML(w io.Writer, v any) error {
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExternalRef(t *testing.T) {
+	api := NewAPI("test", WithExternalRef(ModelOf[User](), "common.yaml#/components/schemas/User"))
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+	api.Get("/owner").HasResponseModel(http.StatusOK, ModelOf[WithOwner]())
+	api.Get("/users").HasResponseModel(http.StatusOK, ModelOf[[]User]())
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"WithOwner"}, getSortedKeys(spec.Components.Schemas)); diff != "" {
+		t.Errorf("unexpected schemas: %s", diff)
+	}
+	if ref := spec.Components.Schemas["WithOwner"].Value.Properties["owner"].Ref; ref != "common.yaml#/components/schemas/User" {
+		t.Errorf("unexpected field reference %q", ref)
+	}
+	items := spec.Paths.Value("/users").Get.Responses.Status(http.StatusOK).Value.Content.Get("application/json").Schema.Value.Items
+	if items.Ref != "common.yaml#/components/schemas/User" {
+		t.Errorf("unexpected items reference %q", items.Ref)
+	}
+}
+
+func TestWriteSplitSpec(t *testing.T) {
+	api := NewAPI("test")
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+	api.Get("/owner").HasResponseModel(http.StatusOK, ModelOf[WithOwner]())
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var paths, components bytes.Buffer
+	if err = WriteSplitSpec(spec, "components.yaml", &paths, &components); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(paths.String(), "schemas:") {
+		t.Errorf("expected the paths document not to contain schemas:\n%s", paths.String())
+	}
+
+	// The documents can be loaded together.
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(_ *openapi3.Loader, location *url.URL) ([]byte, error) {
+		if !strings.HasSuffix(location.Path, "components.yaml") {
+			t.Fatalf("unexpected location %q", location)
+		}
+		return components.Bytes(), nil
+	}
+	loaded, err := loader.LoadFromData(paths.Bytes())
+	if err != nil {
+		t.Fatalf("failed to load split spec: %v", err)
+	}
+	if err = loaded.Validate(loader.Context); err != nil {
+		t.Fatalf("split spec is invalid: %v", err)
+	}
+	schema := loaded.Paths.Value("/owner").Get.Responses.Status(http.StatusOK).Value.Content.Get("application/json").Schema
+	if schema.Ref != "components.yaml#/components/schemas/WithOwner" {
+		t.Errorf("unexpected reference %q", schema.Ref)
+	}
+	if _, ok := schema.Value.Properties["owner"].Value.Properties["name"]; !ok {
+		t.Error("expected references within the components document to be resolved")
+	}
+}
+
+func TestExternalRefWithFilter(t *testing.T) {
+	api := NewAPI("test", WithExternalRef(ModelOf[User](), "common.yaml#/components/schemas/User"))
+	api.Get("/owner").HasResponseModel(http.StatusOK, ModelOf[WithOwner]())
+	if _, err := api.SpecWith(Filter{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// FieldsParameter is the name of the query parameter added by HasFieldsParameter.
+const FieldsParameter = "fields"
+
+// HasFieldsParameter documents a fields query parameter, used to return a subset of the
+// properties of the response, e.g. /users?fields=id,name. The allowed values are the
+// property names of the model, so they stay in sync with it. If the model is a slice,
+// the properties of its elements are used.
+func (rm *Route) HasFieldsParameter(model Model) *Route {
+	rm.FieldsModel = &model
+	return rm
+}
+
+// addFieldsParameter adds the fields query parameter to the operation.
+func (api *API) addFieldsParameter(op *openapi3.Operation, model Model) error {
+	properties, err := api.getPropertyNames(model)
+	if err != nil {
+		return fmt.Errorf("failed to get the fields of %v: %w", model.Type, err)
+	}
+	op.AddParameter(newListQueryParameter(FieldsParameter,
+		"Comma separated list of the fields to return. If empty, all fields are returned.",
+		properties))
+	return nil
+}
+
+// getPropertyNames returns the sorted property names of the model's schema, or of the
+// schema of its items if it's an array.
+func (api *API) getPropertyNames(model Model) ([]string, error) {
+	name, schema, err := api.RegisterModel(model)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Type.Is(openapi3.TypeArray) && schema.Items != nil {
+		items := schema.Items
+		schema = items.Value
+		if schema == nil {
+			name = getComponentName(items.Ref)
+			schema = api.models[name]
+		}
+	}
+	if schema == nil || !schema.Type.Is(openapi3.TypeObject) || len(schema.Properties) == 0 {
+		return nil, fmt.Errorf("schema %q has no properties", name)
+	}
+	return getSortedKeys(schema.Properties), nil
+}
+
+// newListQueryParameter creates a query parameter that contains a comma separated list of
+// the allowed values.
+func newListQueryParameter(name, description string, values []string) *openapi3.Parameter {
+	items := openapi3.NewStringSchema()
+	for _, v := range values {
+		items.Enum = append(items.Enum, v)
+	}
+	explode := false
+	p := openapi3.NewQueryParameter(name).
+		WithDescription(description).
+		WithSchema(openapi3.NewArraySchema().WithItems(items))
+	p.Style = openapi3.SerializationForm
+	p.Explode = &explode
+	return p
+}
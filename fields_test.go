@@ -0,0 +1,23 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFieldsParameterRequiresProperties(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/names").
+		HasResponseModel(http.StatusOK, ModelOf[[]string]()).
+		HasResponseDescription(http.StatusOK, "The names.").
+		HasFieldsParameter(ModelOf[[]string]())
+
+	_, err := api.Spec()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), "has no properties") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
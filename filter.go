@@ -0,0 +1,248 @@
+package rest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Filter selects the parts of the API that are included in a spec.
+type Filter struct {
+	// Tags limits the spec to operations that have at least one of the tags.
+	// If empty, operations are not filtered by tag.
+	Tags []string
+	// ExcludeExtensions removes operations, parameters and schema properties
+	// that have any of the extensions set, e.g. "x-internal".
+	ExcludeExtensions []string
+}
+
+// SpecWith creates an OpenAPI specification that only contains the parts of the API
+// that match the filter. Component schemas that are no longer used are removed.
+// The returned spec is a copy, so the same API can produce multiple variants, e.g.
+// for public and internal documentation.
+func (api *API) SpecWith(f Filter) (spec *openapi3.T, err error) {
+	spec, err = api.Spec()
+	if err != nil {
+		return nil, err
+	}
+	spec, err = api.cloneSpec(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy spec: %w", err)
+	}
+	f.apply(spec)
+	pruneUnusedSchemas(spec)
+	return spec, nil
+}
+
+func (api *API) cloneSpec(spec *openapi3.T) (*openapi3.T, error) {
+	data, err := spec.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	loader := openapi3.NewLoader()
+	if len(api.externalRefs) > 0 {
+		api.allowExternalRefs(loader)
+	}
+	return loader.LoadFromData(data)
+}
+
+func (f Filter) apply(spec *openapi3.T) {
+	seen := make(map[*openapi3.Schema]bool)
+	for _, path := range spec.Paths.InMatchingOrder() {
+		pathItem := spec.Paths.Value(path)
+		pathItem.Parameters = f.filterParameters(pathItem.Parameters)
+		for method, op := range pathItem.Operations() {
+			if !f.includesOperation(op) {
+				pathItem.SetOperation(method, nil)
+				continue
+			}
+			op.Parameters = f.filterParameters(op.Parameters)
+		}
+		if len(pathItem.Operations()) == 0 {
+			spec.Paths.Delete(path)
+		}
+	}
+	if spec.Components != nil {
+		for _, schema := range spec.Components.Schemas {
+			f.filterProperties(schema, seen)
+		}
+	}
+}
+
+func (f Filter) includesOperation(op *openapi3.Operation) bool {
+	if f.isExcluded(op.Extensions) {
+		return false
+	}
+	if len(f.Tags) == 0 {
+		return true
+	}
+	for _, tag := range op.Tags {
+		if slices.Contains(f.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) isExcluded(extensions map[string]any) bool {
+	for _, ext := range f.ExcludeExtensions {
+		if _, ok := extensions[ext]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) filterParameters(params openapi3.Parameters) (filtered openapi3.Parameters) {
+	for _, p := range params {
+		if p.Value != nil && f.isExcluded(p.Value.Extensions) {
+			continue
+		}
+		filtered = append(filtered, p)
+	}
+	return filtered
+}
+
+func (f Filter) filterProperties(ref *openapi3.SchemaRef, seen map[*openapi3.Schema]bool) {
+	if ref == nil || ref.Value == nil || seen[ref.Value] {
+		return
+	}
+	s := ref.Value
+	seen[s] = true
+	for name, prop := range s.Properties {
+		if prop.Value != nil && f.isExcluded(prop.Value.Extensions) {
+			delete(s.Properties, name)
+			s.Required = slices.DeleteFunc(s.Required, func(r string) bool { return r == name })
+			continue
+		}
+		f.filterProperties(prop, seen)
+	}
+	for _, child := range getChildSchemas(s) {
+		f.filterProperties(child, seen)
+	}
+}
+
+// getChildSchemas returns the schemas nested in s, other than its properties.
+func getChildSchemas(s *openapi3.Schema) (children []*openapi3.SchemaRef) {
+	children = append(children, s.Items, s.Not, s.AdditionalProperties.Schema)
+	children = append(children, s.AllOf...)
+	children = append(children, s.AnyOf...)
+	children = append(children, s.OneOf...)
+	return children
+}
+
+// WithPruneUnusedSchemas removes component schemas that aren't used by any route
+// from the specification, e.g. models registered with RegisterModel that are no
+// longer needed.
+func WithPruneUnusedSchemas() APIOpts {
+	return func(api *API) {
+		api.PruneSchemas = true
+	}
+}
+
+// PruneUnusedSchemas removes registered models that aren't used by any route.
+func (api *API) PruneUnusedSchemas() error {
+	spec, err := api.createOpenAPI()
+	if err != nil {
+		return err
+	}
+	used := getUsedSchemas(spec)
+	for name := range api.models {
+		if !used[name] {
+			delete(api.models, name)
+			delete(api.modelTypes, name)
+		}
+	}
+	return nil
+}
+
+// pruneUnusedSchemas removes component schemas that aren't referenced by any operation.
+func pruneUnusedSchemas(spec *openapi3.T) {
+	if spec.Components == nil {
+		return
+	}
+	used := getUsedSchemas(spec)
+	for name := range spec.Components.Schemas {
+		if !used[name] {
+			delete(spec.Components.Schemas, name)
+		}
+	}
+}
+
+// getUsedSchemas returns the names of the component schemas reachable from the operations
+// of the spec, or from its catalog of error codes.
+func getUsedSchemas(spec *openapi3.T) map[string]bool {
+	w := schemaWalker{
+		components: spec.Components.Schemas,
+		used:       make(map[string]bool),
+		seen:       make(map[*openapi3.Schema]bool),
+	}
+	for _, pathItem := range spec.Paths.Map() {
+		w.walkParameters(pathItem.Parameters)
+		for _, op := range pathItem.Operations() {
+			w.walkParameters(op.Parameters)
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				w.walkContent(op.RequestBody.Value.Content)
+			}
+			if op.Responses == nil {
+				continue
+			}
+			for _, response := range op.Responses.Map() {
+				if response.Value != nil {
+					w.walkContent(response.Value.Content)
+				}
+			}
+		}
+	}
+	for _, entry := range getErrorCatalog(spec) {
+		w.walk(entry.Schema)
+	}
+	return w.used
+}
+
+type schemaWalker struct {
+	components openapi3.Schemas
+	used       map[string]bool
+	seen       map[*openapi3.Schema]bool
+}
+
+func (w schemaWalker) walkParameters(params openapi3.Parameters) {
+	for _, p := range params {
+		if p.Value != nil {
+			w.walk(p.Value.Schema)
+			w.walkContent(p.Value.Content)
+		}
+	}
+}
+
+func (w schemaWalker) walkContent(content openapi3.Content) {
+	for _, mt := range content {
+		w.walk(mt.Schema)
+	}
+}
+
+func (w schemaWalker) walk(ref *openapi3.SchemaRef) {
+	if ref == nil {
+		return
+	}
+	if name, ok := strings.CutPrefix(ref.Ref, "#/components/schemas/"); ok {
+		if w.used[name] {
+			return
+		}
+		w.used[name] = true
+		w.walk(w.components[name])
+		return
+	}
+	if ref.Value == nil || w.seen[ref.Value] {
+		return
+	}
+	w.seen[ref.Value] = true
+	for _, prop := range ref.Value.Properties {
+		w.walk(prop)
+	}
+	for _, child := range getChildSchemas(ref.Value) {
+		w.walk(child)
+	}
+}
@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+type PartnerAccount struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+func (PartnerAccount) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Properties["secret"].Value.Extensions = map[string]any{"x-internal": true}
+}
+
+func TestSpecWith(t *testing.T) {
+	api := NewAPI("test")
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+	api.Get("/accounts").
+		HasTags([]string{"public"}).
+		HasResponseModel(http.StatusOK, ModelOf[PartnerAccount]())
+	api.Get("/users").
+		HasTags([]string{"internal"}).
+		HasResponseModel(http.StatusOK, ModelOf[User]())
+
+	spec, err := api.SpecWith(Filter{Tags: []string{"public"}, ExcludeExtensions: []string{"x-internal"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"/accounts"}, spec.Paths.InMatchingOrder()); diff != "" {
+		t.Errorf("unexpected paths: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"PartnerAccount"}, getSortedKeys(spec.Components.Schemas)); diff != "" {
+		t.Errorf("unexpected schemas: %s", diff)
+	}
+	account := spec.Components.Schemas["PartnerAccount"].Value
+	if diff := cmp.Diff([]string{"id"}, getSortedKeys(account.Properties)); diff != "" {
+		t.Errorf("unexpected properties: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"id"}, account.Required); diff != "" {
+		t.Errorf("unexpected required properties: %s", diff)
+	}
+
+	// The unfiltered spec is not affected.
+	full, err := api.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"PartnerAccount", "User"}, getSortedKeys(full.Components.Schemas)); diff != "" {
+		t.Errorf("unexpected schemas in full spec: %s", diff)
+	}
+	if _, ok := full.Components.Schemas["PartnerAccount"].Value.Properties["secret"]; !ok {
+		t.Error("expected the full spec to include the secret property")
+	}
+}
+
+func TestPruneUnusedSchemas(t *testing.T) {
+	api := NewAPI("test")
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+	api.Get("/user").HasResponseModel(http.StatusOK, ModelOf[User]())
+	api.RegisterModel(ModelOf[OK]())
+
+	if err := api.PruneUnusedSchemas(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"User"}, getSortedKeys(api.models)); diff != "" {
+		t.Errorf("unexpected models: %s", diff)
+	}
+}
+
+func TestWithPruneUnusedSchemas(t *testing.T) {
+	api := NewAPI("test", WithPruneUnusedSchemas())
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+	api.Get("/tree").HasResponseModel(http.StatusOK, ModelOf[TreeNode]())
+	api.RegisterModel(ModelOf[OK]())
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"TreeNode"}, getSortedKeys(spec.Components.Schemas)); diff != "" {
+		t.Errorf("unexpected schemas: %s", diff)
+	}
+}
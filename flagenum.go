@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"fmt"
+	"math/bits"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/heimspiel/rest/enums"
+	"golang.org/x/exp/constraints"
+)
+
+// FlagEnumFormat sets how a bitmask of flags is represented in the specification.
+type FlagEnumFormat int
+
+const (
+	// FlagEnumInteger represents the flags as an integer.
+	FlagEnumInteger FlagEnumFormat = iota
+	// FlagEnumArray represents the flags as an array of the names of the flags that are
+	// set, for types that marshal themselves that way.
+	FlagEnumArray
+)
+
+// flagValuesExtension maps the name of each flag to its value, whichever format is used.
+const flagValuesExtension = "x-flag-values"
+
+// WithFlagEnum sets the property to be a bitmask of the constants of the type found in
+// the package. Only constants with a single bit set are flags, so combinations such as
+// an "all" constant are left out. The integer format has a maximum of all the flags set.
+// If the constants can't be found, an error is returned.
+//
+//	api.OverrideModel(rest.ModelOf[Permission](), rest.WithFlagEnum[Permission](rest.FlagEnumInteger))
+func WithFlagEnum[T constraints.Integer](f FlagEnumFormat) ModelOptsErr {
+	return func(_ *API, s *openapi3.Schema) error {
+		var t T
+		constants, err := enums.GetConstants(reflect.TypeOf(t))
+		if err != nil {
+			return fmt.Errorf("failed to get the flags of %v: %w", reflect.TypeOf(t), err)
+		}
+		var flags []enums.Constant
+		for _, c := range constants {
+			if isFlag(c.Value) {
+				flags = append(flags, c)
+			}
+		}
+		s.Enum = nil
+		values := make(map[string]any, len(flags))
+		for _, c := range flags {
+			values[c.Name] = c.Value
+		}
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions[flagValuesExtension] = values
+		if f == FlagEnumArray {
+			items := openapi3.NewStringSchema()
+			for _, c := range flags {
+				items.Enum = append(items.Enum, c.Name)
+			}
+			s.Type = &openapi3.Types{openapi3.TypeArray}
+			s.Format = ""
+			s.Items = openapi3.NewSchemaRef("", items)
+			s.UniqueItems = true
+			return nil
+		}
+		s.Type = &openapi3.Types{openapi3.TypeInteger}
+		s.WithMin(0)
+		s.WithMax(float64(getFlagMask(flags)))
+		return nil
+	}
+}
+
+// getFlagMask returns the value with all the flags set.
+func getFlagMask(flags []enums.Constant) (mask uint64) {
+	for _, c := range flags {
+		switch v := c.Value.(type) {
+		case int:
+			mask |= uint64(v)
+		case uint64:
+			mask |= v
+		}
+	}
+	return mask
+}
+
+// isFlag returns true if the value has exactly one bit set.
+func isFlag(v any) bool {
+	switch v := v.(type) {
+	case int:
+		return v > 0 && bits.OnesCount64(uint64(v)) == 1
+	case uint64:
+		return bits.OnesCount64(v) == 1
+	}
+	return false
+}
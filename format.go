@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// WithFormatInference sets the format of string fields from their names and types, for
+// fields that don't have a format:
+//
+//	Email, ContactEmail: email
+//	URL, AvatarURL, URI: uri
+//	UUID, RequestUUID: uuid
+//	CreatedAt, UpdatedAt: date-time
+//
+// Fields of types named UUID that are strings or [16]byte arrays, e.g. uuid.UUID,
+// become strings with the uuid format. Use the format struct tag to override the
+// format of a field, e.g. `format:"hostname"`, or `format:"-"` to leave it unset.
+func WithFormatInference() APIOpts {
+	return func(api *API) {
+		api.FormatInference = true
+	}
+}
+
+// fieldNameFormats are the formats inferred from the suffixes of field names.
+var fieldNameFormats = []struct {
+	suffix string
+	format string
+}{
+	{"Email", "email"},
+	{"URL", "uri"},
+	{"Url", "uri"},
+	{"URI", "uri"},
+	{"UUID", "uuid"},
+	{"At", "date-time"},
+}
+
+// inferFormat returns the schema of the field with the format inferred from its name and type.
+func inferFormat(f reflect.StructField, ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+	if _, ok := f.Tag.Lookup("format"); ok {
+		return ref
+	}
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isUUIDType(t) {
+		s := openapi3.NewUUIDSchema()
+		s.Nullable = f.Type.Kind() == reflect.Pointer
+		return openapi3.NewSchemaRef("", s)
+	}
+	if ref.Value == nil || !ref.Value.Type.Is(openapi3.TypeString) || ref.Value.Format != "" || len(ref.Value.Enum) > 0 {
+		return ref
+	}
+	for _, nf := range fieldNameFormats {
+		// A suffix of At on its own isn't a timestamp.
+		if (f.Name == nf.suffix && nf.suffix != "At") || (len(f.Name) > len(nf.suffix) && strings.HasSuffix(f.Name, nf.suffix)) {
+			ref.Value.Format = nf.format
+			break
+		}
+	}
+	return ref
+}
+
+// isUUIDType returns true for types named UUID that are stored as strings or 16 bytes.
+func isUUIDType(t reflect.Type) bool {
+	if t.Name() != "UUID" {
+		return false
+	}
+	return t.Kind() == reflect.String ||
+		(t.Kind() == reflect.Array && t.Len() == 16 && t.Elem().Kind() == reflect.Uint8)
+}
+
+// applyFormatTag sets the format of a field from its format tag, if it has one.
+// A value of - removes the format.
+func applyFormatTag(f reflect.StructField, s *openapi3.Schema) {
+	format, ok := f.Tag.Lookup("format")
+	if !ok {
+		return
+	}
+	if format == "-" {
+		format = ""
+	}
+	s.Format = format
+}
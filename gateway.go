@@ -0,0 +1,132 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// GatewayProfile adds the extensions that an API gateway requires to the specification,
+// e.g. the address of the backend. Profiles are applied by Spec, in the order that they
+// were added, see WithGatewayProfile.
+type GatewayProfile interface {
+	Apply(api *API, spec *openapi3.T) error
+}
+
+// WithGatewayProfile adds a GatewayProfile to the API.
+func WithGatewayProfile(p GatewayProfile) APIOpts {
+	return func(api *API) {
+		api.GatewayProfiles = append(api.GatewayProfiles, p)
+	}
+}
+
+// applyGatewayProfiles applies the API's gateway profiles to the spec.
+func (api *API) applyGatewayProfiles(spec *openapi3.T) error {
+	for _, p := range api.GatewayProfiles {
+		if err := p.Apply(api, spec); err != nil {
+			return fmt.Errorf("gateway profile %T: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// forEachOperation calls f with each route of the API, and its operation in the spec.
+func (api *API) forEachOperation(spec *openapi3.T, f func(route *Route, op *openapi3.Operation)) {
+	for _, pattern := range getSortedKeys(api.Routes) {
+		pathItem := spec.Paths.Value(getPath(pattern))
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range getSortedMethods(api.Routes[pattern]) {
+			if op := pathItem.GetOperation(string(method)); op != nil {
+				f(api.Routes[pattern][method], op)
+			}
+		}
+	}
+}
+
+// AWSGatewayProfile adds an Amazon API Gateway integration to every operation that
+// doesn't have one set by Route.HasAWSIntegration.
+type AWSGatewayProfile struct {
+	Integration AWSIntegration
+}
+
+func (p AWSGatewayProfile) Apply(api *API, spec *openapi3.T) error {
+	api.forEachOperation(spec, func(route *Route, op *openapi3.Operation) {
+		if _, ok := op.Extensions[awsIntegrationExtension]; ok {
+			return
+		}
+		if op.Extensions == nil {
+			op.Extensions = make(map[string]any)
+		}
+		op.Extensions[awsIntegrationExtension] = p.Integration.awsIntegration(route)
+	})
+	return nil
+}
+
+// GoogleEndpointsProfile configures the API for Google Cloud Endpoints and API Gateway,
+// which route requests to the backend set in the x-google-backend extension.
+// Every operation must have an OperationID.
+type GoogleEndpointsProfile struct {
+	// BackendAddress is the URL of the backend, e.g. https://users-abc123.a.run.app.
+	BackendAddress string
+	// PathTranslation sets how the path of the request is sent to the backend,
+	// either APPEND_PATH_TO_ADDRESS or CONSTANT_ADDRESS. Defaults to APPEND_PATH_TO_ADDRESS.
+	PathTranslation string
+	// Deadline is the number of seconds to wait for a response from the backend.
+	// If zero, the gateway's default is used.
+	Deadline float64
+	// JWTAudience is the audience of the token used to authenticate to the backend.
+	// If empty, the gateway's default is used.
+	JWTAudience string
+}
+
+func (p GoogleEndpointsProfile) Apply(api *API, spec *openapi3.T) error {
+	var missing []string
+	api.forEachOperation(spec, func(route *Route, op *openapi3.Operation) {
+		if op.OperationID == "" {
+			missing = append(missing, getOperation(route))
+		}
+	})
+	if len(missing) > 0 {
+		return fmt.Errorf("operations must have an operation ID: %s", strings.Join(missing, ", "))
+	}
+	pathTranslation := p.PathTranslation
+	if pathTranslation == "" {
+		pathTranslation = "APPEND_PATH_TO_ADDRESS"
+	}
+	backend := map[string]any{
+		"address":          p.BackendAddress,
+		"path_translation": pathTranslation,
+	}
+	if p.Deadline > 0 {
+		backend["deadline"] = p.Deadline
+	}
+	if p.JWTAudience != "" {
+		backend["jwt_audience"] = p.JWTAudience
+	}
+	if spec.Extensions == nil {
+		spec.Extensions = make(map[string]any)
+	}
+	spec.Extensions["x-google-backend"] = backend
+	return nil
+}
+
+// AzureAPIManagementProfile configures the API for import into Azure API Management,
+// which uses the first server of the specification as the address of the backend.
+//
+// API Management doesn't read policies from the specification, so they must be
+// configured separately, e.g. in the template used to deploy the API.
+type AzureAPIManagementProfile struct {
+	// BackendURL is the URL of the backend, e.g. https://users.azurewebsites.net.
+	BackendURL string
+}
+
+func (p AzureAPIManagementProfile) Apply(api *API, spec *openapi3.T) error {
+	if p.BackendURL == "" {
+		return fmt.Errorf("backend URL is required")
+	}
+	spec.Servers = append(openapi3.Servers{{URL: p.BackendURL}}, spec.Servers...)
+	return nil
+}
@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGatewayProfiles(t *testing.T) {
+	api := NewAPI("test",
+		WithGatewayProfile(AWSGatewayProfile{
+			Integration: HTTPProxyIntegration{URI: "https://default.example.com"},
+		}),
+		WithGatewayProfile(GoogleEndpointsProfile{
+			BackendAddress: "https://users-abc123.a.run.app",
+			Deadline:       10,
+		}),
+		WithGatewayProfile(AzureAPIManagementProfile{
+			BackendURL: "https://users.azurewebsites.net",
+		}),
+	)
+	api.Get("/users").
+		HasOperationID("listUsers").
+		HasResponseModel(http.StatusOK, ModelOf[[]User]())
+	api.Get("/users/{id}").
+		HasOperationID("getUser").
+		HasResponseModel(http.StatusOK, ModelOf[User]()).
+		HasAWSIntegration(HTTPProxyIntegration{URI: "https://users.example.com/users/{id}"})
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+
+	uri := func(path string) any {
+		return spec.Paths.Value(path).Get.Extensions["x-amazon-apigateway-integration"].(map[string]any)["uri"]
+	}
+	if actual := uri("/users"); actual != "https://default.example.com" {
+		t.Errorf("expected the default integration, got %v", actual)
+	}
+	if actual := uri("/users/{id}"); actual != "https://users.example.com/users/{id}" {
+		t.Errorf("expected the route's integration to be kept, got %v", actual)
+	}
+
+	expectedBackend := map[string]any{
+		"address":          "https://users-abc123.a.run.app",
+		"path_translation": "APPEND_PATH_TO_ADDRESS",
+		"deadline":         float64(10),
+	}
+	if diff := cmp.Diff(expectedBackend, spec.Extensions["x-google-backend"]); diff != "" {
+		t.Error(diff)
+	}
+
+	if len(spec.Servers) == 0 || spec.Servers[0].URL != "https://users.azurewebsites.net" {
+		t.Errorf("expected the backend URL to be the first server, got %v", spec.Servers)
+	}
+}
+
+func TestGoogleEndpointsProfileRequiresOperationIDs(t *testing.T) {
+	api := NewAPI("test", WithGatewayProfile(GoogleEndpointsProfile{BackendAddress: "https://example.com"}))
+	api.Get("/users").HasResponseModel(http.StatusOK, ModelOf[[]User]())
+	if _, err := api.Spec(); err == nil {
+		t.Error("expected an error for an operation without an operation ID")
+	}
+}
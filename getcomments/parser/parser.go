@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/types"
+	"path/filepath"
 	"strings"
 
 	"golang.org/x/tools/go/packages"
@@ -31,6 +32,14 @@ func Get(packageName string) (m map[string]string, err error) {
 }
 
 func processFile(packageName string, pkg *packages.Package, file *ast.File, m map[string]string) {
+	// Get the package comment, which is stored with the package name as the key.
+	// By convention, it's in doc.go, which is preferred if other files have one.
+	if doc := strings.TrimSpace(file.Doc.Text()); doc != "" {
+		fileName := filepath.Base(pkg.Fset.File(file.Pos()).Name())
+		if _, exists := m[packageName]; (!exists || fileName == "doc.go") && !strings.HasSuffix(fileName, "_test.go") {
+			m[packageName] = doc
+		}
+	}
 	var lastComment string
 	var typ string
 	ast.Inspect(file, func(n ast.Node) bool {
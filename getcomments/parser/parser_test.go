@@ -12,6 +12,7 @@ import (
 	"github.com/heimspiel/rest/getcomments/parser/tests/enum"
 	"github.com/heimspiel/rest/getcomments/parser/tests/functions"
 	"github.com/heimspiel/rest/getcomments/parser/tests/functiontypes"
+	"github.com/heimspiel/rest/getcomments/parser/tests/packagedoc"
 	"github.com/heimspiel/rest/getcomments/parser/tests/pointers"
 	"github.com/heimspiel/rest/getcomments/parser/tests/privatetypes"
 	"github.com/heimspiel/rest/getcomments/parser/tests/publictypes"
@@ -68,6 +69,11 @@ func TestGet(t *testing.T) {
 			pkg:      "github.com/heimspiel/rest/getcomments/parser/tests/docs",
 			expected: docs.Expected,
 		},
+		{
+			name:     "package comments are extracted, preferring doc.go",
+			pkg:      "github.com/heimspiel/rest/getcomments/parser/tests/packagedoc",
+			expected: packagedoc.Expected,
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,4 @@
+// Package packagedoc manages discussion topics.
+//
+// Topics contain posts.
+package packagedoc
@@ -0,0 +1,13 @@
+// This comment isn't in doc.go, so it isn't the package comment.
+package packagedoc
+
+import _ "embed"
+
+//go:embed snapshot.json
+var Expected string
+
+// Topic is a discussion topic.
+type Topic struct {
+	// Name of the topic.
+	Name string
+}
@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// uniqueExtension marks properties whose values are unique, e.g. because of a unique
+// index in the database.
+const uniqueExtension = "x-unique"
+
+// WithGormTagMapping adds the database constraints in the gorm tags of struct fields to
+// their schemas, for models that are also used with GORM, e.g.
+//
+//	Email string `json:"email" gorm:"size:255;not null;uniqueIndex"`
+//
+//	size:N, type:varchar(N): maxLength N
+//	type:char(N): minLength and maxLength N
+//	not null: required, and not nullable
+//	unique, uniqueIndex, primaryKey: x-unique: true
+//
+// Lengths are only added to strings. The db tags of sqlx and sqlc hold column names,
+// not constraints, so they're ignored.
+func WithGormTagMapping() APIOpts {
+	return func(api *API) {
+		api.GormTagMapping = true
+	}
+}
+
+// dbConstraints are the constraints of a field in the database.
+type dbConstraints struct {
+	minLength, maxLength *uint64
+	notNull              bool
+	unique               bool
+}
+
+// charTypePattern matches the char and varchar column types of the gorm type setting.
+var charTypePattern = regexp.MustCompile(`^(?i)(var)?char\((\d+)\)$`)
+
+// parseDBConstraints returns the constraints in the gorm tag of the field. Unknown
+// settings are ignored.
+func parseDBConstraints(f reflect.StructField) (c dbConstraints, err error) {
+	for _, setting := range strings.Split(f.Tag.Get("gorm"), ";") {
+		key, value, _ := strings.Cut(setting, ":")
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "SIZE":
+			size, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return c, fmt.Errorf("invalid size %q in gorm tag: %w", value, err)
+			}
+			c.maxLength = &size
+		case "TYPE":
+			m := charTypePattern.FindStringSubmatch(strings.TrimSpace(value))
+			if m == nil {
+				continue
+			}
+			size, err := strconv.ParseUint(m[2], 10, 64)
+			if err != nil {
+				return c, fmt.Errorf("invalid type %q in gorm tag: %w", value, err)
+			}
+			c.maxLength = &size
+			if m[1] == "" {
+				c.minLength = &size
+			}
+		case "NOT NULL":
+			c.notNull = true
+		case "UNIQUE", "UNIQUEINDEX", "PRIMARYKEY", "PRIMARY_KEY":
+			c.unique = true
+		}
+	}
+	return c, nil
+}
+
+// apply adds the constraints to the schema of the field.
+func (c dbConstraints) apply(s *openapi3.Schema) {
+	if s.Type.Is(openapi3.TypeString) {
+		if c.minLength != nil {
+			s.MinLength = *c.minLength
+		}
+		if c.maxLength != nil {
+			s.MaxLength = c.maxLength
+		}
+	}
+	if c.notNull {
+		s.Nullable = false
+	}
+	if c.unique {
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions[uniqueExtension] = true
+	}
+}
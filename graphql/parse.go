@@ -0,0 +1,304 @@
+package graphql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// typeRef is a reference to a type in a field definition, e.g. [String!]!
+type typeRef struct {
+	Name    string
+	NonNull bool
+	// Elem is the type of the elements of a list.
+	Elem *typeRef
+}
+
+// Parse reads the object, input, interface and enum types from a GraphQL schema
+// definition, e.g. the schema.graphqls file used by gqlgen. Other definitions are
+// skipped.
+func Parse(sdl string) (s *Schema, err error) {
+	tokens, err := tokenize(sdl)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	s = &Schema{
+		types: make(map[string]map[string]typeRef),
+		enums: make(map[string][]string),
+	}
+	for !p.done() {
+		if err = p.parseDefinition(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+type token struct {
+	value    string
+	isString bool
+}
+
+func tokenize(sdl string) (tokens []token, err error) {
+	for i := 0; i < len(sdl); {
+		c := rune(sdl[i])
+		switch {
+		case unicode.IsSpace(c) || c == ',':
+			i++
+		case c == '#':
+			for i < len(sdl) && sdl[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(sdl[i:], `"""`):
+			end := strings.Index(sdl[i+3:], `"""`)
+			if end < 0 {
+				return nil, fmt.Errorf("graphql: unterminated block string")
+			}
+			tokens = append(tokens, token{value: sdl[i+3 : i+3+end], isString: true})
+			i += end + 6
+		case c == '"':
+			j := i + 1
+			for j < len(sdl) && sdl[j] != '"' {
+				if sdl[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(sdl) {
+				return nil, fmt.Errorf("graphql: unterminated string")
+			}
+			tokens = append(tokens, token{value: sdl[i+1 : j], isString: true})
+			i = j + 1
+		case strings.ContainsRune("{}()[]:!=@&|", c):
+			tokens = append(tokens, token{value: string(c)})
+			i++
+		default:
+			j := i
+			for j < len(sdl) && (sdl[j] == '_' || sdl[j] == '-' || sdl[j] == '.' || unicode.IsLetter(rune(sdl[j])) || unicode.IsDigit(rune(sdl[j]))) {
+				j++
+			}
+			if j == i {
+				return nil, fmt.Errorf("graphql: unexpected character %q", c)
+			}
+			tokens = append(tokens, token{value: sdl[i:j]})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos].value
+}
+
+func (p *parser) peekIsString() bool {
+	return !p.done() && p.tokens[p.pos].isString
+}
+
+func (p *parser) next() string {
+	v := p.peek()
+	p.pos++
+	return v
+}
+
+func (p *parser) expect(value string) error {
+	if actual := p.next(); actual != value {
+		return fmt.Errorf("graphql: expected %q, got %q", value, actual)
+	}
+	return nil
+}
+
+// skipBalanced skips a bracketed group, e.g. (a: 1, b: [2]), if there is one.
+func (p *parser) skipBalanced(open, close string) {
+	if p.peek() != open || p.peekIsString() {
+		return
+	}
+	var depth int
+	for !p.done() {
+		t := p.tokens[p.pos]
+		p.pos++
+		if t.isString {
+			continue
+		}
+		switch t.value {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return
+			}
+		}
+	}
+}
+
+func (p *parser) skipDescription() {
+	for p.peekIsString() {
+		p.pos++
+	}
+}
+
+func (p *parser) skipDirectives() {
+	for p.peek() == "@" && !p.peekIsString() {
+		p.next()
+		p.next()
+		p.skipBalanced("(", ")")
+	}
+}
+
+// skipValue skips a default value, e.g. = [1, 2].
+func (p *parser) skipValue() {
+	switch p.peek() {
+	case "[":
+		p.skipBalanced("[", "]")
+	case "{":
+		p.skipBalanced("{", "}")
+	default:
+		p.next()
+	}
+}
+
+func (p *parser) parseDefinition(s *Schema) (err error) {
+	p.skipDescription()
+	keyword := p.next()
+	if keyword == "extend" {
+		keyword = p.next()
+	}
+	switch keyword {
+	case "type", "input", "interface":
+		name := p.next()
+		for !p.done() && p.peek() != "{" {
+			if p.peek() == "@" {
+				p.skipDirectives()
+				continue
+			}
+			p.next()
+		}
+		fields := s.types[name]
+		if fields == nil {
+			fields = make(map[string]typeRef)
+			s.types[name] = fields
+		}
+		return p.parseFields(fields)
+	case "enum":
+		name := p.next()
+		p.skipDirectives()
+		if p.peek() != "{" {
+			return nil
+		}
+		p.next()
+		for !p.done() && p.peek() != "}" {
+			p.skipDescription()
+			s.enums[name] = append(s.enums[name], p.next())
+			p.skipDirectives()
+		}
+		return p.expect("}")
+	case "scalar":
+		p.next()
+		p.skipDirectives()
+	case "union":
+		p.next()
+		p.skipDirectives()
+		if p.peek() == "=" {
+			p.next()
+			if p.peek() == "|" {
+				p.next()
+			}
+			p.next()
+			for p.peek() == "|" {
+				p.next()
+				p.next()
+			}
+		}
+	case "schema":
+		p.skipDirectives()
+		p.skipBalanced("{", "}")
+	case "directive":
+		if err = p.expect("@"); err != nil {
+			return err
+		}
+		p.next()
+		p.skipBalanced("(", ")")
+		if p.peek() == "repeatable" {
+			p.next()
+		}
+		if err = p.expect("on"); err != nil {
+			return err
+		}
+		if p.peek() == "|" {
+			p.next()
+		}
+		p.next()
+		for p.peek() == "|" {
+			p.next()
+			p.next()
+		}
+	default:
+		return fmt.Errorf("graphql: unexpected %q", keyword)
+	}
+	return nil
+}
+
+func (p *parser) parseFields(fields map[string]typeRef) (err error) {
+	if p.peek() != "{" {
+		// Types can be declared without fields, e.g. extend type Query @key(fields: "id").
+		return nil
+	}
+	p.next()
+	for !p.done() && p.peek() != "}" {
+		p.skipDescription()
+		name := p.next()
+		p.skipBalanced("(", ")")
+		if err = p.expect(":"); err != nil {
+			return fmt.Errorf("%w in field %q", err, name)
+		}
+		t, err := p.parseTypeRef()
+		if err != nil {
+			return fmt.Errorf("%w in field %q", err, name)
+		}
+		if p.peek() == "=" {
+			p.next()
+			p.skipValue()
+		}
+		p.skipDirectives()
+		fields[name] = t
+	}
+	return p.expect("}")
+}
+
+func (p *parser) parseTypeRef() (t typeRef, err error) {
+	if p.peek() == "[" {
+		p.next()
+		elem, err := p.parseTypeRef()
+		if err != nil {
+			return t, err
+		}
+		if err = p.expect("]"); err != nil {
+			return t, err
+		}
+		t.Elem = &elem
+	} else {
+		t.Name = p.next()
+		if t.Name == "" {
+			return t, fmt.Errorf("graphql: expected a type")
+		}
+	}
+	if p.peek() == "!" {
+		p.next()
+		t.NonNull = true
+	}
+	return t, nil
+}
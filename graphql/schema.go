@@ -0,0 +1,159 @@
+// Package graphql reuses the models of a GraphQL API in the REST API, e.g. the
+// structs generated by gqlgen, by reading nullability and enum values from the
+// GraphQL schema, which can't be determined from the Go types alone.
+package graphql
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/heimspiel/rest"
+)
+
+// Schema is the subset of a GraphQL schema that describes models.
+type Schema struct {
+	// types maps from object type names to their fields.
+	types map[string]map[string]typeRef
+	// enums maps from enum type names to their values.
+	enums map[string][]string
+}
+
+// ModelOpts returns options that make the schema of a model match the GraphQL type
+// with the given name: non-null fields are required, other fields are nullable, and
+// fields of enum types have the enum's values. Properties are matched to fields by
+// their JSON names, which gqlgen sets to the GraphQL field names.
+//
+// Nullable fields that reference other schemas are wrapped in allOf, since referenced
+// schemas are shared. Use Register to also apply the GraphQL types of those fields to
+// the schemas they reference.
+func (s *Schema) ModelOpts(typeName string) (opts []rest.ModelOpts, err error) {
+	fields, ok := s.types[typeName]
+	if !ok {
+		return nil, fmt.Errorf("graphql: type %q not found", typeName)
+	}
+	return []rest.ModelOpts{s.applyFields(fields)}, nil
+}
+
+// applyFields returns options that set the required fields, and the nullability and enum
+// values of the properties of the schema.
+func (s *Schema) applyFields(fields map[string]typeRef) rest.ModelOpts {
+	return func(schema *openapi3.Schema) {
+		for name, ref := range schema.Properties {
+			field, ok := fields[name]
+			if !ok {
+				continue
+			}
+			if field.NonNull {
+				if !slices.Contains(schema.Required, name) {
+					schema.Required = append(schema.Required, name)
+				}
+			} else {
+				schema.Required = slices.DeleteFunc(schema.Required, func(r string) bool { return r == name })
+			}
+			schema.Properties[name] = s.apply(field, ref)
+		}
+		slices.Sort(schema.Required)
+	}
+}
+
+// apply sets the nullability and enum values of a property. Referenced schemas are
+// shared with other properties, so they're wrapped in allOf to make them nullable.
+func (s *Schema) apply(t typeRef, ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+	if ref == nil {
+		return nil
+	}
+	if ref.Ref != "" {
+		if t.NonNull {
+			return ref
+		}
+		return openapi3.NewSchemaRef("", &openapi3.Schema{
+			Nullable: true,
+			AllOf:    openapi3.SchemaRefs{ref},
+		})
+	}
+	if ref.Value == nil {
+		return ref
+	}
+	ref.Value.Nullable = !t.NonNull
+	if t.Elem != nil {
+		ref.Value.Items = s.apply(*t.Elem, ref.Value.Items)
+		return ref
+	}
+	if values, ok := s.enums[t.Name]; ok {
+		ref.Value.Enum = s.getEnum(values)
+	}
+	return ref
+}
+
+func (s *Schema) getEnum(values []string) (enum []any) {
+	for _, v := range values {
+		enum = append(enum, v)
+	}
+	return enum
+}
+
+// Register registers the model with the API, using the GraphQL type with the given
+// name to set its nullability and enum values. The Go types of fields that are GraphQL
+// objects or enums are customised wherever they're used, since their schemas may be
+// referenced.
+func (s *Schema) Register(api *rest.API, model rest.Model, typeName string) (name string, err error) {
+	opts, err := s.ModelOpts(typeName)
+	if err != nil {
+		return "", err
+	}
+	s.overrideFieldTypes(api, model.Type, typeName, map[string]bool{typeName: true})
+	name, _, err = api.RegisterModel(model, opts...)
+	return name, err
+}
+
+// overrideFieldTypes applies the GraphQL types of the fields of the struct to the Go
+// types of the fields, if they're named types of objects or enums.
+func (s *Schema) overrideFieldTypes(api *rest.API, t reflect.Type, typeName string, visited map[string]bool) {
+	t = getElemType(t)
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	fields := s.types[typeName]
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		field, ok := fields[name]
+		if !ok {
+			continue
+		}
+		for field.Elem != nil {
+			field = *field.Elem
+		}
+		ft := getElemType(f.Type)
+		// Built-in types, e.g. string, are used by other fields too.
+		if visited[field.Name] || ft.PkgPath() == "" {
+			continue
+		}
+		if fields, ok := s.types[field.Name]; ok && ft.Kind() == reflect.Struct {
+			visited[field.Name] = true
+			api.OverrideModel(rest.Model{Type: ft}, s.applyFields(fields))
+			s.overrideFieldTypes(api, ft, field.Name, visited)
+			continue
+		}
+		if values, ok := s.enums[field.Name]; ok {
+			visited[field.Name] = true
+			api.OverrideModel(rest.Model{Type: ft}, rest.ModelOpts(func(schema *openapi3.Schema) {
+				schema.Enum = s.getEnum(values)
+			}))
+		}
+	}
+}
+
+// getElemType returns the type of the elements of pointers, slices and arrays.
+func getElemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
+}
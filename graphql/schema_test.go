@@ -0,0 +1,149 @@
+package graphql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/graphql"
+)
+
+const sdl = `
+"""
+A user of the system.
+"""
+type User implements Node @key(fields: "id") {
+  id: ID!
+  # The name is optional.
+  name: String
+  role: Role!
+  tags: [String!]!
+  friends(first: Int = 10): [User]
+}
+
+input UserFilter {
+  role: Role = ADMIN
+}
+
+enum Role {
+  "Can do anything."
+  ADMIN
+  USER @deprecated(reason: "Use MEMBER")
+  MEMBER
+}
+
+scalar Time
+type Team {
+  name: String!
+  members: [User!]!
+  owner: User
+}
+
+union SearchResult = User | Post
+directive @key(fields: String!) repeatable on OBJECT | INTERFACE
+`
+
+type Role string
+
+type Team struct {
+	Name    string `json:"name"`
+	Members []User `json:"members"`
+	Owner   *User  `json:"owner,omitempty"`
+}
+
+// User is written in the style of gqlgen output.
+type User struct {
+	ID      string   `json:"id"`
+	Name    *string  `json:"name,omitempty"`
+	Role    Role     `json:"role"`
+	Tags    []string `json:"tags"`
+	Friends []*User  `json:"friends,omitempty"`
+}
+
+func TestRegister(t *testing.T) {
+	s, err := graphql.Parse(sdl)
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	api := rest.NewAPI("test")
+	name, err := s.Register(api, rest.ModelOf[User](), "User")
+	if err != nil {
+		t.Fatalf("failed to register model: %v", err)
+	}
+	models, err := api.Models()
+	if err != nil {
+		t.Fatalf("failed to get models: %v", err)
+	}
+	schema := models[name]
+
+	if diff := cmp.Diff([]string{"id", "role", "tags"}, schema.Required); diff != "" {
+		t.Errorf("unexpected required fields: %s", diff)
+	}
+	if !schema.Properties["name"].Value.Nullable {
+		t.Error("expected name to be nullable")
+	}
+	tags := schema.Properties["tags"].Value
+	if tags.Nullable || tags.Items.Value.Nullable {
+		t.Error("expected tags and its items to be non-null")
+	}
+	// Role is a named type, so its enum values make it a component.
+	role := models[strings.TrimPrefix(schema.Properties["role"].Ref, "#/components/schemas/")]
+	if role == nil {
+		t.Fatalf("expected role to reference a component, got %v", schema.Properties["role"])
+	}
+	if diff := cmp.Diff([]any{"ADMIN", "USER", "MEMBER"}, role.Enum); diff != "" {
+		t.Errorf("unexpected enum values: %s", diff)
+	}
+	if !schema.Properties["friends"].Value.Nullable {
+		t.Error("expected friends to be nullable")
+	}
+}
+
+func TestRegisterReferencedTypes(t *testing.T) {
+	s, err := graphql.Parse(sdl)
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	api := rest.NewAPI("test")
+	name, err := s.Register(api, rest.ModelOf[Team](), "Team")
+	if err != nil {
+		t.Fatalf("failed to register model: %v", err)
+	}
+	models, err := api.Models()
+	if err != nil {
+		t.Fatalf("failed to get models: %v", err)
+	}
+	team := models[name]
+
+	// The User schema is referenced, so the GraphQL type is applied to it.
+	members := team.Properties["members"].Value
+	if members.Nullable || members.Items.Ref == "" {
+		t.Fatalf("expected members to be a non-null list of references, got %v", members)
+	}
+	user := models[strings.TrimPrefix(members.Items.Ref, "#/components/schemas/")]
+	if diff := cmp.Diff([]string{"id", "role", "tags"}, user.Required); diff != "" {
+		t.Errorf("unexpected required fields of the referenced user: %s", diff)
+	}
+	// Nullable references are wrapped, since the referenced schema is shared.
+	owner := team.Properties["owner"].Value
+	if owner == nil || !owner.Nullable || len(owner.AllOf) != 1 {
+		t.Errorf("expected owner to be a nullable allOf, got %v", team.Properties["owner"])
+	}
+}
+
+func TestParseInvalidDirective(t *testing.T) {
+	if _, err := graphql.Parse(`directive key(fields: String!) on OBJECT`); err == nil {
+		t.Error("expected an error for a directive without @")
+	}
+}
+
+func TestModelOptsUnknownType(t *testing.T) {
+	s, err := graphql.Parse(sdl)
+	if err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+	if _, err := s.ModelOpts("Missing"); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+}
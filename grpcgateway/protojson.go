@@ -0,0 +1,262 @@
+package grpcgateway
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/heimspiel/rest"
+)
+
+// protoJSON collects the changes that make the schemas of messages match protojson,
+// rather than encoding/json, which the schemas are generated for.
+type protoJSON struct {
+	// known are the schemas of enums and well-known types, which replace the schemas of
+	// the Go types.
+	known map[reflect.Type]*openapi3.Schema
+	// messages maps from the types of messages to the changes to their fields.
+	messages map[reflect.Type][]protoField
+}
+
+// protoField is a field of a message whose property is changed.
+type protoField struct {
+	// name of the property, from the json tag set by protoc-gen-go, e.g. book_id.
+	name string
+	// jsonName is the name of the field in protojson, e.g. bookId.
+	jsonName string
+	// format is int64 or uint64 if the field is a 64-bit integer, which protojson
+	// encodes as a string.
+	format string
+}
+
+func newProtoJSON() *protoJSON {
+	return &protoJSON{
+		known:    make(map[reflect.Type]*openapi3.Schema),
+		messages: make(map[reflect.Type][]protoField),
+	}
+}
+
+// addMessage adds the message, and the messages and enums of its fields.
+func (p *protoJSON) addMessage(t reflect.Type) error {
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	if _, ok := p.known[t]; ok {
+		return nil
+	}
+	if _, ok := p.messages[t]; ok {
+		return nil
+	}
+	if s, ok := getWellKnownSchema(t); ok {
+		p.known[t] = s
+		return nil
+	}
+	p.messages[t] = nil
+	var fields []protoField
+	for _, f := range getFields(t) {
+		if oneof, ok := f.Tag.Lookup("protobuf_oneof"); ok {
+			return fmt.Errorf("grpcgateway: %v: oneof %q is not supported", t, oneof)
+		}
+		if _, ok := f.Tag.Lookup("protobuf"); !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		field := protoField{name: name, jsonName: getJSONName(f), format: getInt64Format(f.Type)}
+		if field.jsonName != field.name || field.format != "" {
+			fields = append(fields, field)
+		}
+		if isEnum(f) {
+			p.known[getElemType(f.Type)] = getEnumSchema(getElemType(f.Type))
+			continue
+		}
+		if err := p.addMessage(f.Type); err != nil {
+			return err
+		}
+	}
+	p.messages[t] = fields
+	return nil
+}
+
+// apply sets the schemas of the enums and well-known types, and overrides the schemas
+// of the messages.
+func (p *protoJSON) apply(api *rest.API) {
+	for t, s := range p.known {
+		api.KnownTypes[t] = *s
+		api.KnownTypes[reflect.PointerTo(t)] = *s.WithNullable()
+	}
+	for t, fields := range p.messages {
+		if len(fields) == 0 {
+			continue
+		}
+		api.OverrideModel(rest.Model{Type: t}, rest.ModelOpts(func(s *openapi3.Schema) {
+			for _, f := range fields {
+				setInt64Format(s.Properties[f.name], f.format)
+				if f.jsonName == f.name {
+					continue
+				}
+				if prop, ok := s.Properties[f.name]; ok {
+					delete(s.Properties, f.name)
+					s.Properties[f.jsonName] = prop
+				}
+				for i, r := range s.Required {
+					if r == f.name {
+						s.Required[i] = f.jsonName
+					}
+				}
+			}
+		}))
+	}
+}
+
+// getElemType returns the type of the elements of pointers and repeated fields.
+func getElemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
+
+// getInt64Format returns int64 or uint64 if the field is a 64-bit integer, or a repeated
+// field of them.
+func getInt64Format(t reflect.Type) string {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int64:
+		return "int64"
+	case reflect.Uint64:
+		return "uint64"
+	}
+	return ""
+}
+
+// setInt64Format makes the schema of a 64-bit integer, or the items of an array of them,
+// a string, as protojson encodes them.
+func setInt64Format(ref *openapi3.SchemaRef, format string) {
+	if format == "" || ref == nil || ref.Value == nil {
+		return
+	}
+	s := ref.Value
+	if s.Type.Is(openapi3.TypeArray) && s.Items != nil && s.Items.Value != nil {
+		s = s.Items.Value
+	}
+	s.Type = &openapi3.Types{openapi3.TypeString}
+	s.Format = format
+	s.Pattern = `^-?[0-9]+$`
+	if format == "uint64" {
+		s.Pattern = `^[0-9]+$`
+	}
+}
+
+// isEnum returns true if the field is a protobuf enum, from the tag set by protoc-gen-go,
+// e.g. `protobuf:"varint,1,opt,name=kind,proto3,enum=library.Kind"`.
+func isEnum(f reflect.StructField) bool {
+	for _, part := range strings.Split(f.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "enum=") {
+			return true
+		}
+	}
+	return false
+}
+
+// getEnumSchema returns the schema of an enum, which protojson encodes as the names of its
+// values. The names are read using the Descriptor method generated by protoc-gen-go,
+// without depending on protobuf.
+func getEnumSchema(t reflect.Type) *openapi3.Schema {
+	s := openapi3.NewStringSchema()
+	values := call(call(reflect.Zero(t), "Descriptor"), "Values")
+	n := call(values, "Len")
+	if !n.IsValid() || !n.CanInt() {
+		return s
+	}
+	for i := 0; i < int(n.Int()); i++ {
+		name := call(call(values, "Get", reflect.ValueOf(i)), "Name")
+		if !name.IsValid() || name.Kind() != reflect.String {
+			return openapi3.NewStringSchema()
+		}
+		s.Enum = append(s.Enum, name.String())
+	}
+	return s
+}
+
+// call calls the method of the value, and returns its result, or an invalid value if
+// the method doesn't exist.
+func call(v reflect.Value, method string, args ...reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+	v = v.MethodByName(method)
+	if !v.IsValid() || v.Type().NumIn() != len(args) || v.Type().NumOut() != 1 {
+		return reflect.Value{}
+	}
+	for i, arg := range args {
+		if !arg.Type().AssignableTo(v.Type().In(i)) {
+			return reflect.Value{}
+		}
+	}
+	result := v.Call(args)[0]
+	if result.Kind() == reflect.Interface {
+		result = result.Elem()
+	}
+	return result
+}
+
+// wellKnownTypesPkgPath is the path of the packages of the well-known types, e.g.
+// google.golang.org/protobuf/types/known/timestamppb.
+const wellKnownTypesPkgPath = "google.golang.org/protobuf/types/known/"
+
+// getWellKnownSchema returns the schema of the JSON form of a well-known type, e.g.
+// google.protobuf.Timestamp, which protojson encodes as an RFC 3339 string.
+func getWellKnownSchema(t reflect.Type) (s *openapi3.Schema, ok bool) {
+	pkg, ok := strings.CutPrefix(t.PkgPath(), wellKnownTypesPkgPath)
+	if !ok {
+		return nil, false
+	}
+	switch pkg + "." + t.Name() {
+	case "timestamppb.Timestamp":
+		return openapi3.NewDateTimeSchema(), true
+	case "durationpb.Duration":
+		return openapi3.NewStringSchema().WithPattern(`^-?[0-9]+(\.[0-9]+)?s$`), true
+	case "fieldmaskpb.FieldMask":
+		return openapi3.NewStringSchema(), true
+	case "emptypb.Empty":
+		return openapi3.NewObjectSchema(), true
+	case "structpb.Struct":
+		return openapi3.NewObjectSchema().WithAnyAdditionalProperties(), true
+	case "structpb.Value":
+		return openapi3.NewSchema(), true
+	case "structpb.ListValue":
+		return openapi3.NewArraySchema().WithItems(openapi3.NewSchema()), true
+	case "anypb.Any":
+		return openapi3.NewObjectSchema().
+			WithProperty("@type", openapi3.NewStringSchema()).
+			WithRequired([]string{"@type"}).
+			WithAnyAdditionalProperties(), true
+	case "wrapperspb.DoubleValue", "wrapperspb.FloatValue":
+		return openapi3.NewFloat64Schema(), true
+	case "wrapperspb.Int64Value":
+		return openapi3.NewStringSchema().WithFormat("int64").WithPattern(`^-?[0-9]+$`), true
+	case "wrapperspb.UInt64Value":
+		return openapi3.NewStringSchema().WithFormat("uint64").WithPattern(`^[0-9]+$`), true
+	case "wrapperspb.Int32Value", "wrapperspb.UInt32Value":
+		return openapi3.NewIntegerSchema(), true
+	case "wrapperspb.BoolValue":
+		return openapi3.NewBoolSchema(), true
+	case "wrapperspb.StringValue":
+		return openapi3.NewStringSchema(), true
+	case "wrapperspb.BytesValue":
+		return openapi3.NewBytesSchema(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,317 @@
+// Package grpcgateway creates routes from the google.api.http annotations of
+// protobuf services, using the same mapping of paths, query strings and bodies
+// as grpc-gateway, so that proto-first services can document their REST API.
+//
+// The package doesn't depend on protobuf. Read the annotation of each method with
+// proto.GetExtension(method.Options(), annotations.E_Http), and copy it into an
+// HTTPRule.
+package grpcgateway
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/heimspiel/rest"
+)
+
+// HTTPRule is the HTTP mapping of an RPC, as set by the google.api.http annotation.
+type HTTPRule struct {
+	// Method of the rule, e.g. http.MethodGet.
+	Method string
+	// Pattern is the path template, e.g. /v1/{name=shelves/*}/books/{book_id}.
+	Pattern string
+	// Body is the request field that's mapped to the request body. "*" maps all of
+	// the fields that aren't bound by the path, and an empty string means that there
+	// is no request body.
+	Body string
+	// ResponseBody is the response field that's mapped to the response body. An empty
+	// string means that the whole response message is used.
+	ResponseBody string
+	// AdditionalBindings are further rules for the same RPC.
+	AdditionalBindings []HTTPRule
+}
+
+// Add creates routes for the rule, and its additional bindings. The request and response
+// are the Go types generated for the RPC's messages. Request fields that aren't bound
+// to the path or body become query parameters.
+//
+// The schemas of the messages match protojson: properties and query parameters use the
+// JSON names of the fields, e.g. bookId, enums are the names of their values, 64-bit
+// integers are strings, and well-known types such as google.protobuf.Timestamp have
+// their JSON forms. Messages with oneof fields aren't supported. If any of the rules or
+// messages is invalid, no routes are added.
+func Add(api *rest.API, rule HTTPRule, request, response rest.Model) (routes []*rest.Route, err error) {
+	rules := []HTTPRule{rule}
+	for _, binding := range rule.AdditionalBindings {
+		if len(binding.AdditionalBindings) > 0 {
+			return nil, fmt.Errorf("grpcgateway: %s %s: additional bindings cannot be nested", binding.Method, binding.Pattern)
+		}
+		rules = append(rules, binding)
+	}
+	mappings := make([]mapping, len(rules))
+	for i, r := range rules {
+		if mappings[i], err = getMapping(r, request, response); err != nil {
+			return nil, err
+		}
+	}
+	encoding := newProtoJSON()
+	if err = encoding.addMessage(request.Type); err != nil {
+		return nil, err
+	}
+	if err = encoding.addMessage(response.Type); err != nil {
+		return nil, err
+	}
+	encoding.apply(api)
+	for _, m := range mappings {
+		routes = append(routes, m.add(api))
+	}
+	return routes, nil
+}
+
+// mapping is a rule that has been checked against the request and response messages.
+type mapping struct {
+	method  string
+	pattern string
+	path    map[string]rest.PathParam
+	query   map[string]rest.QueryParam
+	// request is the model of the request body, if the rule has one.
+	request  *rest.Model
+	response rest.Model
+}
+
+func getMapping(rule HTTPRule, request, response rest.Model) (m mapping, err error) {
+	m = mapping{
+		method:   rule.Method,
+		path:     make(map[string]rest.PathParam),
+		query:    make(map[string]rest.QueryParam),
+		response: response,
+	}
+	var variables []variable
+	m.pattern, variables, err = getPattern(rule.Pattern)
+	if err != nil {
+		return m, fmt.Errorf("grpcgateway: %s %s: %w", rule.Method, rule.Pattern, err)
+	}
+	fields := getFields(request.Type)
+
+	bound := make(map[string]bool)
+	for _, v := range variables {
+		// Nested fields, e.g. book.name, bind the top-level field.
+		top, _, _ := strings.Cut(v.name, ".")
+		bound[top] = true
+		p := rest.PathParam{Type: rest.PrimitiveTypeString, Regexp: v.getRegexp()}
+		if f, ok := fields[v.name]; ok && p.Regexp == "" {
+			p.Type = getPrimitiveType(f)
+		}
+		m.path[v.name] = p
+	}
+
+	switch rule.Body {
+	case "":
+	case "*":
+		m.request = &request
+	default:
+		f, ok := fields[rule.Body]
+		if !ok {
+			return m, fmt.Errorf("grpcgateway: %s %s: body field %q not found in %v", rule.Method, rule.Pattern, rule.Body, request.Type)
+		}
+		bound[rule.Body] = true
+		m.request = &rest.Model{Type: f.Type}
+	}
+
+	if rule.Body != "*" {
+		for name, f := range fields {
+			if bound[name] {
+				continue
+			}
+			t := getPrimitiveType(f)
+			if t == "" {
+				// Messages and maps can't be set from the query string.
+				continue
+			}
+			m.query[getJSONName(f)] = rest.QueryParam{Type: t}
+		}
+	}
+
+	if rule.ResponseBody != "" {
+		f, ok := getFields(response.Type)[rule.ResponseBody]
+		if !ok {
+			return m, fmt.Errorf("grpcgateway: %s %s: response body field %q not found in %v", rule.Method, rule.Pattern, rule.ResponseBody, response.Type)
+		}
+		m.response = rest.Model{Type: f.Type}
+	}
+	return m, nil
+}
+
+// add creates the route of the mapping.
+func (m mapping) add(api *rest.API) *rest.Route {
+	route := api.Route(m.method, m.pattern)
+	for name, p := range m.path {
+		route.HasPathParameter(name, p)
+	}
+	if m.request != nil {
+		route.HasRequestModel(*m.request)
+	}
+	for name, p := range m.query {
+		route.HasQueryParameter(name, p)
+	}
+	route.HasResponseModel(http.StatusOK, m.response)
+	return route
+}
+
+// variable is a variable of a path template, e.g. {name=shelves/*}.
+type variable struct {
+	// name of the field that the variable binds, e.g. name.
+	name string
+	// segments that the variable matches, e.g. shelves/*. If empty, the variable matches
+	// a single segment.
+	segments string
+}
+
+// getRegexp returns a regular expression that matches the segments of the variable,
+// e.g. ^shelves/[^/]+$, or an empty string if the variable matches a single segment.
+func (v variable) getRegexp() string {
+	if v.segments == "" || v.segments == "*" {
+		return ""
+	}
+	parts := strings.Split(v.segments, "/")
+	for i, part := range parts {
+		switch part {
+		case "*":
+			parts[i] = "[^/]+"
+		case "**":
+			parts[i] = ".+"
+		default:
+			parts[i] = regexp.QuoteMeta(part)
+		}
+	}
+	return "^" + strings.Join(parts, "/") + "$"
+}
+
+// getPattern converts a path template to a route pattern, e.g. /v1/{name=shelves/*}
+// becomes /v1/{name}, and returns its variables.
+func getPattern(template string) (pattern string, variables []variable, err error) {
+	var sb strings.Builder
+	for {
+		start := strings.Index(template, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(template[start:], "}")
+		if end < 0 {
+			return "", nil, fmt.Errorf("unclosed variable")
+		}
+		end += start
+		literal := template[:start]
+		if strings.Contains(literal, "*") {
+			return "", nil, fmt.Errorf("wildcards outside of variables are not supported")
+		}
+		name, segments, _ := strings.Cut(template[start+1:end], "=")
+		sb.WriteString(literal)
+		sb.WriteString("{" + name + "}")
+		variables = append(variables, variable{name: name, segments: segments})
+		template = template[end+1:]
+	}
+	if strings.Contains(template, "*") {
+		return "", nil, fmt.Errorf("wildcards outside of variables are not supported")
+	}
+	sb.WriteString(template)
+	return sb.String(), variables, nil
+}
+
+// getFields returns the exported fields of the message type, keyed by their proto
+// field name, which is used in path templates and query strings.
+func getFields(t reflect.Type) (fields map[string]reflect.StructField) {
+	fields = make(map[string]reflect.StructField)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		fields[getProtoName(f)] = f
+	}
+	return fields
+}
+
+// getProtoName returns the name of the field in the .proto file, from the tag set by
+// protoc-gen-go, e.g. `protobuf:"bytes,1,opt,name=book_id,json=bookId,proto3"`.
+func getProtoName(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("protobuf"), ",") {
+		if name, ok := strings.CutPrefix(part, "name="); ok {
+			return name
+		}
+	}
+	if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return f.Name
+}
+
+// getJSONName returns the name of the field in protojson, which is set by protoc-gen-go
+// in the json option of the protobuf tag, e.g. json=bookId, or is the lowerCamelCase
+// form of the proto name.
+func getJSONName(f reflect.StructField) string {
+	for _, part := range strings.Split(f.Tag.Get("protobuf"), ",") {
+		if name, ok := strings.CutPrefix(part, "json="); ok {
+			return name
+		}
+	}
+	return toLowerCamelCase(getProtoName(f))
+}
+
+// toLowerCamelCase converts a proto field name to its JSON name, as protoc does, e.g.
+// book_id becomes bookId.
+func toLowerCamelCase(name string) string {
+	var sb strings.Builder
+	var upper bool
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// getPrimitiveType returns the type of a scalar field, or of the elements of a
+// repeated scalar field. An empty string is returned for other fields.
+func getPrimitiveType(f reflect.StructField) rest.PrimitiveType {
+	if isEnum(f) {
+		// Enums can be set using the names of their values.
+		return rest.PrimitiveTypeString
+	}
+	t := f.Type
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		t = t.Elem()
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Slice:
+		// bytes fields are base64 encoded strings.
+		return rest.PrimitiveTypeString
+	case reflect.Bool:
+		return rest.PrimitiveTypeBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rest.PrimitiveTypeInteger
+	case reflect.Float32, reflect.Float64:
+		return rest.PrimitiveTypeFloat64
+	}
+	return ""
+}
@@ -0,0 +1,213 @@
+package grpcgateway_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/grpcgateway"
+)
+
+// Book and the request types are written in the style of protoc-gen-go output.
+type Book struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+}
+
+type UpdateBookRequest struct {
+	ShelfId  int64    `protobuf:"varint,1,opt,name=shelf_id,json=shelfId,proto3" json:"shelf_id,omitempty"`
+	BookId   string   `protobuf:"bytes,2,opt,name=book_id,json=bookId,proto3" json:"book_id,omitempty"`
+	Book     *Book    `protobuf:"bytes,3,opt,name=book,proto3" json:"book,omitempty"`
+	Fields   []string `protobuf:"bytes,4,rep,name=fields,proto3" json:"fields,omitempty"`
+	PageSize int32    `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+}
+
+func TestAdd(t *testing.T) {
+	api := rest.NewAPI("test")
+	rule := grpcgateway.HTTPRule{
+		Method:  http.MethodPatch,
+		Pattern: "/v1/shelves/{shelf_id}/books/{book_id=books/*}",
+		Body:    "book",
+		AdditionalBindings: []grpcgateway.HTTPRule{
+			{Method: http.MethodPut, Pattern: "/v1/books/{book_id}", Body: "*"},
+		},
+	}
+	routes, err := grpcgateway.Add(api, rule, rest.ModelOf[UpdateBookRequest](), rest.ModelOf[Book]())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, got %d", len(routes))
+	}
+
+	patch := routes[0]
+	expected := rest.Params{
+		Path: map[string]rest.PathParam{
+			"shelf_id": {Type: rest.PrimitiveTypeInteger},
+			"book_id":  {Type: rest.PrimitiveTypeString, Regexp: "^books/[^/]+$"},
+		},
+		Query: map[string]rest.QueryParam{
+			"fields":   {Type: rest.PrimitiveTypeString},
+			"pageSize": {Type: rest.PrimitiveTypeInteger},
+		},
+	}
+	if diff := cmp.Diff(expected, patch.Params); diff != "" {
+		t.Error(diff)
+	}
+	if patch.Models.Request.Type != rest.ModelOf[*Book]().Type {
+		t.Errorf("expected the body to be the book field, got %v", patch.Models.Request.Type)
+	}
+
+	put := routes[1]
+	if len(put.Params.Query) != 0 {
+		t.Errorf("expected no query parameters when the body is *, got %v", put.Params.Query)
+	}
+	if put.Models.Request.Type != rest.ModelOf[UpdateBookRequest]().Type {
+		t.Errorf("expected the body to be the request, got %v", put.Models.Request.Type)
+	}
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	// The request body uses the JSON names of the fields, as protojson does.
+	for name, schema := range spec.Components.Schemas {
+		if !strings.HasSuffix(name, "UpdateBookRequest") {
+			continue
+		}
+		if _, ok := schema.Value.Properties["shelfId"]; !ok {
+			t.Errorf("expected the shelfId property, got %v", schema.Value.Properties)
+		}
+		if _, ok := schema.Value.Properties["shelf_id"]; ok {
+			t.Error("expected the proto name shelf_id to be replaced by its JSON name")
+		}
+		return
+	}
+	t.Error("expected an UpdateBookRequest schema")
+}
+
+// Shelf_Kind is an enum, with the Descriptor method that protoc-gen-go generates, which
+// returns a protoreflect.EnumDescriptor.
+type Shelf_Kind int32
+
+func (Shelf_Kind) Descriptor() enumDescriptor {
+	return enumDescriptor{"KIND_UNSPECIFIED", "FICTION", "REFERENCE"}
+}
+
+type enumDescriptor []string
+
+func (d enumDescriptor) Values() enumDescriptor { return d }
+func (d enumDescriptor) Len() int               { return len(d) }
+func (d enumDescriptor) Get(i int) enumValue    { return enumValue(d[i]) }
+
+type enumValue string
+
+func (v enumValue) Name() string { return string(v) }
+
+type Shelf struct {
+	Id      int64        `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind    Shelf_Kind   `protobuf:"varint,2,opt,name=kind,proto3,enum=library.Shelf_Kind" json:"kind,omitempty"`
+	BookIds []uint64     `protobuf:"varint,3,rep,packed,name=book_ids,json=bookIds,proto3" json:"book_ids,omitempty"`
+	Kinds   []Shelf_Kind `protobuf:"varint,4,rep,packed,name=kinds,proto3,enum=library.Shelf_Kind" json:"kinds,omitempty"`
+}
+
+type GetShelfRequest struct {
+	Name string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Kind Shelf_Kind `protobuf:"varint,2,opt,name=kind,proto3,enum=library.Shelf_Kind" json:"kind,omitempty"`
+}
+
+func TestAddUsesProtoJSONEncoding(t *testing.T) {
+	api := rest.NewAPI("test")
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest/grpcgateway_test"}
+	rule := grpcgateway.HTTPRule{Method: http.MethodGet, Pattern: "/v1/{name=shelves/*}"}
+	routes, err := grpcgateway.Add(api, rule, rest.ModelOf[GetShelfRequest](), rest.ModelOf[Shelf]())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	get := routes[0]
+	expected := rest.Params{
+		Path: map[string]rest.PathParam{
+			"name": {Type: rest.PrimitiveTypeString, Regexp: "^shelves/[^/]+$"},
+		},
+		Query: map[string]rest.QueryParam{
+			"kind": {Type: rest.PrimitiveTypeString},
+		},
+	}
+	if diff := cmp.Diff(expected, get.Params); diff != "" {
+		t.Error(diff)
+	}
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	shelf := spec.Components.Schemas["Shelf"].Value
+	for _, name := range []string{"id", "bookIds"} {
+		s := shelf.Properties[name].Value
+		if s.Items != nil {
+			s = s.Items.Value
+		}
+		if !s.Type.Is("string") {
+			t.Errorf("expected the 64-bit integer %s to be a string, got %v", name, s.Type)
+		}
+	}
+	kind := spec.Components.Schemas["Shelf_Kind"]
+	if kind == nil {
+		t.Fatalf("expected the enum to be a component, got %v", spec.Components.Schemas)
+	}
+	if diff := cmp.Diff([]any{"KIND_UNSPECIFIED", "FICTION", "REFERENCE"}, kind.Value.Enum); diff != "" {
+		t.Errorf("expected the enum to use the names of its values: %s", diff)
+	}
+	if !kind.Value.Type.Is("string") {
+		t.Errorf("expected the enum to be a string, got %v", kind.Value.Type)
+	}
+}
+
+type isBook_Identifier interface {
+	isBook_Identifier()
+}
+
+type BookWithOneof struct {
+	Name       string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Identifier isBook_Identifier `protobuf_oneof:"identifier"`
+}
+
+func TestAddRejectsOneofs(t *testing.T) {
+	api := rest.NewAPI("test")
+	rule := grpcgateway.HTTPRule{Method: http.MethodGet, Pattern: "/v1/books/{name}"}
+	if _, err := grpcgateway.Add(api, rule, rest.ModelOf[BookWithOneof](), rest.ModelOf[Book]()); err == nil {
+		t.Error("expected an error")
+	}
+	if len(api.Routes) != 0 {
+		t.Errorf("expected no routes to be added, got %v", api.Routes)
+	}
+}
+
+func TestAddErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule grpcgateway.HTTPRule
+	}{
+		{name: "unknown body field", rule: grpcgateway.HTTPRule{Method: http.MethodPost, Pattern: "/v1/books", Body: "missing"}},
+		{name: "wildcard outside variable", rule: grpcgateway.HTTPRule{Method: http.MethodGet, Pattern: "/v1/*/books"}},
+		{name: "unclosed variable", rule: grpcgateway.HTTPRule{Method: http.MethodGet, Pattern: "/v1/{book_id"}},
+		{name: "invalid additional binding", rule: grpcgateway.HTTPRule{
+			Method:             http.MethodGet,
+			Pattern:            "/v1/books/{book_id}",
+			AdditionalBindings: []grpcgateway.HTTPRule{{Method: http.MethodPost, Pattern: "/v1/books", Body: "missing"}},
+		}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := rest.NewAPI("test")
+			if _, err := grpcgateway.Add(api, test.rule, rest.ModelOf[UpdateBookRequest](), rest.ModelOf[Book]()); err == nil {
+				t.Error("expected an error")
+			}
+			if len(api.Routes) != 0 {
+				t.Errorf("expected no routes to be added, got %v", api.Routes)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// halLinksProperty is the property that contains the links of a HAL resource.
+const halLinksProperty = "_links"
+
+// Link is a HAL link to a related resource.
+type Link struct {
+	// Href is the URL of the related resource.
+	Href string `json:"href"`
+	// Templated is true if Href is a URI template, e.g. /users{?page}.
+	Templated bool `json:"templated,omitempty"`
+	// Title of the related resource.
+	Title string `json:"title,omitempty"`
+}
+
+// Links are the HAL links of a resource.
+type Links struct {
+	// Self is the URL of the resource.
+	Self Link `json:"self"`
+	// Next is the next page of a paginated resource.
+	Next *Link `json:"next,omitempty"`
+	// Prev is the previous page of a paginated resource.
+	Prev *Link `json:"prev,omitempty"`
+}
+
+// WithHALLinks adds a _links property, containing the Links of the resource, to the
+// object schemas of responses, for APIs that use HAL. Models can also have a Links
+// field named _links, which is left unchanged. The Link and Links components
+// are added to the specification.
+func WithHALLinks() APIOpts {
+	return func(api *API) {
+		api.HALLinks = true
+		api.modelNames[reflect.TypeOf(Link{})] = "Link"
+		api.modelNames[reflect.TypeOf(Links{})] = "Links"
+	}
+}
+
+// withHALLinks adds the _links property to the response schema, if it's an object that
+// doesn't already have links. The model's component isn't changed, since it may also be
+// used in requests, so the schema is combined with the links using allOf.
+func (api *API) withHALLinks(ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+	schema := ref.Value
+	if schema == nil {
+		schema, _ = api.getComponentSchema(getComponentName(ref.Ref))
+	}
+	if schema == nil || !schema.Type.Is(openapi3.TypeObject) || schema.Properties[halLinksProperty] != nil {
+		return ref, nil
+	}
+	name, linksSchema, err := api.RegisterModel(ModelOf[Links]())
+	if err != nil {
+		return nil, err
+	}
+	links := openapi3.NewObjectSchema().
+		WithPropertyRef(halLinksProperty, api.getSchemaReferenceOrValue(name, linksSchema)).
+		WithRequired([]string{halLinksProperty})
+	return openapi3.NewSchemaRef("", &openapi3.Schema{
+		AllOf: openapi3.SchemaRefs{ref, openapi3.NewSchemaRef("", links)},
+	}), nil
+}
@@ -0,0 +1,52 @@
+package rest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// specHashExtension is the extension of the spec's info that contains the hash of the spec.
+const specHashExtension = "x-spec-hash"
+
+// WithSpecHash adds the hash of the specification to its info, as x-spec-hash, so
+// that tools can detect when the specification of a service has changed.
+func WithSpecHash() APIOpts {
+	return func(api *API) {
+		api.IncludeSpecHash = true
+	}
+}
+
+// SpecHash returns a hash of the content of the specification. The hash only
+// changes when the content of the specification changes.
+func (api *API) SpecHash() (hash string, err error) {
+	spec, err := api.Spec()
+	if err != nil {
+		return "", err
+	}
+	return getSpecHash(spec)
+}
+
+func getSpecHash(spec *openapi3.T) (hash string, err error) {
+	data, err := spec.MarshalJSON()
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal spec: %w", err)
+	}
+	// Normalize the spec by unmarshalling it, since maps are marshalled with sorted keys.
+	var normalized map[string]any
+	if err = json.Unmarshal(data, &normalized); err != nil {
+		return "", fmt.Errorf("failed to unmarshal spec: %w", err)
+	}
+	// The hash isn't part of the content.
+	if info, ok := normalized["info"].(map[string]any); ok {
+		delete(info, specHashExtension)
+	}
+	if data, err = json.Marshal(normalized); err != nil {
+		return "", fmt.Errorf("failed to marshal spec: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
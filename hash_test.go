@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSpecHash(t *testing.T) {
+	newAPI := func(opts ...APIOpts) *API {
+		api := NewAPI("test", opts...)
+		api.Get("/user").HasResponseModel(http.StatusOK, ModelOf[User]())
+		api.Get("/owner").HasResponseModel(http.StatusOK, ModelOf[WithOwner]())
+		return api
+	}
+
+	api := newAPI()
+	hash, err := api.SpecHash()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hash) != 64 {
+		t.Errorf("expected a SHA-256 hash, got %q", hash)
+	}
+
+	// The hash is stable.
+	again, err := newAPI().SpecHash()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hash != again {
+		t.Errorf("expected the same hash, got %q and %q", hash, again)
+	}
+
+	// The hash is embedded, and doesn't include itself.
+	spec, err := newAPI(WithSpecHash()).Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embedded := spec.Info.Extensions["x-spec-hash"]; embedded != hash {
+		t.Errorf("expected x-spec-hash %q, got %v", hash, embedded)
+	}
+
+	// The hash changes when the content changes.
+	changed := newAPI()
+	changed.Routes["/user"][http.MethodGet].HasDescription("Get the user.")
+	changedHash, err := changed.SpecHash()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changedHash == hash {
+		t.Error("expected the hash to change")
+	}
+}
@@ -0,0 +1,127 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// HealthConfig configures the routes added by HasHealthEndpoints.
+type HealthConfig struct {
+	// Path of the liveness route, which returns 200 OK while the service is running.
+	// Defaults to /healthz.
+	Path string
+	// ReadinessPath of the readiness route, which returns 200 OK if all of the Checks pass,
+	// and 503 Service Unavailable otherwise. Defaults to /readyz.
+	ReadinessPath string
+	// VersionPath of the route that returns the Version. Defaults to /version.
+	VersionPath string
+	// Tag of the routes. Defaults to "health".
+	Tag string
+	// Version of the service, e.g. a semantic version or commit hash.
+	Version string
+	// Checks run by the readiness handler, by name, e.g. "database". A check fails if it
+	// returns an error.
+	Checks map[string]func(ctx context.Context) error
+}
+
+// HealthStatus is the status of a service or check.
+type HealthStatus string
+
+const (
+	HealthStatusOK          HealthStatus = "ok"
+	HealthStatusUnavailable HealthStatus = "unavailable"
+)
+
+// ApplyCustomSchema sets the allowed values.
+func (HealthStatus) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Enum = []any{HealthStatusOK, HealthStatusUnavailable}
+}
+
+// HealthResponse is the status of the service.
+type HealthResponse struct {
+	// Status of the service.
+	Status HealthStatus `json:"status"`
+	// Checks contains the status of each readiness check.
+	Checks map[string]HealthStatus `json:"checks,omitempty"`
+}
+
+// VersionResponse is the version of the service.
+type VersionResponse struct {
+	// Version of the service.
+	Version string `json:"version"`
+}
+
+// HealthHandlers are the handlers of the routes added by HasHealthEndpoints.
+type HealthHandlers struct {
+	Health    http.Handler
+	Readiness http.Handler
+	Version   http.Handler
+}
+
+// HasHealthEndpoints adds liveness, readiness and version routes to the API, and returns
+// handlers that implement them. The handlers can be ignored if the service already has
+// its own.
+func (api *API) HasHealthEndpoints(config HealthConfig) HealthHandlers {
+	if config.Path == "" {
+		config.Path = "/healthz"
+	}
+	if config.ReadinessPath == "" {
+		config.ReadinessPath = "/readyz"
+	}
+	if config.VersionPath == "" {
+		config.VersionPath = "/version"
+	}
+	if config.Tag == "" {
+		config.Tag = "health"
+	}
+	tags := []string{config.Tag}
+
+	api.Get(config.Path).
+		HasOperationID("getHealth").
+		HasDescription("Returns 200 OK while the service is running.").
+		HasTags(tags).
+		HasResponseModel(http.StatusOK, ModelOf[HealthResponse]()).
+		HasResponseDescription(http.StatusOK, "The service is running.")
+	api.Get(config.ReadinessPath).
+		HasOperationID("getReadiness").
+		HasDescription("Returns 200 OK if the service is ready to handle requests.").
+		HasTags(tags).
+		HasResponseModel(http.StatusOK, ModelOf[HealthResponse]()).
+		HasResponseDescription(http.StatusOK, "The service is ready.").
+		HasResponseModel(http.StatusServiceUnavailable, ModelOf[HealthResponse]()).
+		HasResponseDescription(http.StatusServiceUnavailable, "The service isn't ready, see the checks that failed.")
+	api.addVersionRoute(config.VersionPath).
+		HasTags(tags)
+
+	return HealthHandlers{
+		Health: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, HealthResponse{Status: HealthStatusOK})
+		}),
+		Readiness: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status, response := http.StatusOK, HealthResponse{Status: HealthStatusOK}
+			if len(config.Checks) > 0 {
+				response.Checks = make(map[string]HealthStatus, len(config.Checks))
+			}
+			for name, check := range config.Checks {
+				response.Checks[name] = HealthStatusOK
+				if err := check(r.Context()); err != nil {
+					response.Checks[name] = HealthStatusUnavailable
+					status, response.Status = http.StatusServiceUnavailable, HealthStatusUnavailable
+				}
+			}
+			writeJSON(w, status, response)
+		}),
+		Version: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, http.StatusOK, VersionResponse{Version: config.Version})
+		}),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	api := NewAPI("test")
+	handlers := api.HasHealthEndpoints(HealthConfig{
+		Version: "1.2.3",
+		Checks: map[string]func(ctx context.Context) error{
+			"cache": func(ctx context.Context) error { return nil },
+			"database": func(ctx context.Context) error {
+				return errors.New("connection refused")
+			},
+		},
+	})
+
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	for _, path := range []string{"/healthz", "/readyz", "/version"} {
+		if spec.Paths.Value(path) == nil || spec.Paths.Value(path).Get == nil {
+			t.Errorf("expected GET %s", path)
+		}
+	}
+	if err := spec.Validate(context.Background()); err != nil {
+		t.Errorf("spec is invalid: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		handler        http.Handler
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "health",
+			handler:        handlers.Health,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"status":"ok"}`,
+		},
+		{
+			name:           "readiness",
+			handler:        handlers.Readiness,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   `{"status":"unavailable","checks":{"cache":"ok","database":"unavailable"}}`,
+		},
+		{
+			name:           "version",
+			handler:        handlers.Version,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"version":"1.2.3"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			test.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != test.expectedStatus {
+				t.Errorf("expected status %d, got %d", test.expectedStatus, w.Code)
+			}
+			if body := w.Body.String(); body != test.expectedBody+"\n" {
+				t.Errorf("expected body %s, got %s", test.expectedBody, body)
+			}
+		})
+	}
+}
+
+func TestHealthStatusEnum(t *testing.T) {
+	api := NewAPI("test")
+	_, schema, err := api.RegisterModel(ModelOf[HealthStatus]())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schema.Enum) != 2 || !schema.Type.Is(openapi3.TypeString) {
+		t.Errorf("unexpected schema: %+v", schema)
+	}
+}
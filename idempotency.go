@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// IdempotencyKeyHeader is the header that clients use to make requests safe to retry.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// Names of the response components added for routes that require an idempotency key.
+const (
+	idempotencyKeyConflictResponse = "IdempotencyKeyConflict"
+	idempotencyKeyMismatchResponse = "IdempotencyKeyMismatch"
+)
+
+// RequiresIdempotencyKey documents that requests to the route must have an Idempotency-Key
+// header, so that they can be retried safely. 409 and 422 responses are added for
+// requests that reuse a key while the original is in progress, or with a different body.
+//
+// Use API.IdempotencyKeyRequired, or API.IdempotencyKeyMiddleware, to enforce the header.
+func (rm *Route) RequiresIdempotencyKey() *Route {
+	rm.IdempotencyKeyRequired = true
+	return rm
+}
+
+// IdempotencyKeyRequired returns true if the request is for a route that requires an
+// Idempotency-Key header, so that middleware that stores and replays responses only
+// applies to those routes.
+func (api *API) IdempotencyKeyRequired(r *http.Request) bool {
+	route, ok := api.matchRoute(r.Method, r.URL.Path)
+	return ok && route.IdempotencyKeyRequired
+}
+
+// IdempotencyKeyMiddleware rejects requests to routes that require an Idempotency-Key
+// header, but don't have one, with a 400 status.
+func (api *API) IdempotencyKeyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(IdempotencyKeyHeader) == "" && api.IdempotencyKeyRequired(r) {
+			http.Error(w, "missing "+IdempotencyKeyHeader+" header", http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// addIdempotencyKey documents the Idempotency-Key header, and the responses to requests
+// that reuse a key, on the operation.
+func addIdempotencyKey(spec *openapi3.T, op *openapi3.Operation) {
+	header := openapi3.NewHeaderParameter(IdempotencyKeyHeader).
+		WithDescription("A unique key, e.g. a UUID, that allows the request to be retried without being processed twice.").
+		WithRequired(true).
+		WithSchema(openapi3.NewStringSchema().WithMinLength(1).WithMaxLength(255))
+	op.AddParameter(header)
+
+	if spec.Components.Responses == nil {
+		spec.Components.Responses = make(openapi3.ResponseBodies)
+	}
+	spec.Components.Responses[idempotencyKeyConflictResponse] = &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().WithDescription("A request with the same idempotency key is in progress."),
+	}
+	spec.Components.Responses[idempotencyKeyMismatchResponse] = &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().WithDescription("The idempotency key was used for a request with a different body."),
+	}
+	if op.Responses == nil {
+		op.Responses = openapi3.NewResponses()
+	}
+	for status, name := range map[int]string{
+		http.StatusConflict:            idempotencyKeyConflictResponse,
+		http.StatusUnprocessableEntity: idempotencyKeyMismatchResponse,
+	} {
+		if op.Responses.Status(status) == nil {
+			op.Responses.Set(strconv.Itoa(status), &openapi3.ResponseRef{
+				Ref: "#/components/responses/" + name,
+			})
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIdempotencyKeyMiddleware(t *testing.T) {
+	api := NewAPI("test")
+	api.Post("/payments/{id}").RequiresIdempotencyKey()
+	api.Get("/payments/{id}")
+
+	h := api.IdempotencyKeyMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tests := []struct {
+		method   string
+		key      string
+		expected int
+	}{
+		{method: http.MethodPost, expected: http.StatusBadRequest},
+		{method: http.MethodPost, key: "abc", expected: http.StatusOK},
+		{method: http.MethodGet, expected: http.StatusOK},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, "/payments/123", nil)
+		if test.key != "" {
+			r.Header.Set(IdempotencyKeyHeader, test.key)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != test.expected {
+			t.Errorf("%s with key %q: expected status %d, got %d", test.method, test.key, test.expected, w.Code)
+		}
+	}
+}
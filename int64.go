@@ -0,0 +1,113 @@
+package rest
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Int64Encoding sets how int64 and uint64 fields are represented in the specification.
+type Int64Encoding int
+
+const (
+	// Int64Integer represents the fields as integers, which is how encoding/json
+	// marshals them.
+	Int64Integer Int64Encoding = iota
+	// Int64String represents the fields as strings with the int64 or uint64 format, for
+	// clients such as JavaScript that lose precision on integers larger than 2^53.
+	Int64String
+	// Int64StringEncoded keeps the fields as integers with the int64 or uint64 format,
+	// and adds x-string-encoded: true, for code generators that support the extension.
+	Int64StringEncoded
+)
+
+// stringEncodedExtension marks integers that are sent as strings.
+const stringEncodedExtension = "x-string-encoded"
+
+// int64Encodings are the values of the int64 struct tag.
+var int64Encodings = map[string]Int64Encoding{
+	"integer":        Int64Integer,
+	"string":         Int64String,
+	"string-encoded": Int64StringEncoded,
+}
+
+// WithInt64Encoding sets how int64 and uint64 fields, and slices of them, are represented
+// in the specification. Use the int64 struct tag to override the encoding of a field,
+// e.g. `int64:"integer"`, `int64:"string"` or `int64:"string-encoded"`. Fields with the
+// string option in their json tag are always strings, since that's how encoding/json
+// marshals them.
+//
+// Enums, known types such as time.Duration, and referenced schemas are left as they are.
+func WithInt64Encoding(e Int64Encoding) APIOpts {
+	return func(api *API) {
+		api.Int64Encoding = e
+	}
+}
+
+// WithInt64AsString represents int64 and uint64 fields as strings, e.g.
+//
+//	id:
+//	  type: string
+//	  format: int64
+//	  pattern: ^-?[0-9]+$
+//
+// See WithInt64Encoding.
+func WithInt64AsString() APIOpts {
+	return WithInt64Encoding(Int64String)
+}
+
+// applyInt64Encoding sets the representation of int64 and uint64 fields, and the items
+// of slices of them, from the API's Int64Encoding, the field's int64 tag, and the string
+// option of its json tag.
+func (api *API) applyInt64Encoding(f reflect.StructField, jsonTags []string, ref *openapi3.SchemaRef) error {
+	tag, hasTag := f.Tag.Lookup("int64")
+	encoding := api.Int64Encoding
+	if hasTag {
+		var ok bool
+		if encoding, ok = int64Encodings[tag]; !ok {
+			return fmt.Errorf("invalid int64 tag %q, expected integer, string or string-encoded", tag)
+		}
+	}
+	t := f.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	s := ref.Value
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && s != nil && s.Items != nil {
+		t, s = t.Elem(), s.Items.Value
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+	} else if !hasTag && slices.Contains(jsonTags[1:], "string") {
+		// encoding/json only applies the string option to scalar fields.
+		encoding = Int64String
+	}
+	if t.Kind() != reflect.Int64 && t.Kind() != reflect.Uint64 {
+		if hasTag {
+			return fmt.Errorf("int64 tag is not supported on %v fields", f.Type)
+		}
+		return nil
+	}
+	if _, ok := api.KnownTypes[t]; ok || s == nil || len(s.Enum) > 0 || !s.Type.Is(openapi3.TypeInteger) {
+		return nil
+	}
+	format, pattern := "int64", `^-?[0-9]+$`
+	if t.Kind() == reflect.Uint64 {
+		format, pattern = "uint64", `^[0-9]+$`
+	}
+	switch encoding {
+	case Int64String:
+		s.Type = &openapi3.Types{openapi3.TypeString}
+		s.Format = format
+		s.Pattern = pattern
+	case Int64StringEncoded:
+		s.Format = format
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions[stringEncodedExtension] = true
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+// Package user contains the enums of the User entity, in the form generated by ent.
+package user
+
+// Status defines the type for the "status" enum field.
+type Status string
+
+// Status values.
+const (
+	StatusActive    Status = "active"
+	StatusSuspended Status = "suspended"
+)
+
+func (s Status) String() string {
+	return string(s)
+}
@@ -0,0 +1,223 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Models returns a snapshot of the models registered with the API, keyed by schema name.
+// Changes to the returned schemas do not affect the API.
+func (api *API) Models() (models map[string]*openapi3.Schema, err error) {
+	models = make(map[string]*openapi3.Schema, len(api.models))
+	for name, schema := range api.models {
+		if models[name], err = cloneSchema(schema); err != nil {
+			return nil, fmt.Errorf("failed to copy schema %q: %w", name, err)
+		}
+	}
+	return models, nil
+}
+
+func cloneSchema(s *openapi3.Schema) (*openapi3.Schema, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var copied openapi3.Schema
+	if err = json.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+	return &copied, nil
+}
+
+// RouteInfo describes a route registered with the API.
+type RouteInfo struct {
+	// Method of the route, e.g. GET.
+	Method Method
+	// Pattern the route was registered with.
+	Pattern Pattern
+	// Path of the route in the OpenAPI specification, i.e. the pattern without a querystring.
+	Path string
+	// Route configuration.
+	Route *Route
+}
+
+// RouteInfo returns the routes registered with the API, sorted by path and method.
+func (api *API) RouteInfo() (routes []RouteInfo) {
+	for _, pattern := range getSortedKeys(api.Routes) {
+		for _, method := range getSortedKeys(api.Routes[pattern]) {
+			routes = append(routes, RouteInfo{
+				Method:  method,
+				Pattern: pattern,
+				Path:    getPath(pattern),
+				Route:   api.Routes[pattern][method],
+			})
+		}
+	}
+	slices.SortStableFunc(routes, func(a, b RouteInfo) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	return routes
+}
+
+// ModelDescription explains how a model is mapped to an OpenAPI schema.
+type ModelDescription struct {
+	// Name of the schema.
+	Name string
+	// Type of the model.
+	Type reflect.Type
+	// Schema created for the model.
+	Schema *openapi3.Schema
+	// KnownType is true if the schema was taken from the KnownTypes of the API.
+	KnownType bool
+	// CustomSchema is true if the type customises its own schema with an ApplyCustomSchema method.
+	CustomSchema bool
+	// Fields of the model, if it's a struct.
+	Fields []FieldDescription
+}
+
+// FieldDescription explains how a struct field is mapped to an OpenAPI schema property.
+type FieldDescription struct {
+	// GoName is the name of the field in the Go struct.
+	GoName string
+	// Name of the property in the schema.
+	Name string
+	// NameSource explains where the property name came from, e.g. "json tag".
+	NameSource string
+	// Type of the field.
+	Type reflect.Type
+	// Schema is the name of the referenced schema, or the type of the inline schema.
+	Schema string
+	// KnownType is true if the schema was taken from the KnownTypes of the API.
+	KnownType bool
+	// Comment is the doc comment of the field, used as the property description.
+	Comment string
+	// Required is true if the property is required.
+	Required bool
+	// Embedded is true if the field is an embedded struct, with fields that are promoted.
+	Embedded bool
+	// Skipped explains why the field is not in the schema, if it's not.
+	Skipped string
+}
+
+// DescribeModel explains how the model is mapped to an OpenAPI schema, without registering it.
+func (api *API) DescribeModel(model Model) (d ModelDescription, err error) {
+	dryRun := *api
+	dryRun.models = maps.Clone(api.models)
+	dryRun.modelTypes = maps.Clone(api.modelTypes)
+	dryRun.warnings = nil
+	dryRun.warned = nil
+
+	t := model.Type
+	d.Type = t
+	_, d.KnownType = api.KnownTypes[t]
+	_, d.CustomSchema = reflect.New(t).Interface().(CustomSchemaApplier)
+	if d.Name, d.Schema, err = dryRun.RegisterModel(model); err != nil {
+		return d, err
+	}
+	if t.Kind() != reflect.Struct || d.KnownType {
+		return d, nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fd := FieldDescription{
+			GoName:     f.Name,
+			Name:       f.Name,
+			NameSource: "field name",
+			Type:       f.Type,
+			Embedded:   isPromoted(f),
+		}
+		if name, tagged := getJSONFieldName(f); tagged {
+			fd.Name = name
+			fd.NameSource = "json tag"
+		}
+		if !f.IsExported() {
+			fd.Skipped = "not exported"
+			d.Fields = append(d.Fields, fd)
+			continue
+		}
+		if isSkipped(f) {
+			fd.Skipped = `json tag is "-"`
+			d.Fields = append(d.Fields, fd)
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Interface {
+			if api.EmbeddedInterfacePolicy == EmbeddedInterfaceAny {
+				fd.Schema = "any"
+			} else {
+				fd.Skipped = "embedded interface"
+			}
+			d.Fields = append(d.Fields, fd)
+			continue
+		}
+		_, fd.KnownType = api.KnownTypes[f.Type]
+		fieldName, fieldSchema, err := dryRun.RegisterModel(modelFromType(f.Type))
+		if err != nil {
+			return d, err
+		}
+		fd.Schema = dryRun.describeSchema(fieldName, fieldSchema)
+		if fd.Comment, _, err = dryRun.getTypeFieldComment(t.PkgPath(), t.Name(), f.Name); err != nil {
+			return d, err
+		}
+		if !fd.Embedded {
+			_, inSchema := d.Schema.Properties[fd.Name]
+			if !inSchema {
+				fd.Skipped = "shadowed or ambiguous"
+			}
+		}
+		fd.Required = slices.Contains(d.Schema.Required, fd.Name)
+		d.Fields = append(d.Fields, fd)
+	}
+	return d, nil
+}
+
+func (api *API) describeSchema(name string, s *openapi3.Schema) string {
+	if api.models[name] == s {
+		return "#/components/schemas/" + name
+	}
+	if s.Type == nil {
+		return ""
+	}
+	return strings.Join(s.Type.Slice(), ",")
+}
+
+// String returns a human readable description of the model.
+func (d ModelDescription) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s (%v)", d.Name, d.Type)
+	if d.KnownType {
+		sb.WriteString(", known type")
+	}
+	if d.CustomSchema {
+		sb.WriteString(", customised by ApplyCustomSchema")
+	}
+	sb.WriteString("\n")
+	for _, f := range d.Fields {
+		fmt.Fprintf(&sb, "  %s: ", f.GoName)
+		if f.Skipped != "" {
+			fmt.Fprintf(&sb, "skipped, %s\n", f.Skipped)
+			continue
+		}
+		if f.Embedded {
+			fmt.Fprintf(&sb, "embedded %v, fields promoted\n", f.Type)
+			continue
+		}
+		fmt.Fprintf(&sb, "%q from %s, %v as %s", f.Name, f.NameSource, f.Type, f.Schema)
+		if f.KnownType {
+			sb.WriteString(" (known type)")
+		}
+		if f.Required {
+			sb.WriteString(", required")
+		}
+		if f.Comment != "" {
+			fmt.Fprintf(&sb, ", comment %q", f.Comment)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestModels(t *testing.T) {
+	api := NewAPI("test")
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+	api.Get("/user").HasResponseModel(http.StatusOK, ModelOf[User]())
+	if _, err := api.Spec(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	models, err := api.Models()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"User"}, getSortedKeys(models)); diff != "" {
+		t.Fatal(diff)
+	}
+
+	// Changes to the snapshot don't affect the API.
+	models["User"].Description = "Changed"
+	if api.models["User"].Description != "" {
+		t.Error("expected the snapshot to be a copy")
+	}
+}
+
+func TestRouteInfo(t *testing.T) {
+	api := NewAPI("test")
+	api.Post("/users")
+	api.Get("/users?sort=asc")
+	api.Get("/")
+
+	var actual []string
+	for _, ri := range api.RouteInfo() {
+		actual = append(actual, string(ri.Method)+" "+ri.Path+" "+string(ri.Route.Pattern))
+	}
+	expected := []string{
+		"GET / /",
+		"POST /users /users",
+		"GET /users /users?sort=asc",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+type DescribedModel struct {
+	// Name of the model.
+	Name    string     `json:"name"`
+	Created KnownTypes `json:"created,omitempty"`
+	Tags    []string
+	private string
+	EmbeddedStructA
+}
+
+func TestDescribeModel(t *testing.T) {
+	api := NewAPI("test")
+	api.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+
+	d, err := api.DescribeModel(ModelOf[DescribedModel]())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `DescribedModel (rest.DescribedModel)
+  Name: "name" from json tag, string as string, required, comment "Name of the model."
+  Created: "created" from json tag, rest.KnownTypes as #/components/schemas/KnownTypes
+  Tags: "Tags" from field name, []string as array, required
+  private: skipped, not exported
+  EmbeddedStructA: embedded rest.EmbeddedStructA, fields promoted
+`
+	if diff := cmp.Diff(expected, d.String()); diff != "" {
+		t.Error(diff)
+	}
+
+	// Describing a model doesn't register it.
+	if len(api.models) != 0 {
+		t.Errorf("expected no models to be registered, got %v", getSortedKeys(api.models))
+	}
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// JSONAPIContentType is the media type of JSON:API documents.
+const JSONAPIContentType = "application/vnd.api+json"
+
+// JSONAPIResourceOf creates a model of a JSON:API document containing a single resource
+// of type T, e.g. {"data": {"type": "articles", "id": "1", "attributes": {...}}}, with the
+// application/vnd.api+json content type. The id property of T becomes the id of the
+// resource, and its other properties are the attributes.
+func JSONAPIResourceOf[T any](resourceType string) Model {
+	m := ModelOf[T]()
+	m.contentType = JSONAPIContentType
+	m.wrap = func(api *API, ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+		return api.getJSONAPIDocument(resourceType, ref)
+	}
+	return m
+}
+
+// getJSONAPIDocument wraps the schema of a model in a JSON:API document.
+func (api *API) getJSONAPIDocument(resourceType string, ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+	schema := ref.Value
+	if schema == nil {
+		schema = api.models[getComponentName(ref.Ref)]
+	}
+	if schema == nil || !schema.Type.Is(openapi3.TypeObject) {
+		return nil, fmt.Errorf("JSON:API resource %q must be an object", resourceType)
+	}
+	attributes, err := cloneSchema(schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy schema of JSON:API resource %q: %w", resourceType, err)
+	}
+	delete(attributes.Properties, "id")
+	attributes.Required = slices.DeleteFunc(attributes.Required, func(r string) bool { return r == "id" })
+
+	relationship := openapi3.NewObjectSchema().
+		WithProperty("data", openapi3.NewSchema().WithNullable()).
+		WithProperty("links", openapi3.NewObjectSchema().WithAdditionalProperties(openapi3.NewStringSchema()))
+	relationship.Properties["data"].Value.Description = "A resource identifier, an array of resource identifiers, or null."
+
+	data := openapi3.NewObjectSchema().
+		WithProperty("type", openapi3.NewStringSchema().WithEnum(resourceType)).
+		WithProperty("id", openapi3.NewStringSchema()).
+		WithPropertyRef("attributes", openapi3.NewSchemaRef("", attributes)).
+		WithProperty("relationships", openapi3.NewObjectSchema().WithAdditionalProperties(relationship))
+	// The id can be omitted when a resource is created.
+	data.Required = []string{"type"}
+
+	document := openapi3.NewObjectSchema().
+		WithPropertyRef("data", openapi3.NewSchemaRef("", data)).
+		WithProperty("links", openapi3.NewObjectSchema().WithAdditionalProperties(openapi3.NewStringSchema())).
+		WithProperty("meta", openapi3.NewObjectSchema())
+	document.Required = []string{"data"}
+	return openapi3.NewSchemaRef("", document), nil
+}
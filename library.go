@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ComponentLibrary is a set of models that are shared by multiple APIs, e.g. across
+// services, so that the models have the same schema names and definitions in each API.
+//
+// Register the models with the library before creating the APIs that use it.
+type ComponentLibrary struct {
+	// StripPkgPaths removes the package paths from the schema names of the library's
+	// models, see API.StripPkgPaths.
+	StripPkgPaths []string
+
+	api *API
+}
+
+// NewComponentLibrary creates a library of shared models. The options configure how
+// the schemas of the models are generated.
+func NewComponentLibrary(opts ...APIOpts) *ComponentLibrary {
+	return &ComponentLibrary{
+		api: NewAPI("components", opts...),
+	}
+}
+
+// Register adds a model to the library, along with the models it uses.
+func (lib *ComponentLibrary) Register(model Model, opts ...ModelOpts) (name string, err error) {
+	lib.api.StripPkgPaths = lib.StripPkgPaths
+	name, _, err = lib.api.RegisterModel(model, opts...)
+	return name, err
+}
+
+// Spec creates an OpenAPI specification that contains the components of the library,
+// and no paths, which can be published for use by other services.
+func (lib *ComponentLibrary) Spec() (*openapi3.T, error) {
+	return lib.api.Spec()
+}
+
+// WithComponentLibrary adds the models of the library to the API. When the API uses
+// a type that's in the library, the library's schema name and definition are used.
+// Use WithPruneUnusedSchemas to omit library models that the API doesn't use.
+func WithComponentLibrary(lib *ComponentLibrary) APIOpts {
+	return func(api *API) {
+		for _, name := range getSortedKeys(lib.api.models) {
+			schema, err := cloneSchema(lib.api.models[name])
+			if err != nil {
+				api.errs = append(api.errs, fmt.Errorf("failed to copy schema %q from component library: %w", name, err))
+				continue
+			}
+			t := lib.api.modelTypes[name]
+			api.models[name] = schema
+			api.modelTypes[name] = t
+			api.modelNames[t] = name
+		}
+	}
+}
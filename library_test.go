@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestComponentLibrary(t *testing.T) {
+	lib := NewComponentLibrary()
+	lib.StripPkgPaths = []string{"github.com/heimspiel/rest"}
+	if _, err := lib.Register(ModelOf[WithOwner](), WithDescription("Something with an owner.")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	published, err := lib.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]string{"User", "WithOwner"}, getSortedKeys(published.Components.Schemas)); diff != "" {
+		t.Errorf("unexpected library schemas: %s", diff)
+	}
+
+	// The API doesn't strip package paths, but uses the names from the library.
+	api := NewAPI("test", WithComponentLibrary(lib))
+	api.Get("/owner").HasResponseModel(http.StatusOK, ModelOf[WithOwner]())
+	api.Get("/users").HasResponseModel(http.StatusOK, ModelOf[[]User]())
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(toJSON(t, published.Components.Schemas), toJSON(t, spec.Components.Schemas)); diff != "" {
+		t.Errorf("expected the API to use the library schemas: %s", diff)
+	}
+
+	// Changes to the API don't affect the library.
+	api.models["User"].Description = "Changed"
+	if lib.api.models["User"].Description != "" {
+		t.Error("expected the library schemas to be copied")
+	}
+}
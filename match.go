@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// routeMatcher matches requests to the routes of an API. It's built when a request is
+// first matched, and rebuilt after routes are added, or their path parameters change.
+type routeMatcher struct {
+	m sync.RWMutex
+	// routes maps from method to the routes, with the fewest placeholders first, or nil
+	// if the matcher must be rebuilt.
+	routes map[Method][]routeRegexp
+}
+
+// routeRegexp matches request paths against the path of a route.
+type routeRegexp struct {
+	route *Route
+	*regexp.Regexp
+	// placeholders is the number of placeholders in the path, used to prefer
+	// literal matches, e.g. /users/me over /users/{id}.
+	placeholders int
+}
+
+// invalidate discards the matcher, so that it's rebuilt for the next request.
+func (m *routeMatcher) invalidate() {
+	if m == nil {
+		return
+	}
+	m.m.Lock()
+	defer m.m.Unlock()
+	m.routes = nil
+}
+
+// getRoutes returns the routes of the method, building the matcher if required.
+func (m *routeMatcher) getRoutes(api *API, method Method) []routeRegexp {
+	m.m.RLock()
+	routes := m.routes
+	m.m.RUnlock()
+	if routes != nil {
+		return routes[method]
+	}
+	m.m.Lock()
+	defer m.m.Unlock()
+	if m.routes == nil {
+		m.routes = make(map[Method][]routeRegexp)
+		for _, pattern := range getSortedKeys(api.Routes) {
+			for method, route := range api.Routes[pattern] {
+				m.routes[method] = append(m.routes[method], getRouteRegexp(route))
+			}
+		}
+		for _, routes := range m.routes {
+			slices.SortStableFunc(routes, func(a, b routeRegexp) int {
+				return cmp.Compare(a.placeholders, b.placeholders)
+			})
+		}
+	}
+	return m.routes[method]
+}
+
+// getRouteRegexp returns a regular expression that matches request paths for the path
+// of a route. Each placeholder matches a single path segment, or the regular expression
+// or type of its path parameter.
+func getRouteRegexp(route *Route) routeRegexp {
+	path := getPath(route.Pattern)
+	if re, ok := compileRoutePath(path, route.Params.Path); ok {
+		return routeRegexp{route: route, Regexp: re.Regexp, placeholders: re.placeholders}
+	}
+	// Invalid regular expressions of parameters are ignored.
+	re, _ := compileRoutePath(path, nil)
+	return routeRegexp{route: route, Regexp: re.Regexp, placeholders: re.placeholders}
+}
+
+func compileRoutePath(path string, params map[string]PathParam) (re routeRegexp, ok bool) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	remaining := path
+	for {
+		start := strings.Index(remaining, "{")
+		if start < 0 {
+			break
+		}
+		end := findPlaceholderEnd(remaining, start)
+		if end < 0 {
+			break
+		}
+		name, _, _ := strings.Cut(remaining[start+1:end], ":")
+		sb.WriteString(regexp.QuoteMeta(remaining[:start]))
+		sb.WriteString("(?:" + getPathParamRegexp(params[name]) + ")")
+		re.placeholders++
+		remaining = remaining[end+1:]
+	}
+	sb.WriteString(regexp.QuoteMeta(remaining))
+	sb.WriteString("$")
+	var err error
+	re.Regexp, err = regexp.Compile(sb.String())
+	return re, err == nil
+}
+
+// getPathParamRegexp returns the regular expression that matches the value of a path
+// parameter within a path.
+func getPathParamRegexp(p PathParam) string {
+	if p.Regexp != "" {
+		return strings.TrimSuffix(strings.TrimPrefix(p.Regexp, "^"), "$")
+	}
+	switch p.Type {
+	case PrimitiveTypeInteger:
+		return `-?[0-9]+`
+	case PrimitiveTypeFloat64:
+		return `-?[0-9]+(?:\.[0-9]+)?`
+	case PrimitiveTypeBool:
+		return `true|false`
+	}
+	if s, ok := getRegisteredPrimitiveSchema(p.Type); ok && s.Pattern != "" {
+		return strings.TrimSuffix(strings.TrimPrefix(s.Pattern, "^"), "$")
+	}
+	return `[^/]+`
+}
+
+// matchRoute finds the route that handles requests with the given method and path.
+// Routes with literal path segments are preferred over routes with placeholders.
+func (api *API) matchRoute(method, path string) (route *Route, ok bool) {
+	path = api.PathNormalization.normalize(path)
+	for _, r := range api.matcher.getRoutes(api, Method(method)) {
+		if r.MatchString(path) {
+			return r.route, true
+		}
+	}
+	return nil, false
+}
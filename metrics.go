@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metrics records how the API is used, see MetricsHandler.
+type metrics struct {
+	m                sync.Mutex
+	specCount        int
+	specSeconds      float64
+	requestCount     map[string]int
+	requestBodyBytes map[string]int64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requestCount:     make(map[string]int),
+		requestBodyBytes: make(map[string]int64),
+	}
+}
+
+func (m *metrics) recordSpec(d time.Duration) {
+	m.m.Lock()
+	defer m.m.Unlock()
+	m.specCount++
+	m.specSeconds += d.Seconds()
+}
+
+// MetricsMiddleware records the size of request bodies sent to each of the API's operations.
+// See MetricsHandler.
+func (api *API) MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := api.matchRoute(r.Method, r.URL.Path); ok && r.ContentLength >= 0 {
+			operation := getOperation(route)
+			api.metrics.m.Lock()
+			api.metrics.requestCount[operation]++
+			api.metrics.requestBodyBytes[operation] += r.ContentLength
+			api.metrics.m.Unlock()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MetricsHandler serves the API's metrics in the Prometheus text exposition format, so that
+// they can be scraped without a dependency on the Prometheus client library.
+//
+//	rest_spec_generation_seconds: the time taken by Spec.
+//	rest_request_body_bytes: the size of request bodies by operation, see MetricsMiddleware.
+func (api *API) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		api.WriteMetrics(w)
+	})
+}
+
+// WriteMetrics writes the API's metrics to w in the Prometheus text exposition format.
+func (api *API) WriteMetrics(w io.Writer) (err error) {
+	m := api.metrics
+	m.m.Lock()
+	defer m.m.Unlock()
+	var sb strings.Builder
+	sb.WriteString("# HELP rest_spec_generation_seconds Time taken to generate the OpenAPI specification.\n")
+	sb.WriteString("# TYPE rest_spec_generation_seconds summary\n")
+	fmt.Fprintf(&sb, "rest_spec_generation_seconds_sum %g\n", m.specSeconds)
+	fmt.Fprintf(&sb, "rest_spec_generation_seconds_count %d\n", m.specCount)
+	sb.WriteString("# HELP rest_request_body_bytes Size of request bodies by operation.\n")
+	sb.WriteString("# TYPE rest_request_body_bytes summary\n")
+	for _, operation := range getSortedKeys(m.requestCount) {
+		label := labelEscaper.Replace(operation)
+		fmt.Fprintf(&sb, "rest_request_body_bytes_sum{operation=\"%s\"} %d\n", label, m.requestBodyBytes[operation])
+		fmt.Fprintf(&sb, "rest_request_body_bytes_count{operation=\"%s\"} %d\n", label, m.requestCount[operation])
+	}
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+// labelEscaper escapes Prometheus label values.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,38 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	api := NewAPI("test")
+	api.Post("/users").HasRequestModel(ModelOf[User]()).HasResponseModel(http.StatusOK, ModelOf[User]())
+	if _, err := api.Spec(); err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+
+	h := api.MetricsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"id":1}`)))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{}`)))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/other", strings.NewReader(`{}`)))
+
+	w := httptest.NewRecorder()
+	api.MetricsHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	actual := w.Body.String()
+
+	for _, expected := range []string{
+		"rest_spec_generation_seconds_count 1\n",
+		`rest_request_body_bytes_sum{operation="POST /users"} 10` + "\n",
+		`rest_request_body_bytes_count{operation="POST /users"} 2` + "\n",
+	} {
+		if !strings.Contains(actual, expected) {
+			t.Errorf("expected %q in metrics, got:\n%s", expected, actual)
+		}
+	}
+	if strings.Contains(actual, "/other") {
+		t.Errorf("expected undocumented requests to be excluded, got:\n%s", actual)
+	}
+}
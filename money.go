@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Money is an amount in the minor units of its currency, e.g. an amount of 1234 with a
+// currency of EUR is €12.34.
+type Money struct {
+	// Amount in the minor units of the currency, e.g. cents.
+	Amount int64 `json:"amount"`
+	// Currency is the ISO 4217 code of the currency, e.g. EUR.
+	Currency string `json:"currency"`
+}
+
+// currencyPattern matches ISO 4217 currency codes.
+const currencyPattern = `^[A-Z]{3}$`
+
+// ApplyCustomSchema sets the format of the amount, the pattern of the currency, and an
+// example.
+func (Money) ApplyCustomSchema(s *openapi3.Schema) {
+	if amount := s.Properties["amount"]; amount != nil && amount.Value != nil && amount.Value.Format == "" {
+		amount.Value.Format = "int64"
+	}
+	if currency := s.Properties["currency"]; currency != nil && currency.Value != nil {
+		currency.Value.Pattern = currencyPattern
+		currency.Value.Example = "EUR"
+	}
+	s.Example = map[string]any{"amount": 1234, "currency": "EUR"}
+}
+
+const (
+	// decimalPattern matches decimal numbers, e.g. "-12.34".
+	decimalPattern = `^-?[0-9]+(\.[0-9]+)?$`
+	// ratPattern matches the text of a big.Rat, which is an integer or a fraction, e.g. "-617/50".
+	ratPattern = `^-?[0-9]+(/[0-9]+)?$`
+)
+
+// NewDecimalSchema returns a string schema for decimal numbers, e.g. "12.34", which is
+// how decimal types such as decimal.Decimal from github.com/shopspring/decimal are
+// marshalled, so that amounts don't lose precision in clients that use floats.
+func NewDecimalSchema() *openapi3.Schema {
+	s := openapi3.NewStringSchema().WithPattern(decimalPattern)
+	s.Format = "decimal"
+	s.Example = "12.34"
+	return s
+}
+
+// WithDecimalType represents T, and pointers to T, as decimal strings, see
+// NewDecimalSchema, e.g.
+//
+//	api := rest.NewAPI("orders", rest.WithDecimalType[decimal.Decimal]())
+func WithDecimalType[T any]() APIOpts {
+	return func(api *API) {
+		var t T
+		api.KnownTypes[reflect.TypeOf(t)] = *NewDecimalSchema()
+		api.KnownTypes[reflect.TypeOf(&t)] = *NewDecimalSchema().WithNullable()
+	}
+}
+
+// newRatSchema returns the schema of big.Rat, which is marshalled as an integer or a
+// fraction in its lowest terms, e.g. "617/50" for 12.34.
+func newRatSchema() *openapi3.Schema {
+	s := openapi3.NewStringSchema().WithPattern(ratPattern)
+	s.Example = "617/50"
+	return s
+}
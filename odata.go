@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ODataOpts selects the OData system query options that a route supports.
+type ODataOpts struct {
+	// Filter adds the $filter option, an expression that filters the results, e.g. "price lt 10".
+	Filter bool
+	// Select adds the $select option, a comma separated list of the properties to return.
+	Select bool
+	// OrderBy adds the $orderby option, a comma separated list of the properties to sort by.
+	OrderBy bool
+	// Top adds the $top option, the maximum number of results to return.
+	Top bool
+	// Skip adds the $skip option, the number of results to skip.
+	Skip bool
+}
+
+// Patterns of the OData query options, used to validate them.
+const (
+	oDataSelectPattern  = `^(\*|\w+(,\w+)*)$`
+	oDataOrderByPattern = `^\w+( (asc|desc))?(,\w+( (asc|desc))?)*$`
+)
+
+// HasODataQueryOptions adds the OData system query options selected by opts as optional
+// query parameters, e.g. /products?$filter=price lt 10&$orderby=name&$top=20.
+func (rm *Route) HasODataQueryOptions(opts ODataOpts) *Route {
+	if opts.Filter {
+		rm.HasQueryParameter("$filter", QueryParam{
+			Description: "Filters the results with an OData expression, e.g. price lt 10 and name eq 'Milk'.",
+			Type:        PrimitiveTypeString,
+		})
+	}
+	if opts.Select {
+		rm.HasQueryParameter("$select", QueryParam{
+			Description: "Comma separated list of the properties to return, or * for all properties.",
+			Type:        PrimitiveTypeString,
+			Regexp:      oDataSelectPattern,
+		})
+	}
+	if opts.OrderBy {
+		rm.HasQueryParameter("$orderby", QueryParam{
+			Description: "Comma separated list of the properties to sort the results by, each optionally followed by asc or desc.",
+			Type:        PrimitiveTypeString,
+			Regexp:      oDataOrderByPattern,
+		})
+	}
+	if opts.Top {
+		rm.HasQueryParameter("$top", QueryParam{
+			Description:       "The maximum number of results to return.",
+			Type:              PrimitiveTypeInteger,
+			ApplyCustomSchema: withMinimumZero,
+		})
+	}
+	if opts.Skip {
+		rm.HasQueryParameter("$skip", QueryParam{
+			Description:       "The number of results to skip.",
+			Type:              PrimitiveTypeInteger,
+			ApplyCustomSchema: withMinimumZero,
+		})
+	}
+	return rm
+}
+
+func withMinimumZero(p *openapi3.Parameter) {
+	p.Schema.Value.WithMin(0)
+}
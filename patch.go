@@ -0,0 +1,71 @@
+package rest
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Content types of patch requests.
+const (
+	JSONMergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType      = "application/json-patch+json"
+)
+
+// HasJSONMergePatchRequest sets the request model to a JSON Merge Patch (RFC 7396) of
+// the model, with the application/merge-patch+json content type. The properties of the
+// model are optional, since only the properties to change are sent, and nullable, since
+// null removes a property.
+func (rm *Route) HasJSONMergePatchRequest(model Model, opts ...RequestOpts) *Route {
+	model.contentType = JSONMergePatchContentType
+	model.opts = append(slices.Clip(model.opts), ModelOpts(withAllOptional))
+	return rm.HasRequestModel(model, opts...)
+}
+
+// HasJSONPatchRequest sets the request model to a JSON Patch (RFC 6902) document, an
+// array of operations, with the application/json-patch+json content type.
+func (rm *Route) HasJSONPatchRequest(opts ...RequestOpts) *Route {
+	model := ModelOf[[]JSONPatchOperation]()
+	model.contentType = JSONPatchContentType
+	model.opts = []SchemaOpts{ModelOpts(func(s *openapi3.Schema) {
+		s.Nullable = false
+	})}
+	return rm.HasRequestModel(model, opts...)
+}
+
+// JSONPatchOp is the operation of a JSON Patch.
+type JSONPatchOp string
+
+const (
+	JSONPatchOpAdd     JSONPatchOp = "add"
+	JSONPatchOpRemove  JSONPatchOp = "remove"
+	JSONPatchOpReplace JSONPatchOp = "replace"
+	JSONPatchOpMove    JSONPatchOp = "move"
+	JSONPatchOpCopy    JSONPatchOp = "copy"
+	JSONPatchOpTest    JSONPatchOp = "test"
+)
+
+// ApplyCustomSchema sets the allowed values.
+func (JSONPatchOp) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Enum = []any{JSONPatchOpAdd, JSONPatchOpRemove, JSONPatchOpReplace, JSONPatchOpMove, JSONPatchOpCopy, JSONPatchOpTest}
+}
+
+// JSONPatchOperation is an operation of a JSON Patch document.
+type JSONPatchOperation struct {
+	// Op is the operation to perform.
+	Op JSONPatchOp `json:"op"`
+	// Path is a JSON Pointer to the location to change, e.g. /name.
+	Path string `json:"path"`
+	// Value to add, replace or test.
+	Value json.RawMessage `json:"value,omitempty"`
+	// From is a JSON Pointer to the location to move or copy from.
+	From string `json:"from,omitempty"`
+}
+
+// ApplyCustomSchema allows any JSON value.
+func (JSONPatchOperation) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Properties["value"] = openapi3.NewSchemaRef("", &openapi3.Schema{
+		Description: "Value to add, replace or test.",
+	})
+}
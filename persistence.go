@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/heimspiel/rest/enums"
+)
+
+// WithSQLCModels documents nullable column types that marshal themselves as their value
+// or null, as nullable values of their underlying type, e.g. pgtype.Text in models that
+// sqlc generates for pgx, or a type that embeds sql.NullString and implements
+// MarshalJSON. They're recognised by their Valid field and MarshalJSON method. Pointers
+// to nullable columns are already nullable.
+//
+// The Null types of database/sql, e.g. sql.NullString, don't implement MarshalJSON, so
+// encoding/json writes them as objects, e.g. {"String":"a","Valid":true}, and they're
+// documented as such.
+func WithSQLCModels() APIOpts {
+	return func(api *API) {
+		api.SQLCModels = true
+	}
+}
+
+// WithEntModels adapts the entities generated by ent:
+//
+//   - The edges field, which is only set when edges are eagerly loaded, is omitted.
+//   - Fields of enum types generated by ent, e.g. user.Status, have the values of the
+//     constants of the type, or of its Values method.
+//
+// Optional and nillable fields are already handled by their json tags and pointers.
+func WithEntModels() APIOpts {
+	return func(api *API) {
+		api.EntModels = true
+	}
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// getNullValueType returns the type of the value of nullable types that marshal
+// themselves, e.g. string for pgtype.Text, which has String and Valid fields. The value
+// of types that embed a nullable type, e.g. sql.NullString, is the value of the
+// embedded type.
+func getNullValueType(t reflect.Type) (vt reflect.Type, ok bool) {
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return nil, false
+	}
+	if !t.Implements(jsonMarshalerType) && !reflect.PointerTo(t).Implements(jsonMarshalerType) {
+		return nil, false
+	}
+	if valid, ok := t.FieldByName("Valid"); !ok || valid.Type.Kind() != reflect.Bool {
+		return nil, false
+	}
+	f := t.Field(0)
+	for f.Anonymous && f.Type.Kind() == reflect.Struct && f.Type.NumField() > 0 {
+		f = f.Type.Field(0)
+	}
+	if f.Name == "Valid" {
+		return nil, false
+	}
+	return f.Type, true
+}
+
+// isEntEntity returns true if the type is an entity generated by ent, which has an Edges
+// field of the type named after the entity, e.g. UserEdges for User.
+func isEntEntity(t reflect.Type) bool {
+	f, ok := t.FieldByName("Edges")
+	return ok && isEntEdges(t, f)
+}
+
+// isEntEdges returns true if the field contains the edges of the ent entity t.
+func isEntEdges(t reflect.Type, f reflect.StructField) bool {
+	if f.Name != "Edges" || f.Type.Kind() != reflect.Struct || f.Type.Name() != t.Name()+"Edges" {
+		return false
+	}
+	_, ok := f.Type.FieldByName("loadedTypes")
+	return ok
+}
+
+// entEnum is implemented by enums with Go types in ent schemas.
+type entEnum interface {
+	Values() []string
+}
+
+// applyEntEnum sets the values of fields of ent enum types, which are string types
+// declared in the packages generated for each entity, e.g. user.Status.
+func applyEntEnum(t reflect.Type, f reflect.StructField, s *openapi3.Schema) error {
+	ft := f.Type
+	for ft.Kind() == reflect.Pointer {
+		ft = ft.Elem()
+	}
+	if ft.Kind() != reflect.String || ft.Name() == "" || ft.PkgPath() == "" || len(s.Enum) > 0 {
+		return nil
+	}
+	if e, ok := reflect.Zero(ft).Interface().(entEnum); ok {
+		for _, v := range e.Values() {
+			s.Enum = append(s.Enum, v)
+		}
+		return nil
+	}
+	// Enums generated by ent are in the package of the entity, e.g. ent/user for ent.User.
+	if !strings.HasPrefix(ft.PkgPath(), t.PkgPath()+"/") {
+		return nil
+	}
+	constants, err := enums.GetConstants(ft)
+	if err != nil {
+		return err
+	}
+	for _, c := range constants {
+		s.Enum = append(s.Enum, c.Value)
+	}
+	return nil
+}
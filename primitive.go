@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+var primitiveTypes = struct {
+	sync.RWMutex
+	m map[PrimitiveType]func() *openapi3.Schema
+}{
+	m: map[PrimitiveType]func() *openapi3.Schema{},
+}
+
+// RegisterPrimitiveType registers a PrimitiveType that parameters can use, in addition
+// to string, boolean, integer and number, e.g.
+//
+//	rest.RegisterPrimitiveType("uuid", func() *openapi3.Schema {
+//		return openapi3.NewUUIDSchema()
+//	})
+//
+// The function is called for each parameter, so it must return a new schema every time.
+// It panics if the type is already registered, or is one of the built-in types.
+func RegisterPrimitiveType(name PrimitiveType, newSchema func() *openapi3.Schema) {
+	if newSchema == nil {
+		panic("rest: RegisterPrimitiveType newSchema is nil")
+	}
+	primitiveTypes.Lock()
+	defer primitiveTypes.Unlock()
+	if _, ok := primitiveTypes.m[name]; ok || isBuiltInPrimitiveType(name) {
+		panic(fmt.Sprintf("rest: primitive type %q is already registered", name))
+	}
+	primitiveTypes.m[name] = newSchema
+}
+
+func isBuiltInPrimitiveType(name PrimitiveType) bool {
+	switch name {
+	case "", PrimitiveTypeString, PrimitiveTypeBool, PrimitiveTypeInteger, PrimitiveTypeFloat64:
+		return true
+	}
+	return false
+}
+
+// getRegisteredPrimitiveSchema returns the schema of a type added with
+// RegisterPrimitiveType.
+func getRegisteredPrimitiveSchema(name PrimitiveType) (s *openapi3.Schema, ok bool) {
+	primitiveTypes.RLock()
+	defer primitiveTypes.RUnlock()
+	newSchema, ok := primitiveTypes.m[name]
+	if !ok {
+		return nil, false
+	}
+	return newSchema(), true
+}
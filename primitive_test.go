@@ -0,0 +1,42 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+const (
+	primitiveTypeUUID PrimitiveType = "guid"
+	primitiveTypeDate PrimitiveType = "date"
+)
+
+// Types can only be registered once, so they're registered for all tests.
+func init() {
+	RegisterPrimitiveType(primitiveTypeUUID, openapi3.NewUUIDSchema)
+	RegisterPrimitiveType(primitiveTypeDate, func() *openapi3.Schema {
+		s := openapi3.NewStringSchema().WithFormat("date")
+		s.Example = "2024-01-31"
+		return s
+	})
+}
+
+func TestRegisterPrimitiveTypePanics(t *testing.T) {
+	tests := []struct {
+		name      string
+		primitive PrimitiveType
+	}{
+		{name: "registered twice", primitive: primitiveTypeUUID},
+		{name: "built-in", primitive: PrimitiveTypeInteger},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected a panic")
+				}
+			}()
+			RegisterPrimitiveType(test.primitive, openapi3.NewStringSchema)
+		})
+	}
+}
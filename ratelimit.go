@@ -0,0 +1,87 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// rateLimitExtension is the operation extension that describes the rate limit of the route.
+const rateLimitExtension = "x-rate-limit"
+
+// RateLimit is the number of requests that a client can make to a route.
+type RateLimit struct {
+	// Limit is the number of requests allowed in each window.
+	Limit int
+	// Window is the period that the limit applies to.
+	Window time.Duration
+	// Burst is the number of requests that can be made at once, if the limiter
+	// allows bursts. Zero means that bursts are not documented.
+	Burst int
+}
+
+// HasRateLimit documents the rate limit of the route. The RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers are added to the route's responses,
+// a 429 response is added if the route doesn't have one, and the limit is added to
+// the operation as the x-rate-limit extension, with the window in seconds.
+func (rm *Route) HasRateLimit(rl RateLimit) *Route {
+	rm.RateLimit = &rl
+	ext := map[string]any{
+		"limit":  rl.Limit,
+		"window": int(rl.Window.Seconds()),
+	}
+	if rl.Burst > 0 {
+		ext["burst"] = rl.Burst
+	}
+	return rm.HasExtension(rateLimitExtension, ext)
+}
+
+// RateLimits returns the rate limits of the API's routes, keyed by operation, e.g.
+// "GET /users/{id}", so that a rate limiting middleware can be configured from
+// the routes.
+func (api *API) RateLimits() (limits map[string]RateLimit) {
+	limits = make(map[string]RateLimit)
+	for _, methodToRoute := range api.Routes {
+		for _, route := range methodToRoute {
+			if route.RateLimit != nil {
+				limits[getOperation(route)] = *route.RateLimit
+			}
+		}
+	}
+	return limits
+}
+
+// addRateLimitHeaders documents the rate limit of the route on the operation's responses.
+func addRateLimitHeaders(op *openapi3.Operation, rl RateLimit) {
+	if op.Responses.Status(http.StatusTooManyRequests) == nil {
+		op.AddResponse(http.StatusTooManyRequests, openapi3.NewResponse().
+			WithDescription("Too many requests. The limit is "+strconv.Itoa(rl.Limit)+" requests every "+rl.Window.String()+"."))
+		op.Responses.Status(http.StatusTooManyRequests).Value.Headers = openapi3.Headers{
+			"Retry-After": newHeader("The number of seconds to wait before making another request.", openapi3.NewIntegerSchema()),
+		}
+	}
+	for key, status := range op.Responses.Map() {
+		if status.Value == nil || key == "default" {
+			continue
+		}
+		if status.Value.Headers == nil {
+			status.Value.Headers = make(openapi3.Headers)
+		}
+		status.Value.Headers["RateLimit-Limit"] = newHeader("The number of requests allowed in the window.", openapi3.NewIntegerSchema())
+		status.Value.Headers["RateLimit-Remaining"] = newHeader("The number of requests remaining in the current window.", openapi3.NewIntegerSchema())
+		status.Value.Headers["RateLimit-Reset"] = newHeader("The number of seconds until the current window resets.", openapi3.NewIntegerSchema())
+	}
+}
+
+func newHeader(description string, schema *openapi3.Schema) *openapi3.HeaderRef {
+	return &openapi3.HeaderRef{
+		Value: &openapi3.Header{
+			Parameter: openapi3.Parameter{
+				Description: description,
+				Schema:      openapi3.NewSchemaRef("", schema),
+			},
+		},
+	}
+}
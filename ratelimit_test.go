@@ -0,0 +1,24 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRateLimits(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/users").HasRateLimit(RateLimit{Limit: 100, Window: time.Minute})
+	api.Post("/users").HasRateLimit(RateLimit{Limit: 10, Window: time.Second, Burst: 5})
+	api.Get("/health").HasResponseModel(http.StatusOK, ModelOf[OK]())
+
+	expected := map[string]RateLimit{
+		"GET /users":  {Limit: 100, Window: time.Minute},
+		"POST /users": {Limit: 10, Window: time.Second, Burst: 5},
+	}
+	if diff := cmp.Diff(expected, api.RateLimits()); diff != "" {
+		t.Error(diff)
+	}
+}
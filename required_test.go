@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+)
+
+type WithInferredRequired struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name,omitempty"`
+	Nickname *string `json:"nickname"`
+}
+
+type WithManualRequired struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name,omitempty"`
+	Nickname *string `json:"nickname"`
+}
+
+func (WithManualRequired) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Required = []string{"id", "name"}
+}
+
+func TestRequiredFieldsAreInferred(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []APIOpts
+		model    Model
+		expected []string
+	}{
+		{
+			name:     "inferred from pointers and omitempty",
+			model:    ModelOf[WithInferredRequired](),
+			expected: []string{"id"},
+		},
+		{
+			name:     "inferred from omitempty",
+			opts:     []APIOpts{WithOptionalityPolicy(OptionalityOmitEmpty)},
+			model:    ModelOf[WithInferredRequired](),
+			expected: []string{"id", "nickname"},
+		},
+		{
+			name:     "ApplyCustomSchema keeps manual control",
+			model:    ModelOf[WithManualRequired](),
+			expected: []string{"id", "name"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("test", test.opts...)
+			_, schema, err := api.RegisterModel(test.model)
+			if err != nil {
+				t.Fatalf("failed to register model: %v", err)
+			}
+			if diff := cmp.Diff(test.expected, schema.Required); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
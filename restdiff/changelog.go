@@ -0,0 +1,120 @@
+package restdiff
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Group of changes in a changelog.
+type Group struct {
+	// Name of the group, which is the first tag of the operations, or their path if they
+	// have no tags.
+	Name    string
+	Changes []Change
+}
+
+// GroupChanges groups the changes by the first tag of their operation, or by path if the
+// operation has no tags. The groups are sorted by name, and the order of the changes is
+// kept.
+func GroupChanges(changes []Change) (groups []Group) {
+	for _, c := range changes {
+		name := getGroupName(c)
+		i := slices.IndexFunc(groups, func(g Group) bool { return g.Name == name })
+		if i < 0 {
+			groups = append(groups, Group{Name: name})
+			i = len(groups) - 1
+		}
+		groups[i].Changes = append(groups[i].Changes, c)
+	}
+	slices.SortStableFunc(groups, func(a, b Group) int { return strings.Compare(a.Name, b.Name) })
+	return groups
+}
+
+func getGroupName(c Change) string {
+	if len(c.Tags) > 0 {
+		return c.Tags[0]
+	}
+	_, path, _ := strings.Cut(c.Operation, " ")
+	return path
+}
+
+// Renderer writes a changelog, e.g. as Markdown for release notes.
+type Renderer interface {
+	Render(w io.Writer, groups []Group) error
+}
+
+// Changelog renders the changes between the specifications, grouped by tag or path.
+func Changelog(old, new *openapi3.T, r Renderer) (string, error) {
+	var sb strings.Builder
+	if err := r.Render(&sb, GroupChanges(Diff(old, new))); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// MarkdownRenderer renders a section for each group, with the changes listed under
+// Added, Changed, Deprecated and Removed headings. Breaking changes are marked in bold.
+var MarkdownRenderer Renderer = markdownRenderer{}
+
+type markdownRenderer struct{}
+
+// changelogSections are the headings of the changes in each group, in order.
+var changelogSections = []struct {
+	heading string
+	t       ChangeType
+}{
+	{"Added", Added},
+	{"Changed", Modified},
+	{"Deprecated", Deprecated},
+	{"Removed", Removed},
+}
+
+func (markdownRenderer) Render(w io.Writer, groups []Group) error {
+	if len(groups) == 0 {
+		_, err := io.WriteString(w, "No changes.\n")
+		return err
+	}
+	for i, g := range groups {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "## %s\n", g.Name); err != nil {
+			return err
+		}
+		for _, section := range changelogSections {
+			changes := slices.DeleteFunc(slices.Clone(g.Changes), func(c Change) bool { return c.Type != section.t })
+			if len(changes) == 0 {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "\n### %s\n\n", section.heading); err != nil {
+				return err
+			}
+			for _, c := range changes {
+				if _, err := io.WriteString(w, formatMarkdownChange(c)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func formatMarkdownChange(c Change) string {
+	var sb strings.Builder
+	sb.WriteString("- ")
+	if c.Severity == Breaking {
+		sb.WriteString("**Breaking:** ")
+	}
+	fmt.Fprintf(&sb, "`%s`", c.Operation)
+	if c.Location != "" {
+		fmt.Fprintf(&sb, " `%s`", c.Location)
+	}
+	fmt.Fprintf(&sb, ": %s\n", c.Description)
+	return sb.String()
+}
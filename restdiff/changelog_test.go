@@ -0,0 +1,58 @@
+package restdiff_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/heimspiel/rest/restdiff"
+)
+
+func TestChangelog(t *testing.T) {
+	old, new := newSpecs(t)
+	changelog, err := restdiff.Changelog(old, new, restdiff.MarkdownRenderer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "## /status\n" +
+		"\n" +
+		"### Removed\n" +
+		"\n" +
+		"- **Breaking:** `GET /status`: operation removed\n" +
+		"\n" +
+		"## users\n" +
+		"\n" +
+		"### Added\n" +
+		"\n" +
+		"- `GET /users` `query.team`: parameter added\n" +
+		"- `GET /users` `response.200[].team`: property added\n" +
+		"- **Breaking:** `POST /users` `request.team`: required property added\n" +
+		"- `POST /users` `response.201.team`: property added\n" +
+		"- `DELETE /users/{id}`: operation added\n" +
+		"\n" +
+		"### Changed\n" +
+		"\n" +
+		"- **Breaking:** `GET /users` `response.200[].email`: property made optional\n" +
+		"- **Breaking:** `GET /users` `response.200[].email`: made nullable\n" +
+		"- **Breaking:** `GET /users` `response.200[].role`: enum values added: guest\n" +
+		"- **Breaking:** `POST /users` `response.201.email`: property made optional\n" +
+		"- **Breaking:** `POST /users` `response.201.email`: made nullable\n" +
+		"- **Breaking:** `POST /users` `response.201.role`: enum values added: guest\n" +
+		"\n" +
+		"### Removed\n" +
+		"\n" +
+		"- **Breaking:** `POST /users` `request.nickname`: property removed\n"
+	if diff := cmp.Diff(expected, changelog); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestChangelogWithoutChanges(t *testing.T) {
+	old, _ := newSpecs(t)
+	changelog, err := restdiff.Changelog(old, old, restdiff.MarkdownRenderer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changelog != "No changes.\n" {
+		t.Errorf("unexpected changelog: %q", changelog)
+	}
+}
@@ -0,0 +1,382 @@
+// Package restdiff compares two versions of an OpenAPI specification, e.g. to review
+// the changes to an API before it's released.
+package restdiff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Severity of a change, which decides how the version of the API changes.
+type Severity string
+
+const (
+	// Breaking changes can break existing clients, e.g. removing an operation, or adding
+	// a required property to a request.
+	Breaking Severity = "breaking"
+	// Additive changes add to the API without breaking existing clients, e.g. adding an
+	// operation, or adding a property to a response.
+	Additive Severity = "additive"
+	// Patch changes don't change the behaviour of the API, e.g. changing a description.
+	Patch Severity = "patch"
+)
+
+// ChangeType is what happened to the part of the API that changed.
+type ChangeType string
+
+const (
+	// Added is an operation, parameter, property or response that's new.
+	Added ChangeType = "added"
+	// Modified is a change to something that exists in both versions.
+	Modified ChangeType = "modified"
+	// Deprecated is something that has been marked as deprecated.
+	Deprecated ChangeType = "deprecated"
+	// Removed is something that no longer exists.
+	Removed ChangeType = "removed"
+)
+
+// Change is a difference between two versions of a specification.
+type Change struct {
+	// Operation that changed, e.g. "GET /users".
+	Operation string
+	// Tags of the operation, taken from the new version unless it was removed.
+	Tags []string
+	// Location of the change within the operation, e.g. "request.address.country",
+	// "response.200.items[].id" or "query.limit". Empty if the operation itself changed.
+	Location string
+	// Type of the change.
+	Type ChangeType
+	// Severity of the change.
+	Severity Severity
+	// Description of the change, e.g. "required property added".
+	Description string
+}
+
+func (c Change) String() string {
+	if c.Location == "" {
+		return fmt.Sprintf("%s: %s (%s)", c.Operation, c.Description, c.Severity)
+	}
+	return fmt.Sprintf("%s %s: %s (%s)", c.Operation, c.Location, c.Description, c.Severity)
+}
+
+// Diff returns the changes to the operations of the specification, and the schemas
+// they use, in order of path and method.
+//
+// Whether a change to a schema is breaking depends on where it's used. Clients send
+// requests, so making a request property required is breaking, whereas clients read
+// responses, so adding a value to the enum of a response property is breaking.
+func Diff(old, new *openapi3.T) []Change {
+	d := &differ{old: old, new: new}
+	for _, path := range getKeys(getPaths(old), getPaths(new)) {
+		oldItem, newItem := getPathItem(old, path), getPathItem(new, path)
+		for _, method := range getKeys(oldItem.Operations(), newItem.Operations()) {
+			oldOp, newOp := oldItem.GetOperation(method), newItem.GetOperation(method)
+			d.operation = method + " " + path
+			switch {
+			case oldOp == nil:
+				d.tags = newOp.Tags
+				d.add("", Added, Additive, "operation added")
+			case newOp == nil:
+				d.tags = oldOp.Tags
+				d.add("", Removed, Breaking, "operation removed")
+			default:
+				d.tags = newOp.Tags
+				d.diffOperation(oldOp, newOp)
+			}
+		}
+	}
+	return d.changes
+}
+
+type direction int
+
+const (
+	request direction = iota
+	response
+)
+
+type differ struct {
+	old, new  *openapi3.T
+	operation string
+	tags      []string
+	// visited contains the pairs of references that have been compared, so that
+	// recursive schemas terminate.
+	visited map[string]bool
+	changes []Change
+}
+
+func (d *differ) add(location string, t ChangeType, severity Severity, format string, a ...any) {
+	c := Change{
+		Operation:   d.operation,
+		Tags:        d.tags,
+		Location:    location,
+		Type:        t,
+		Severity:    severity,
+		Description: fmt.Sprintf(format, a...),
+	}
+	// The same schema is often used by more than one content type.
+	if !slices.ContainsFunc(d.changes, func(e Change) bool {
+		return e.Operation == c.Operation && e.Location == c.Location && e.Description == c.Description
+	}) {
+		d.changes = append(d.changes, c)
+	}
+}
+
+func (d *differ) diffOperation(old, new *openapi3.Operation) {
+	if !old.Deprecated && new.Deprecated {
+		d.add("", Deprecated, Additive, "operation deprecated")
+	}
+	if old.Summary != new.Summary || old.Description != new.Description {
+		d.add("", Modified, Patch, "description changed")
+	}
+	d.diffParameters(old.Parameters, new.Parameters)
+	d.diffRequestBody(old.RequestBody, new.RequestBody)
+	d.diffResponses(old.Responses, new.Responses)
+}
+
+func (d *differ) diffParameters(old, new openapi3.Parameters) {
+	oldParams, newParams := getParameters(old), getParameters(new)
+	for _, key := range getKeys(oldParams, newParams) {
+		o, n := oldParams[key], newParams[key]
+		switch {
+		case o == nil && n.Required:
+			d.add(key, Added, Breaking, "required parameter added")
+		case o == nil:
+			d.add(key, Added, Additive, "parameter added")
+		case n == nil:
+			d.add(key, Removed, Breaking, "parameter removed")
+		default:
+			if !o.Required && n.Required {
+				d.add(key, Modified, Breaking, "parameter made required")
+			}
+			if o.Required && !n.Required {
+				d.add(key, Modified, Additive, "parameter made optional")
+			}
+			if !o.Deprecated && n.Deprecated {
+				d.add(key, Deprecated, Additive, "parameter deprecated")
+			}
+			if o.Description != n.Description {
+				d.add(key, Modified, Patch, "description changed")
+			}
+			d.diffSchema(request, key, o.Schema, n.Schema)
+		}
+	}
+}
+
+// getParameters returns the parameters by location and name, e.g. "query.limit".
+func getParameters(params openapi3.Parameters) map[string]*openapi3.Parameter {
+	m := make(map[string]*openapi3.Parameter, len(params))
+	for _, p := range params {
+		if p.Value != nil {
+			m[p.Value.In+"."+p.Value.Name] = p.Value
+		}
+	}
+	return m
+}
+
+func (d *differ) diffRequestBody(old, new *openapi3.RequestBodyRef) {
+	const location = "request"
+	o, n := getRequestBody(old), getRequestBody(new)
+	switch {
+	case o == nil && n == nil:
+	case o == nil && n.Required:
+		d.add(location, Added, Breaking, "required request body added")
+	case o == nil:
+		d.add(location, Added, Additive, "request body added")
+	case n == nil:
+		d.add(location, Removed, Breaking, "request body removed")
+	default:
+		if !o.Required && n.Required {
+			d.add(location, Modified, Breaking, "request body made required")
+		}
+		d.diffContent(request, location, o.Content, n.Content)
+	}
+}
+
+func getRequestBody(body *openapi3.RequestBodyRef) *openapi3.RequestBody {
+	if body == nil {
+		return nil
+	}
+	return body.Value
+}
+
+func (d *differ) diffResponses(old, new *openapi3.Responses) {
+	oldResponses, newResponses := old.Map(), new.Map()
+	for _, status := range getKeys(oldResponses, newResponses) {
+		location := "response." + status
+		o, n := oldResponses[status], newResponses[status]
+		switch {
+		case o == nil || o.Value == nil:
+			d.add(location, Added, Additive, "response added")
+		case n == nil || n.Value == nil:
+			d.add(location, Removed, Breaking, "response removed")
+		default:
+			d.diffContent(response, location, o.Value.Content, n.Value.Content)
+		}
+	}
+}
+
+func (d *differ) diffContent(dir direction, location string, old, new openapi3.Content) {
+	for _, contentType := range getKeys(old, new) {
+		o, n := old[contentType], new[contentType]
+		switch {
+		case o == nil:
+			d.add(location, Added, Additive, "content type %s added", contentType)
+		case n == nil:
+			d.add(location, Removed, Breaking, "content type %s removed", contentType)
+		default:
+			d.diffSchema(dir, location, o.Schema, n.Schema)
+		}
+	}
+}
+
+func (d *differ) diffSchema(dir direction, location string, old, new *openapi3.SchemaRef) {
+	d.visited = make(map[string]bool)
+	d.compareSchemas(dir, location, old, new)
+}
+
+func (d *differ) compareSchemas(dir direction, location string, oldRef, newRef *openapi3.SchemaRef) {
+	if oldRef == nil || newRef == nil {
+		return
+	}
+	if oldRef.Ref != "" && newRef.Ref != "" {
+		// Each pair of references is compared once, wherever it's used, since
+		// recursive schemas would otherwise be compared at ever longer locations.
+		key := oldRef.Ref + "|" + newRef.Ref
+		if d.visited[key] {
+			return
+		}
+		d.visited[key] = true
+	}
+	o, n := resolve(d.old, oldRef), resolve(d.new, newRef)
+	if o == nil || n == nil {
+		return
+	}
+	if oldType, newType := getType(o), getType(n); oldType != newType {
+		d.add(location, Modified, Breaking, "type changed from %s to %s", oldType, newType)
+		return
+	}
+	if o.Format != n.Format {
+		d.add(location, Modified, Breaking, "format changed from %q to %q", o.Format, n.Format)
+	}
+	// Clients can send more in requests, and must handle more in responses.
+	loosened, tightened := Additive, Breaking
+	if dir == response {
+		loosened, tightened = Breaking, Additive
+	}
+	if !o.Nullable && n.Nullable {
+		d.add(location, Modified, loosened, "made nullable")
+	}
+	if o.Nullable && !n.Nullable {
+		d.add(location, Modified, tightened, "made non-nullable")
+	}
+	if len(o.Enum) > 0 || len(n.Enum) > 0 {
+		if added := getMissingValues(n.Enum, o.Enum); len(added) > 0 {
+			severity := loosened
+			if len(o.Enum) == 0 {
+				severity = tightened
+			}
+			d.add(location, Modified, severity, "enum values added: %s", strings.Join(added, ", "))
+		}
+		if removed := getMissingValues(o.Enum, n.Enum); len(removed) > 0 {
+			severity := tightened
+			if len(n.Enum) == 0 {
+				severity = loosened
+			}
+			d.add(location, Modified, severity, "enum values removed: %s", strings.Join(removed, ", "))
+		}
+	}
+	if !o.Deprecated && n.Deprecated {
+		d.add(location, Deprecated, Additive, "deprecated")
+	}
+	if o.Description != n.Description {
+		d.add(location, Modified, Patch, "description changed")
+	}
+	for _, name := range getKeys(o.Properties, n.Properties) {
+		propertyLocation := location + "." + name
+		op, np := o.Properties[name], n.Properties[name]
+		isRequired := slices.Contains(n.Required, name)
+		wasRequired := slices.Contains(o.Required, name)
+		switch {
+		case op == nil && isRequired && dir == request:
+			d.add(propertyLocation, Added, Breaking, "required property added")
+		case op == nil:
+			d.add(propertyLocation, Added, Additive, "property added")
+		case np == nil:
+			d.add(propertyLocation, Removed, Breaking, "property removed")
+		default:
+			if !wasRequired && isRequired {
+				d.add(propertyLocation, Modified, tightened, "property made required")
+			}
+			if wasRequired && !isRequired {
+				d.add(propertyLocation, Modified, loosened, "property made optional")
+			}
+			d.compareSchemas(dir, propertyLocation, op, np)
+		}
+	}
+	d.compareSchemas(dir, location+"[]", o.Items, n.Items)
+	for i := 0; i < len(o.AllOf) && i < len(n.AllOf); i++ {
+		d.compareSchemas(dir, location, o.AllOf[i], n.AllOf[i])
+	}
+}
+
+// resolve returns the schema of the reference, looking it up in the components of the
+// specification if the reference hasn't been resolved.
+func resolve(spec *openapi3.T, ref *openapi3.SchemaRef) *openapi3.Schema {
+	if ref.Value != nil || ref.Ref == "" {
+		return ref.Value
+	}
+	if spec.Components == nil {
+		return nil
+	}
+	if s := spec.Components.Schemas[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]; s != nil {
+		return s.Value
+	}
+	return nil
+}
+
+func getType(s *openapi3.Schema) string {
+	if s.Type == nil {
+		return "any"
+	}
+	return strings.Join(s.Type.Slice(), ",")
+}
+
+// getMissingValues returns the values of a that aren't in b.
+func getMissingValues(a, b []any) (missing []string) {
+	for _, v := range a {
+		if !slices.ContainsFunc(b, func(w any) bool { return fmt.Sprint(v) == fmt.Sprint(w) }) {
+			missing = append(missing, fmt.Sprint(v))
+		}
+	}
+	return missing
+}
+
+func getPaths(spec *openapi3.T) map[string]*openapi3.PathItem {
+	return spec.Paths.Map()
+}
+
+func getPathItem(spec *openapi3.T, path string) *openapi3.PathItem {
+	if item := getPaths(spec)[path]; item != nil {
+		return item
+	}
+	return &openapi3.PathItem{}
+}
+
+// getKeys returns the keys of both maps, sorted.
+func getKeys[V1, V2 any](a map[string]V1, b map[string]V2) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,148 @@
+package restdiff_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/restdiff"
+)
+
+type UserV1 struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+func (UserV1) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Properties["role"].Value.WithEnum("admin", "member")
+}
+
+type CreateUserV1 struct {
+	Name     string  `json:"name"`
+	Nickname *string `json:"nickname,omitempty"`
+}
+
+type UserV2 struct {
+	Name  string  `json:"name"`
+	Email *string `json:"email,omitempty"`
+	Role  string  `json:"role"`
+	Team  string  `json:"team"`
+}
+
+func (UserV2) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Properties["role"].Value.WithEnum("admin", "member", "guest")
+}
+
+type CreateUserV2 struct {
+	Name string `json:"name"`
+	Team string `json:"team"`
+}
+
+func newSpecs(t *testing.T) (old, new *openapi3.T) {
+	t.Helper()
+	v1 := rest.NewAPI("users")
+	v1.Get("/users").
+		HasTags([]string{"users"}).
+		HasResponseModel(http.StatusOK, rest.ModelOf[[]UserV1]())
+	v1.Post("/users").
+		HasTags([]string{"users"}).
+		HasRequestModel(rest.ModelOf[CreateUserV1]()).
+		HasResponseModel(http.StatusCreated, rest.ModelOf[UserV1]())
+	v1.Get("/status").
+		HasResponseModel(http.StatusOK, rest.ModelOf[string]())
+
+	v2 := rest.NewAPI("users")
+	v2.Get("/users").
+		HasTags([]string{"users"}).
+		HasQueryParameter("team", rest.QueryParam{Description: "Filter by team."}).
+		HasResponseModel(http.StatusOK, rest.ModelOf[[]UserV2]())
+	v2.Post("/users").
+		HasTags([]string{"users"}).
+		HasRequestModel(rest.ModelOf[CreateUserV2]()).
+		HasResponseModel(http.StatusCreated, rest.ModelOf[UserV2]())
+	v2.Delete("/users/{id}").
+		HasTags([]string{"users"}).
+		HasPathParameter("id", rest.PathParam{}).
+		HasResponseModel(http.StatusNoContent, rest.ModelOf[struct{}]())
+
+	var err error
+	if old, err = v1.Spec(); err != nil {
+		t.Fatalf("failed to create old spec: %v", err)
+	}
+	if new, err = v2.Spec(); err != nil {
+		t.Fatalf("failed to create new spec: %v", err)
+	}
+	return old, new
+}
+
+func TestDiff(t *testing.T) {
+	old, new := newSpecs(t)
+	var actual []string
+	for _, c := range restdiff.Diff(old, new) {
+		actual = append(actual, c.String())
+	}
+	expected := []string{
+		"GET /status: operation removed (breaking)",
+		"GET /users query.team: parameter added (additive)",
+		"GET /users response.200[].email: property made optional (breaking)",
+		"GET /users response.200[].email: made nullable (breaking)",
+		"GET /users response.200[].role: enum values added: guest (breaking)",
+		"GET /users response.200[].team: property added (additive)",
+		"POST /users request.nickname: property removed (breaking)",
+		"POST /users request.team: required property added (breaking)",
+		"POST /users response.201.email: property made optional (breaking)",
+		"POST /users response.201.email: made nullable (breaking)",
+		"POST /users response.201.role: enum values added: guest (breaking)",
+		"POST /users response.201.team: property added (additive)",
+		"DELETE /users/{id}: operation added (additive)",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestDiffOfTheSameSpec(t *testing.T) {
+	old, _ := newSpecs(t)
+	if changes := restdiff.Diff(old, old); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+}
+
+type NodeV1 struct {
+	Name   string  `json:"name"`
+	Parent *NodeV1 `json:"parent"`
+}
+
+type NodeV2 struct {
+	Name     string    `json:"name"`
+	Parent   *NodeV2   `json:"parent"`
+	Children []*NodeV2 `json:"children"`
+}
+
+func TestDiffOfRecursiveModels(t *testing.T) {
+	v1 := rest.NewAPI("nodes")
+	v1.Get("/nodes").HasResponseModel(http.StatusOK, rest.ModelOf[NodeV1]())
+	v2 := rest.NewAPI("nodes")
+	v2.Get("/nodes").HasResponseModel(http.StatusOK, rest.ModelOf[NodeV2]())
+	old, err := v1.Spec()
+	if err != nil {
+		t.Fatalf("failed to create old spec: %v", err)
+	}
+	new, err := v2.Spec()
+	if err != nil {
+		t.Fatalf("failed to create new spec: %v", err)
+	}
+	var actual []string
+	for _, c := range restdiff.Diff(old, new) {
+		actual = append(actual, c.String())
+	}
+	expected := []string{
+		"GET /nodes response.200.children: property added (additive)",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,70 @@
+package restdiff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SuggestVersion returns the semantic version that follows the current version, given
+// the changes between the specifications: breaking changes increment the major version,
+// additive changes the minor version, and other changes the patch version. If there are
+// no changes, the current version is returned.
+//
+// While the major version is 0, breaking changes increment the minor version, since the
+// API isn't considered stable yet. A "v" prefix is kept, and pre-release and build
+// suffixes are dropped before the version is incremented.
+func SuggestVersion(old, new *openapi3.T, current string) (string, error) {
+	prefix, v := "", current
+	if strings.HasPrefix(v, "v") {
+		prefix, v = "v", v[1:]
+	}
+	v, _, _ = strings.Cut(v, "+")
+	v, _, _ = strings.Cut(v, "-")
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid semantic version %q", current)
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid semantic version %q", current)
+		}
+		numbers[i] = n
+	}
+	major, minor, patch := numbers[0], numbers[1], numbers[2]
+	switch getHighestSeverity(Diff(old, new)) {
+	case "":
+		return current, nil
+	case Breaking:
+		if major == 0 {
+			minor, patch = minor+1, 0
+			break
+		}
+		major, minor, patch = major+1, 0, 0
+	case Additive:
+		minor, patch = minor+1, 0
+	case Patch:
+		patch++
+	}
+	return fmt.Sprintf("%s%d.%d.%d", prefix, major, minor, patch), nil
+}
+
+// getHighestSeverity returns the most severe of the changes, or an empty string if
+// there are none.
+func getHighestSeverity(changes []Change) (highest Severity) {
+	for _, c := range changes {
+		switch {
+		case c.Severity == Breaking:
+			return Breaking
+		case c.Severity == Additive:
+			highest = Additive
+		case highest == "":
+			highest = c.Severity
+		}
+	}
+	return highest
+}
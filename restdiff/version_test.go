@@ -0,0 +1,68 @@
+package restdiff_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/restdiff"
+)
+
+func newStatusSpec(t *testing.T, configure func(api *rest.API)) *openapi3.T {
+	t.Helper()
+	api := rest.NewAPI("status")
+	api.Get("/status").
+		HasResponseModel(http.StatusOK, rest.ModelOf[string]())
+	configure(api)
+	spec, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	return spec
+}
+
+func TestSuggestVersion(t *testing.T) {
+	old, breaking := newSpecs(t)
+	base := newStatusSpec(t, func(api *rest.API) {})
+	additive := newStatusSpec(t, func(api *rest.API) {
+		api.Get("/health").HasResponseModel(http.StatusOK, rest.ModelOf[string]())
+	})
+	patch := newStatusSpec(t, func(api *rest.API) {
+		api.Routes["/status"][http.MethodGet].HasDescription("Returns the status of the service.")
+	})
+	tests := []struct {
+		name     string
+		old, new *openapi3.T
+		current  string
+		expected string
+	}{
+		{name: "no changes", old: base, new: base, current: "1.2.3", expected: "1.2.3"},
+		{name: "patch", old: base, new: patch, current: "1.2.3", expected: "1.2.4"},
+		{name: "additive", old: base, new: additive, current: "1.2.3", expected: "1.3.0"},
+		{name: "breaking", old: old, new: breaking, current: "1.2.3", expected: "2.0.0"},
+		{name: "breaking before 1.0.0", old: old, new: breaking, current: "0.2.3", expected: "0.3.0"},
+		{name: "prefix", old: base, new: additive, current: "v1.2.3", expected: "v1.3.0"},
+		{name: "pre-release", old: base, new: patch, current: "1.2.3-rc.1+build.5", expected: "1.2.4"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := restdiff.SuggestVersion(test.old, test.new, test.current)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if actual != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSuggestVersionInvalid(t *testing.T) {
+	spec := newStatusSpec(t, func(api *rest.API) {})
+	for _, current := range []string{"", "1.2", "1.2.x", "latest"} {
+		if _, err := restdiff.SuggestVersion(spec, spec, current); err == nil {
+			t.Errorf("expected an error for %q", current)
+		}
+	}
+}
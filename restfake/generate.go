@@ -0,0 +1,283 @@
+// Package restfake generates fake instances of models, which are valid according to
+// their OpenAPI schemas, e.g. to seed tests or to return from a mock server.
+package restfake
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/heimspiel/rest"
+)
+
+// maxDepth limits the nesting of generated values, so that recursive types terminate.
+const maxDepth = 5
+
+// Options configure the generator.
+type Options func(g *Generator)
+
+// WithSeed makes the generated values deterministic.
+func WithSeed(seed uint64) Options {
+	return func(g *Generator) {
+		g.rand = rand.New(rand.NewPCG(seed, seed))
+	}
+}
+
+// WithAPI generates models using the configuration of the API, e.g. its KnownTypes.
+func WithAPI(api *rest.API) Options {
+	return func(g *Generator) {
+		g.api = api
+	}
+}
+
+// Generator creates fake values from schemas.
+type Generator struct {
+	api  *rest.API
+	rand *rand.Rand
+}
+
+// New creates a Generator. Unless WithSeed is used, each Generator produces different values.
+func New(opts ...Options) *Generator {
+	g := &Generator{}
+	for _, o := range opts {
+		o(g)
+	}
+	if g.api == nil {
+		g.api = rest.NewAPI("restfake")
+	}
+	if g.rand == nil {
+		g.rand = rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+	}
+	return g
+}
+
+// Generate creates a fake value of the model, as it would be unmarshalled from JSON,
+// i.e. a map[string]any, []any, string, float64, bool or nil. The value respects the
+// enums, minimums, maximums, lengths, patterns and formats of the model's schema.
+func Generate(model rest.Model, opts ...Options) (v any, err error) {
+	return New(opts...).Generate(model)
+}
+
+// Generate creates a fake value of the model, see Generate.
+func (g *Generator) Generate(model rest.Model) (v any, err error) {
+	_, schema, err := g.api.RegisterModel(model)
+	if err != nil {
+		return nil, err
+	}
+	models, err := g.api.Models()
+	if err != nil {
+		return nil, err
+	}
+	return g.Schema(openapi3.NewSchemaRef("", schema), models)
+}
+
+// Schema creates a fake value of the schema. References to components are resolved
+// using the models, see rest.API.Models.
+func (g *Generator) Schema(ref *openapi3.SchemaRef, models map[string]*openapi3.Schema) (v any, err error) {
+	return g.value(ref, models, 0)
+}
+
+func (g *Generator) value(ref *openapi3.SchemaRef, models map[string]*openapi3.Schema, depth int) (v any, err error) {
+	if ref == nil {
+		return nil, nil
+	}
+	s := ref.Value
+	if ref.Ref != "" {
+		name := strings.TrimPrefix(ref.Ref, "#/components/schemas/")
+		if s == nil {
+			s = models[name]
+		}
+		if s == nil {
+			return nil, fmt.Errorf("restfake: unknown reference %q", ref.Ref)
+		}
+	}
+	if s.Default != nil && g.rand.IntN(4) == 0 {
+		return s.Default, nil
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[g.rand.IntN(len(s.Enum))], nil
+	}
+	if s.Nullable && depth >= maxDepth {
+		return nil, nil
+	}
+	if len(s.OneOf) > 0 {
+		return g.value(s.OneOf[g.rand.IntN(len(s.OneOf))], models, depth+1)
+	}
+	if len(s.AnyOf) > 0 {
+		return g.value(s.AnyOf[g.rand.IntN(len(s.AnyOf))], models, depth+1)
+	}
+	if len(s.AllOf) > 0 {
+		return g.allOf(s.AllOf, models, depth)
+	}
+	switch {
+	case s.Type.Is(openapi3.TypeString):
+		return g.string(s)
+	case s.Type.Is(openapi3.TypeInteger):
+		return g.number(s, true), nil
+	case s.Type.Is(openapi3.TypeNumber):
+		return g.number(s, false), nil
+	case s.Type.Is(openapi3.TypeBoolean):
+		return g.rand.IntN(2) == 0, nil
+	case s.Type.Is(openapi3.TypeArray):
+		return g.array(s, models, depth)
+	case s.Type.Is(openapi3.TypeObject) || len(s.Properties) > 0:
+		return g.object(s, models, depth)
+	}
+	// Any value is allowed.
+	return nil, nil
+}
+
+func (g *Generator) allOf(refs openapi3.SchemaRefs, models map[string]*openapi3.Schema, depth int) (v any, err error) {
+	merged := make(map[string]any)
+	for _, ref := range refs {
+		part, err := g.value(ref, models, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := part.(map[string]any)
+		if !ok {
+			// Only objects can be merged, so use the first value.
+			return part, nil
+		}
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged, nil
+}
+
+func (g *Generator) number(s *openapi3.Schema, integer bool) float64 {
+	min, max := 0.0, 1000.0
+	if s.Min != nil {
+		min = *s.Min
+		if s.Max == nil {
+			max = min + 1000
+		}
+	}
+	if s.Max != nil {
+		max = *s.Max
+		if s.Min == nil {
+			min = math.Min(0, max-1000)
+		}
+	}
+	if integer {
+		min, max = math.Ceil(min), math.Floor(max)
+		if s.ExclusiveMin && s.Min != nil && min == *s.Min {
+			min++
+		}
+		if s.ExclusiveMax && s.Max != nil && max == *s.Max {
+			max--
+		}
+	}
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		m := *s.MultipleOf
+		lo, hi := math.Ceil(min/m), math.Floor(max/m)
+		if s.ExclusiveMin && s.Min != nil && lo*m == *s.Min {
+			lo++
+		}
+		if s.ExclusiveMax && s.Max != nil && hi*m == *s.Max {
+			hi--
+		}
+		if hi < lo {
+			return lo * m
+		}
+		return (lo + g.intN(hi-lo)) * m
+	}
+	if max <= min {
+		return min
+	}
+	if integer {
+		return min + g.intN(max-min)
+	}
+	v := min + g.rand.Float64()*(max-min)
+	if s.ExclusiveMin && s.Min != nil && v == *s.Min {
+		v = (min + max) / 2
+	}
+	return v
+}
+
+// intN returns a random integer in [0, n]. Ranges that don't fit in an int64, e.g. of
+// uint64 flags, are sampled as floating point numbers.
+func (g *Generator) intN(n float64) float64 {
+	if n < math.MaxInt64 {
+		return float64(g.rand.Int64N(int64(n) + 1))
+	}
+	return math.Min(math.Floor(g.rand.Float64()*n), n)
+}
+
+func (g *Generator) array(s *openapi3.Schema, models map[string]*openapi3.Schema, depth int) (v any, err error) {
+	n := g.length(s.MinItems, s.MaxItems, depth)
+	values := make([]any, 0, n)
+	for attempts := 0; len(values) < n && attempts < n*10; attempts++ {
+		item, err := g.value(s.Items, models, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if s.UniqueItems && containsValue(values, item) {
+			continue
+		}
+		values = append(values, item)
+	}
+	return values, nil
+}
+
+func (g *Generator) object(s *openapi3.Schema, models map[string]*openapi3.Schema, depth int) (v any, err error) {
+	m := make(map[string]any)
+	required := make(map[string]bool)
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	for _, name := range getSortedKeys(s.Properties) {
+		prop := s.Properties[name]
+		if prop.Value != nil && prop.Value.ReadOnly && !required[name] {
+			continue
+		}
+		if !required[name] && (depth >= maxDepth || g.rand.IntN(2) == 0) {
+			continue
+		}
+		if m[name], err = g.value(prop, models, depth+1); err != nil {
+			return nil, err
+		}
+	}
+	if ap := s.AdditionalProperties.Schema; ap != nil && len(s.Properties) == 0 {
+		n := g.length(s.MinProps, s.MaxProps, depth)
+		for i := 0; i < n; i++ {
+			if m[fmt.Sprintf("key%d", i+1)], err = g.value(ap, models, depth+1); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// length returns the length of an array or map between min and max.
+func (g *Generator) length(min uint64, max *uint64, depth int) int {
+	hi := min + 3
+	if max != nil && *max < hi {
+		hi = *max
+	}
+	if depth >= maxDepth {
+		return int(min)
+	}
+	return int(min) + g.rand.IntN(int(hi-min)+1)
+}
+
+func containsValue(values []any, v any) bool {
+	for _, existing := range values {
+		if fmt.Sprint(existing) == fmt.Sprint(v) {
+			return true
+		}
+	}
+	return false
+}
+
+func getSortedKeys[V any](m map[string]V) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
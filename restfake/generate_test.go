@@ -0,0 +1,126 @@
+package restfake_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/restfake"
+)
+
+type Status string
+
+type Order struct {
+	ID       string         `json:"id"`
+	Status   Status         `json:"status"`
+	Quantity int            `json:"quantity"`
+	Price    float64        `json:"price"`
+	Code     string         `json:"code"`
+	Email    string         `json:"email"`
+	Created  time.Time      `json:"created"`
+	Lines    []OrderLine    `json:"lines"`
+	Labels   map[string]int `json:"labels"`
+	Parent   *Order         `json:"parent"`
+}
+
+type OrderLine struct {
+	SKU string `json:"sku"`
+}
+
+func (Order) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Properties["id"].Value.Format = "uuid"
+	s.Properties["status"].Value.Enum = []any{"pending", "shipped"}
+	s.Properties["quantity"].Value.WithMin(1).WithMax(10)
+	s.Properties["price"].Value.WithMin(0.5).WithMax(2)
+	s.Properties["code"].Value.WithPattern(`^[A-Z]{3}-\d{4}$`)
+	s.Properties["email"].Value.Format = "email"
+	s.Properties["lines"].Value.WithMinItems(1).WithMaxItems(3)
+}
+
+func TestGenerate(t *testing.T) {
+	api := rest.NewAPI("test")
+	name, _, err := api.RegisterModel(rest.ModelOf[Order]())
+	if err != nil {
+		t.Fatalf("failed to register model: %v", err)
+	}
+	spec := &openapi3.T{OpenAPI: "3.0.0", Components: &openapi3.Components{Schemas: openapi3.Schemas{}}}
+	models, err := api.Models()
+	if err != nil {
+		t.Fatalf("failed to get models: %v", err)
+	}
+	for k, v := range models {
+		spec.Components.Schemas[k] = openapi3.NewSchemaRef("", v)
+	}
+	if err := openapi3.NewLoader().ResolveRefsIn(spec, nil); err != nil {
+		t.Fatalf("failed to resolve references: %v", err)
+	}
+	schema := spec.Components.Schemas[name].Value
+
+	for seed := uint64(0); seed < 50; seed++ {
+		v, err := restfake.Generate(rest.ModelOf[Order](), restfake.WithSeed(seed))
+		if err != nil {
+			t.Fatalf("failed to generate: %v", err)
+		}
+		if err := schema.VisitJSON(v); err != nil {
+			t.Fatalf("seed %d: generated value is invalid: %v\n%#v", seed, err, v)
+		}
+	}
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	a, err := restfake.Generate(rest.ModelOf[Order](), restfake.WithSeed(42))
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	b, err := restfake.Generate(rest.ModelOf[Order](), restfake.WithSeed(42))
+	if err != nil {
+		t.Fatalf("failed to generate: %v", err)
+	}
+	if diff := cmp.Diff(a, b); diff != "" {
+		t.Errorf("expected the same value for the same seed: %s", diff)
+	}
+}
+
+func TestGenerateFullRangeIntegers(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *openapi3.Schema
+	}{
+		{
+			name:   "uint64",
+			schema: openapi3.NewIntegerSchema().WithMin(0).WithMax(math.MaxUint64),
+		},
+		{
+			name:   "int64",
+			schema: openapi3.NewIntegerSchema().WithMin(math.MinInt64).WithMax(math.MaxInt64),
+		},
+		{
+			name: "multiple of",
+			schema: func() *openapi3.Schema {
+				s := openapi3.NewIntegerSchema().WithMin(0).WithMax(math.MaxUint64)
+				s.MultipleOf = openapi3.Float64Ptr(2)
+				return s
+			}(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for seed := uint64(0); seed < 50; seed++ {
+				v, err := restfake.New(restfake.WithSeed(seed)).Schema(openapi3.NewSchemaRef("", test.schema), nil)
+				if err != nil {
+					t.Fatalf("failed to generate: %v", err)
+				}
+				n, ok := v.(float64)
+				if !ok {
+					t.Fatalf("expected a number, got %T", v)
+				}
+				if n < *test.schema.Min || n > *test.schema.Max {
+					t.Errorf("seed %d: %v is out of range", seed, n)
+				}
+			}
+		})
+	}
+}
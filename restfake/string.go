@@ -0,0 +1,157 @@
+package restfake
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxRepeat limits the repetitions of unbounded patterns, e.g. a+.
+const maxRepeat = 5
+
+const letters = "abcdefghijklmnopqrstuvwxyz"
+
+func (g *Generator) string(s *openapi3.Schema) (v string, err error) {
+	switch {
+	case s.Pattern != "":
+		v, err = g.pattern(s.Pattern)
+		if err != nil {
+			return "", err
+		}
+	case s.Format != "":
+		v = g.format(s.Format)
+	default:
+		n := g.length(s.MinLength, s.MaxLength, 0) + 5
+		if s.MaxLength != nil && uint64(n) > *s.MaxLength {
+			n = int(*s.MaxLength)
+		}
+		v = g.letters(n)
+	}
+	// Pad or truncate values to fit the length constraints.
+	if n := utf8.RuneCountInString(v); uint64(n) < s.MinLength {
+		v += g.letters(int(s.MinLength) - n)
+	}
+	if s.MaxLength != nil && uint64(utf8.RuneCountInString(v)) > *s.MaxLength {
+		v = string([]rune(v)[:*s.MaxLength])
+	}
+	return v, nil
+}
+
+func (g *Generator) letters(n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteByte(letters[g.rand.IntN(len(letters))])
+	}
+	return sb.String()
+}
+
+func (g *Generator) format(format string) string {
+	switch format {
+	case "date-time":
+		return g.time().Format(time.RFC3339)
+	case "date":
+		return g.time().Format(time.DateOnly)
+	case "time":
+		return g.time().Format(time.TimeOnly)
+	case "email":
+		return g.letters(8) + "@example.com"
+	case "uuid":
+		b := make([]byte, 16)
+		for i := range b {
+			b[i] = byte(g.rand.IntN(256))
+		}
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "uri", "url":
+		return "https://example.com/" + g.letters(8)
+	case "hostname":
+		return g.letters(8) + ".example.com"
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", g.rand.IntN(256))
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", g.rand.IntN(0x10000))
+	case "byte":
+		return base64.StdEncoding.EncodeToString([]byte(g.letters(8)))
+	}
+	return g.letters(8)
+}
+
+func (g *Generator) time() time.Time {
+	start := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.Add(time.Duration(g.rand.Int64N(int64(30 * 365 * 24 * time.Hour)))).Truncate(time.Second)
+}
+
+// pattern generates a string that matches the regular expression.
+func (g *Generator) pattern(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", fmt.Errorf("restfake: invalid pattern %q: %w", pattern, err)
+	}
+	var sb strings.Builder
+	g.regexp(&sb, re.Simplify())
+	return sb.String(), nil
+}
+
+func (g *Generator) regexp(sb *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		sb.WriteRune(g.charClass(re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteByte(letters[g.rand.IntN(len(letters))])
+	case syntax.OpCapture:
+		g.regexp(sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.regexp(sb, sub)
+		}
+	case syntax.OpAlternate:
+		g.regexp(sb, re.Sub[g.rand.IntN(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, -1
+		case syntax.OpPlus:
+			min, max = 1, -1
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + maxRepeat
+		}
+		n := min + g.rand.IntN(max-min+1)
+		for i := 0; i < n; i++ {
+			g.regexp(sb, re.Sub[0])
+		}
+	}
+	// Anchors and empty matches don't generate any characters.
+}
+
+// charClass picks a rune from the ranges of a character class, given as pairs of
+// the first and last rune of each range. Printable ASCII is preferred.
+func (g *Generator) charClass(ranges []rune) rune {
+	var printable []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], ' '), min(ranges[i+1], '~')
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) == 0 {
+		return 'a'
+	}
+	i := g.rand.IntN(len(ranges)/2) * 2
+	lo, hi := ranges[i], ranges[i+1]
+	return lo + rune(g.rand.IntN(int(hi-lo)+1))
+}
@@ -0,0 +1,122 @@
+package resttest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/restdiff"
+)
+
+// CompatibilityOpts configures AssertBackwardsCompatible.
+type CompatibilityOpts func(c *compatibilityConfig)
+
+type compatibilityConfig struct {
+	allowed []allowedChange
+}
+
+type allowedChange struct {
+	operation, location string
+}
+
+// AllowBreakingChange allows intentional breaking changes to an operation, e.g.
+// AllowBreakingChange("GET /users", "response.200[].email"). If location is empty, all
+// breaking changes to the operation are allowed, including its removal. Remove the
+// exception once the new snapshot has been committed.
+func AllowBreakingChange(operation, location string) CompatibilityOpts {
+	return func(c *compatibilityConfig) {
+		c.allowed = append(c.allowed, allowedChange{operation: operation, location: location})
+	}
+}
+
+func (c compatibilityConfig) isAllowed(change restdiff.Change) bool {
+	return slices.ContainsFunc(c.allowed, func(a allowedChange) bool {
+		return a.operation == change.Operation && (a.location == "" || a.location == change.Location)
+	})
+}
+
+// CheckBackwardsCompatible compares the specification of the API with the snapshot of
+// its previous version in dir, and returns the breaking changes that aren't allowed, see
+// restdiff.Diff. If there are none, the snapshot is updated, so commit it with the
+// changes to the API. If there is no snapshot, one is created.
+//
+// The snapshot is named after the API, e.g. snapshots/users.json.
+func CheckBackwardsCompatible(dir string, api *rest.API, opts ...CompatibilityOpts) (breaking []restdiff.Change, err error) {
+	var config compatibilityConfig
+	for _, o := range opts {
+		o(&config)
+	}
+	spec, err := api.Spec()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spec: %w", err)
+	}
+	path := filepath.Join(dir, getSnapshotName(api.Name))
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if err == nil {
+		previous, err := openapi3.NewLoader().LoadFromData(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load snapshot %q: %w", path, err)
+		}
+		for _, c := range restdiff.Diff(previous, spec) {
+			if c.Severity == restdiff.Breaking && !config.isAllowed(c) {
+				breaking = append(breaking, c)
+			}
+		}
+		if len(breaking) > 0 {
+			return breaking, nil
+		}
+	}
+	if err = writeSnapshot(path, spec); err != nil {
+		return nil, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil, nil
+}
+
+// AssertBackwardsCompatible fails the test for each breaking change to the API since
+// the snapshot in dir was taken, see CheckBackwardsCompatible.
+func AssertBackwardsCompatible(t *testing.T, dir string, api *rest.API, opts ...CompatibilityOpts) {
+	t.Helper()
+	breaking, err := CheckBackwardsCompatible(dir, api, opts...)
+	if err != nil {
+		t.Fatalf("failed to check compatibility: %v", err)
+	}
+	for _, c := range breaking {
+		t.Errorf("breaking change: %v", c)
+	}
+}
+
+// getSnapshotName returns the file name of the snapshot of the API, using only letters,
+// digits, hyphens and underscores.
+func getSnapshotName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" {
+		name = "openapi"
+	}
+	return name + ".json"
+}
+
+func writeSnapshot(path string, spec *openapi3.T) error {
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal spec: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,71 @@
+package resttest_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/resttest"
+)
+
+type Account struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type AccountWithoutEmail struct {
+	Name string `json:"name"`
+}
+
+func newAccountAPI[T any](extra func(api *rest.API)) *rest.API {
+	api := rest.NewAPI("accounts api")
+	api.Get("/accounts/{id}").
+		HasPathParameter("id", rest.PathParam{}).
+		HasResponseModel(http.StatusOK, rest.ModelOf[T]())
+	extra(api)
+	return api
+}
+
+func TestCheckBackwardsCompatible(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	snapshot := filepath.Join(dir, "accounts_api.json")
+
+	// The first run creates the snapshot.
+	resttest.AssertBackwardsCompatible(t, dir, newAccountAPI[Account](func(api *rest.API) {}))
+	if _, err := os.Stat(snapshot); err != nil {
+		t.Fatalf("expected the snapshot to be created: %v", err)
+	}
+
+	// Additive changes are compatible, and update the snapshot.
+	withList := func(api *rest.API) {
+		api.Get("/accounts").HasResponseModel(http.StatusOK, rest.ModelOf[[]Account]())
+	}
+	resttest.AssertBackwardsCompatible(t, dir, newAccountAPI[Account](withList))
+
+	// Breaking changes are returned, and don't update the snapshot.
+	breaking, err := resttest.CheckBackwardsCompatible(dir, newAccountAPI[AccountWithoutEmail](func(api *rest.API) {}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var actual []string
+	for _, c := range breaking {
+		actual = append(actual, c.String())
+	}
+	expected := []string{
+		"GET /accounts: operation removed (breaking)",
+		"GET /accounts/{id} response.200.email: property removed (breaking)",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	// Intentional breaking changes can be allowed.
+	resttest.AssertBackwardsCompatible(t, dir, newAccountAPI[AccountWithoutEmail](func(api *rest.API) {}),
+		resttest.AllowBreakingChange("GET /accounts", ""),
+		resttest.AllowBreakingChange("GET /accounts/{id}", "response.200.email"),
+	)
+	resttest.AssertBackwardsCompatible(t, dir, newAccountAPI[AccountWithoutEmail](func(api *rest.API) {}))
+}
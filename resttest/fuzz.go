@@ -0,0 +1,332 @@
+// Package resttest contains helpers for testing handlers against the specification
+// of a rest.API.
+package resttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/restfake"
+)
+
+// Mutation changes a valid request into a boundary or invalid variant.
+type Mutation string
+
+const (
+	// MutationNone sends valid requests.
+	MutationNone Mutation = "none"
+	// MutationBoundary sets a value to the minimum or maximum allowed by its schema,
+	// e.g. an empty string, or the largest permitted number.
+	MutationBoundary Mutation = "boundary"
+	// MutationWrongType replaces a value with a value of another type.
+	MutationWrongType Mutation = "wrong-type"
+	// MutationMissingRequired removes a required property or query parameter.
+	MutationMissingRequired Mutation = "missing-required"
+	// MutationNull replaces a value with null.
+	MutationNull Mutation = "null"
+	// MutationInvalidJSON truncates the request body.
+	MutationInvalidJSON Mutation = "invalid-json"
+)
+
+// AllMutations are used when FuzzConfig.Mutations is empty.
+var AllMutations = []Mutation{MutationNone, MutationBoundary, MutationWrongType, MutationMissingRequired, MutationNull, MutationInvalidJSON}
+
+// FuzzConfig configures Fuzz.
+type FuzzConfig struct {
+	// Mutations applied to generated requests. Defaults to AllMutations.
+	Mutations []Mutation
+	// Iterations is the number of requests sent to each operation for each mutation.
+	// Defaults to 10.
+	Iterations int
+	// Seed makes the generated requests deterministic.
+	Seed uint64
+}
+
+// FuzzFailure is a request that the handler didn't handle correctly.
+type FuzzFailure struct {
+	// Operation, e.g. "POST /users".
+	Operation string
+	Mutation  Mutation
+	// Request that was sent.
+	Method string
+	URL    string
+	Body   string
+	// Status returned by the handler.
+	Status int
+	// Reason for the failure.
+	Reason string
+}
+
+func (f FuzzFailure) Error() string {
+	return fmt.Sprintf("%s (%s): %s %s: %s", f.Operation, f.Mutation, f.Method, f.URL, f.Reason)
+}
+
+// Fuzz sends generated requests for each of the API's operations to the handler, including
+// boundary and invalid variants, and returns the requests where the handler returned a
+// 5xx status, or a response that doesn't match the schema declared for its status.
+func Fuzz(api *rest.API, handler http.Handler, config FuzzConfig) (failures []FuzzFailure, err error) {
+	spec, err := api.Spec()
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Mutations) == 0 {
+		config.Mutations = AllMutations
+	}
+	if config.Iterations <= 0 {
+		config.Iterations = 10
+	}
+	f := fuzzer{
+		gen:  restfake.New(restfake.WithSeed(config.Seed)),
+		rand: rand.New(rand.NewPCG(config.Seed, config.Seed)),
+	}
+	for _, path := range spec.Paths.InMatchingOrder() {
+		pathItem := spec.Paths.Value(path)
+		methods := make([]string, 0, len(pathItem.Operations()))
+		for method := range pathItem.Operations() {
+			methods = append(methods, method)
+		}
+		slices.Sort(methods)
+		for _, method := range methods {
+			op := pathItem.GetOperation(method)
+			for _, mutation := range config.Mutations {
+				for i := 0; i < config.Iterations; i++ {
+					failure, ok, err := f.send(handler, method, path, op, mutation)
+					if err != nil {
+						return failures, fmt.Errorf("%s %s: %w", method, path, err)
+					}
+					if !ok {
+						failures = append(failures, failure)
+					}
+				}
+			}
+		}
+	}
+	return failures, nil
+}
+
+type fuzzer struct {
+	gen  *restfake.Generator
+	rand *rand.Rand
+}
+
+func (f fuzzer) send(handler http.Handler, method, path string, op *openapi3.Operation, mutation Mutation) (failure FuzzFailure, ok bool, err error) {
+	req, body, err := f.newRequest(method, path, op, mutation)
+	if err != nil {
+		return failure, false, err
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	failure = FuzzFailure{
+		Operation: method + " " + path,
+		Mutation:  mutation,
+		Method:    method,
+		URL:       req.URL.String(),
+		Body:      body,
+		Status:    w.Code,
+	}
+	if w.Code >= 500 {
+		failure.Reason = fmt.Sprintf("returned status %d", w.Code)
+		return failure, false, nil
+	}
+	if reason := checkResponse(op, w); reason != "" {
+		failure.Reason = reason
+		return failure, false, nil
+	}
+	return failure, true, nil
+}
+
+// checkResponse returns the reason that the response doesn't match the operation's
+// declared response, or an empty string if it matches.
+func checkResponse(op *openapi3.Operation, w *httptest.ResponseRecorder) (reason string) {
+	if op.Responses == nil {
+		return ""
+	}
+	response := op.Responses.Status(w.Code)
+	if response == nil || response.Value == nil {
+		return ""
+	}
+	mediaType := response.Value.Content.Get("application/json")
+	if mediaType == nil || mediaType.Schema == nil || mediaType.Schema.Value == nil {
+		return ""
+	}
+	var body any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		return fmt.Sprintf("response %d is not valid JSON: %v", w.Code, err)
+	}
+	if err := mediaType.Schema.Value.VisitJSON(body, openapi3.MultiErrors()); err != nil {
+		return fmt.Sprintf("response %d doesn't match its schema: %v", w.Code, err)
+	}
+	return ""
+}
+
+func (f fuzzer) newRequest(method, path string, op *openapi3.Operation, mutation Mutation) (req *http.Request, body string, err error) {
+	query := url.Values{}
+	var queryParams []*openapi3.Parameter
+	for _, p := range op.Parameters {
+		if p.Value == nil {
+			continue
+		}
+		v, err := f.gen.Schema(p.Value.Schema, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		switch p.Value.In {
+		case openapi3.ParameterInPath:
+			path = strings.ReplaceAll(path, "{"+p.Value.Name+"}", url.PathEscape(formatValue(v)))
+		case openapi3.ParameterInQuery:
+			query.Set(p.Value.Name, formatValue(v))
+			queryParams = append(queryParams, p.Value)
+		}
+	}
+	var bodySchema *openapi3.SchemaRef
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		if mt := op.RequestBody.Value.Content.Get("application/json"); mt != nil {
+			bodySchema = mt.Schema
+		}
+	}
+
+	var bodyValue any
+	if bodySchema != nil {
+		if bodyValue, err = f.gen.Schema(bodySchema, nil); err != nil {
+			return nil, "", err
+		}
+	}
+	switch mutation {
+	case MutationBoundary, MutationWrongType, MutationNull:
+		if bodySchema != nil && (len(queryParams) == 0 || f.rand.IntN(2) == 0) {
+			bodyValue = f.mutate(bodyValue, bodySchema.Value, mutation)
+		} else if len(queryParams) > 0 {
+			p := queryParams[f.rand.IntN(len(queryParams))]
+			v := f.mutate(nil, p.Schema.Value, mutation)
+			query.Set(p.Name, formatValue(v))
+		}
+	case MutationMissingRequired:
+		if m, ok := bodyValue.(map[string]any); ok && bodySchema.Value != nil && len(bodySchema.Value.Required) > 0 {
+			required := bodySchema.Value.Required
+			delete(m, required[f.rand.IntN(len(required))])
+		} else {
+			for _, p := range queryParams {
+				if p.Required {
+					query.Del(p.Name)
+					break
+				}
+			}
+		}
+	}
+
+	if bodySchema != nil {
+		data, err := json.Marshal(bodyValue)
+		if err != nil {
+			return nil, "", err
+		}
+		if mutation == MutationInvalidJSON && len(data) > 1 {
+			data = data[:len(data)/2]
+		}
+		body = string(data)
+	}
+	target := path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req = httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+	if bodySchema != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, body, nil
+}
+
+// mutate applies the mutation to the value, or to one of its properties.
+func (f fuzzer) mutate(v any, s *openapi3.Schema, mutation Mutation) any {
+	if m, ok := v.(map[string]any); ok && s != nil && len(m) > 0 && f.rand.IntN(4) != 0 {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		k := keys[f.rand.IntN(len(keys))]
+		var prop *openapi3.Schema
+		if ref := s.Properties[k]; ref != nil {
+			prop = ref.Value
+		}
+		m[k] = f.mutate(m[k], prop, mutation)
+		return m
+	}
+	switch mutation {
+	case MutationNull:
+		return nil
+	case MutationWrongType:
+		switch v.(type) {
+		case string:
+			return f.rand.IntN(1000)
+		case map[string]any:
+			return []any{"unexpected"}
+		}
+		return "unexpected"
+	case MutationBoundary:
+		return f.boundary(s)
+	}
+	return v
+}
+
+// boundary returns a value at one of the limits of the schema.
+func (f fuzzer) boundary(s *openapi3.Schema) any {
+	if s == nil || s.Type == nil {
+		return ""
+	}
+	var values []any
+	switch {
+	case s.Type.Is(openapi3.TypeString):
+		values = append(values, "", strings.Repeat("a", 10000))
+		if s.MaxLength != nil {
+			values = append(values, strings.Repeat("a", int(*s.MaxLength)))
+		}
+	case s.Type.Is(openapi3.TypeInteger), s.Type.Is(openapi3.TypeNumber):
+		values = append(values, 0, -1, int64(1)<<53)
+		if s.Min != nil {
+			values = append(values, *s.Min)
+		}
+		if s.Max != nil {
+			values = append(values, *s.Max)
+		}
+	case s.Type.Is(openapi3.TypeArray):
+		values = append(values, []any{})
+	case s.Type.Is(openapi3.TypeObject):
+		values = append(values, map[string]any{})
+	default:
+		values = append(values, false)
+	}
+	return values[f.rand.IntN(len(values))]
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
+
+// Errors joins the failures into a single error, or returns nil if there are none.
+func Errors(failures []FuzzFailure) error {
+	errs := make([]error, len(failures))
+	for i, f := range failures {
+		errs[i] = f
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,79 @@
+package resttest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/resttest"
+)
+
+type User struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type Error struct {
+	Message string `json:"message"`
+}
+
+func newAPI() *rest.API {
+	api := rest.NewAPI("test")
+	api.Post("/users").
+		HasRequestModel(rest.ModelOf[User]()).
+		HasResponseModel(http.StatusCreated, rest.ModelOf[User]()).
+		HasResponseModel(http.StatusBadRequest, rest.ModelOf[Error]())
+	return api
+}
+
+func TestFuzz(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var u User
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(Error{Message: err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(u)
+	})
+	failures, err := resttest.Fuzz(newAPI(), handler, resttest.FuzzConfig{Seed: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := resttest.Errors(failures); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFuzzFindsFailures(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var u User
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			// Invalid requests cause a server error.
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// The response is missing the required id field.
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"name":"a"}`))
+	})
+	failures, err := resttest.Fuzz(newAPI(), handler, resttest.FuzzConfig{
+		Mutations:  []resttest.Mutation{resttest.MutationNone, resttest.MutationInvalidJSON},
+		Iterations: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(failures) != 2 {
+		t.Fatalf("expected 2 failures, got %d: %v", len(failures), failures)
+	}
+	if !strings.Contains(failures[0].Reason, "doesn't match its schema") {
+		t.Errorf("expected a schema failure, got %q", failures[0].Reason)
+	}
+	if failures[1].Status != http.StatusInternalServerError {
+		t.Errorf("expected a server error, got %d", failures[1].Status)
+	}
+}
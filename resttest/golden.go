@@ -0,0 +1,100 @@
+package resttest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/heimspiel/rest"
+	"gopkg.in/yaml.v2"
+)
+
+// GoldenCase is a test that the specification of an API matches an expected YAML file.
+type GoldenCase struct {
+	// Name of the expected YAML file, e.g. "users.yaml", which is also the name of the
+	// subtest, and the title of the API.
+	Name string
+	// Opts used to create the API.
+	Opts []rest.APIOpts
+	// StripPkgPaths are stripped from the names of the types in the specification, so
+	// that the expected files don't depend on where the models are.
+	StripPkgPaths []string
+	// Setup configures the API, e.g. by adding routes and registering models.
+	Setup func(api *rest.API) error
+}
+
+// RunGolden runs each case as a subtest, comparing the specification of the API with
+// the expected YAML file of the same name in fsys, e.g. an embed.FS:
+//
+//	//go:embed tests/*.yaml
+//	var testFiles embed.FS
+//
+//	func TestSpec(t *testing.T) {
+//		tests, _ := fs.Sub(testFiles, "tests")
+//		resttest.RunGolden(t, tests, []resttest.GoldenCase{...})
+//	}
+//
+// Both specifications are marshalled in the same way before they're compared, so the
+// order of the keys in the expected files doesn't matter. The actual YAML is logged if
+// they differ, so that it can be copied into the expected file.
+func RunGolden(t *testing.T, fsys fs.FS, cases []GoldenCase) {
+	t.Helper()
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			expected, err := readGoldenFile(fsys, c.Name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			api := rest.NewAPI(c.Name, c.Opts...)
+			api.StripPkgPaths = c.StripPkgPaths
+			if c.Setup != nil {
+				if err = c.Setup(api); err != nil {
+					t.Fatalf("failed to set up the API: %v", err)
+				}
+			}
+			spec, err := api.Spec()
+			if err != nil {
+				t.Fatalf("failed to generate spec: %v", err)
+			}
+			actual, err := specToYAML(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(string(expected), string(actual)); diff != "" {
+				t.Error(diff)
+				t.Error("\n\n" + string(actual))
+			}
+		})
+	}
+}
+
+// readGoldenFile loads the expected specification, and marshals it in the same way as
+// the actual specification.
+func readGoldenFile(fsys fs.FS, name string) ([]byte, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file %q: %w", name, err)
+	}
+	spec, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("error in expected YAML: %w", err)
+	}
+	return specToYAML(spec)
+}
+
+func specToYAML(spec *openapi3.T) ([]byte, error) {
+	// Use JSON, because kin-openapi doesn't customise the YAML output.
+	// For example, AdditionalProperties only has a MarshalJSON capability.
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal spec to JSON: %w", err)
+	}
+	var m map[string]any
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(m)
+}
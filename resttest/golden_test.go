@@ -0,0 +1,35 @@
+package resttest_test
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"testing"
+
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/resttest"
+)
+
+//go:embed tests/*.yaml
+var testFiles embed.FS
+
+func TestRunGolden(t *testing.T) {
+	tests, err := fs.Sub(testFiles, "tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resttest.RunGolden(t, tests, []resttest.GoldenCase{
+		{
+			Name:          "accounts.yaml",
+			Opts:          []rest.APIOpts{rest.WithVersion("1.0.0")},
+			StripPkgPaths: []string{"github.com/heimspiel/rest"},
+			Setup: func(api *rest.API) error {
+				api.Post("/accounts").
+					HasRequestModel(rest.ModelOf[CreateAccount]()).
+					HasResponseModel(http.StatusCreated, rest.ModelOf[Account]()).
+					HasResponseDescription(http.StatusCreated, "The created account.")
+				return nil
+			},
+		},
+	})
+}
@@ -0,0 +1,313 @@
+package resttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/restfake"
+)
+
+// NegativeCase is a request that violates the specification of an operation, and
+// should be rejected by the handler with a 4xx status.
+type NegativeCase struct {
+	// Name of the case, e.g. "POST /users/body.name/too-long".
+	Name string
+	// Operation, e.g. "POST /users".
+	Operation string
+	Method    string
+	// URL of the request, including its query string.
+	URL string
+	// Body of the request. Empty if the operation has no request body.
+	Body string
+}
+
+// NewRequest creates the request of the case.
+func (c NegativeCase) NewRequest() *http.Request {
+	r := httptest.NewRequest(c.Method, c.URL, strings.NewReader(c.Body))
+	if c.Body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return r
+}
+
+// NegativeCases creates a request for each way that the requests of the API's operations
+// can be invalid: missing required properties and query parameters, values outside of
+// enums, strings that are too long or too short, and numbers outside of their range.
+// Nested objects in the request body are included.
+func NegativeCases(api *rest.API) (cases []NegativeCase, err error) {
+	spec, err := api.Spec()
+	if err != nil {
+		return nil, err
+	}
+	gen := restfake.New(restfake.WithSeed(0))
+	for _, path := range spec.Paths.InMatchingOrder() {
+		pathItem := spec.Paths.Value(path)
+		methods := make([]string, 0, len(pathItem.Operations()))
+		for method := range pathItem.Operations() {
+			methods = append(methods, method)
+		}
+		slices.Sort(methods)
+		for _, method := range methods {
+			op := pathItem.GetOperation(method)
+			nc := negativeCaseBuilder{gen: gen, method: method, operation: method + " " + path}
+			if err = nc.build(path, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			cases = append(cases, nc.cases...)
+		}
+	}
+	return cases, nil
+}
+
+// RunNegativeCases runs each of the API's NegativeCases against the handler as a subtest,
+// and fails the subtest if the handler doesn't return a 4xx status.
+func RunNegativeCases(t *testing.T, api *rest.API, handler http.Handler) {
+	t.Helper()
+	cases, err := NegativeCases(api)
+	if err != nil {
+		t.Fatalf("failed to create negative cases: %v", err)
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, c.NewRequest())
+			if w.Code < 400 || w.Code > 499 {
+				t.Errorf("%s %s: expected a 4xx status, got %d\nbody: %s", c.Method, c.URL, w.Code, c.Body)
+			}
+		})
+	}
+}
+
+type negativeCaseBuilder struct {
+	gen       *restfake.Generator
+	method    string
+	operation string
+	path      string
+	query     url.Values
+	body      map[string]any
+	hasBody   bool
+	cases     []NegativeCase
+}
+
+func (b *negativeCaseBuilder) build(path string, op *openapi3.Operation) error {
+	b.query = url.Values{}
+	var queryParams []*openapi3.Parameter
+	for _, p := range op.Parameters {
+		if p.Value == nil {
+			continue
+		}
+		v, err := b.gen.Schema(p.Value.Schema, nil)
+		if err != nil {
+			return err
+		}
+		switch p.Value.In {
+		case openapi3.ParameterInPath:
+			path = strings.ReplaceAll(path, "{"+p.Value.Name+"}", url.PathEscape(formatValue(v)))
+		case openapi3.ParameterInQuery:
+			b.query.Set(p.Value.Name, formatValue(v))
+			queryParams = append(queryParams, p.Value)
+		}
+	}
+	b.path = path
+
+	var bodySchema *openapi3.Schema
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		if mt := op.RequestBody.Value.Content.Get("application/json"); mt != nil && mt.Schema != nil {
+			bodySchema = mt.Schema.Value
+		}
+	}
+	if bodySchema != nil {
+		b.hasBody = true
+		var err error
+		if b.body, err = completeObject(b.gen, bodySchema, map[*openapi3.Schema]bool{}); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range queryParams {
+		name := "query." + p.Name
+		if p.Required {
+			b.add(name+"/missing", func(query url.Values, body map[string]any) { query.Del(p.Name) })
+		}
+		for _, v := range getInvalidValues(p.Schema.Value) {
+			b.add(name+"/"+v.kind, func(query url.Values, body map[string]any) { query.Set(p.Name, formatValue(v.value)) })
+		}
+	}
+	if bodySchema != nil {
+		b.addSchemaCases("body", nil, bodySchema, map[*openapi3.Schema]bool{})
+	}
+	return nil
+}
+
+// addSchemaCases adds cases for the properties of an object in the body, at the given
+// keys, e.g. ["address", "line1"]. Objects that contain themselves, e.g. the parent of a
+// tree node, are visited once.
+func (b *negativeCaseBuilder) addSchemaCases(name string, keys []string, s *openapi3.Schema, visited map[*openapi3.Schema]bool) {
+	visited[s] = true
+	defer delete(visited, s)
+	for _, prop := range getSortedKeys(s.Properties) {
+		ref := s.Properties[prop]
+		if ref.Value == nil {
+			continue
+		}
+		propKeys := append(slices.Clone(keys), prop)
+		propName := name + "." + prop
+		if slices.Contains(s.Required, prop) {
+			b.add(propName+"/missing", func(query url.Values, body map[string]any) {
+				parent, key := getParent(body, propKeys)
+				delete(parent, key)
+			})
+		}
+		for _, v := range getInvalidValues(ref.Value) {
+			b.add(propName+"/"+v.kind, func(query url.Values, body map[string]any) {
+				parent, key := getParent(body, propKeys)
+				parent[key] = v.value
+			})
+		}
+		if ref.Value.Type.Is(openapi3.TypeObject) && len(ref.Value.Properties) > 0 && !visited[ref.Value] {
+			b.addSchemaCases(propName, propKeys, ref.Value, visited)
+		}
+	}
+}
+
+// add creates a case by applying the change to a copy of the valid request.
+func (b *negativeCaseBuilder) add(name string, change func(query url.Values, body map[string]any)) {
+	query := url.Values{}
+	for k, v := range b.query {
+		query[k] = slices.Clone(v)
+	}
+	var body map[string]any
+	if b.hasBody {
+		body = deepCopy(b.body).(map[string]any)
+	}
+	change(query, body)
+
+	c := NegativeCase{
+		Name:      b.operation + "/" + name,
+		Operation: b.operation,
+		Method:    b.method,
+		URL:       b.path,
+	}
+	if len(query) > 0 {
+		c.URL += "?" + query.Encode()
+	}
+	if b.hasBody {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		enc.Encode(body)
+		c.Body = strings.TrimSpace(buf.String())
+	}
+	b.cases = append(b.cases, c)
+}
+
+type invalidValue struct {
+	kind  string
+	value any
+}
+
+// getInvalidValues returns values that violate the constraints of the schema.
+func getInvalidValues(s *openapi3.Schema) (values []invalidValue) {
+	if s == nil {
+		return nil
+	}
+	if len(s.Enum) > 0 {
+		values = append(values, invalidValue{kind: "not-in-enum", value: "not-in-enum"})
+	}
+	if s.MaxLength != nil {
+		values = append(values, invalidValue{kind: "too-long", value: strings.Repeat("a", int(*s.MaxLength)+1)})
+	}
+	if s.MinLength > 0 {
+		values = append(values, invalidValue{kind: "too-short", value: strings.Repeat("a", int(s.MinLength)-1)})
+	}
+	if s.Min != nil {
+		values = append(values, invalidValue{kind: "below-minimum", value: *s.Min - 1})
+	}
+	if s.Max != nil {
+		values = append(values, invalidValue{kind: "above-maximum", value: *s.Max + 1})
+	}
+	return values
+}
+
+// completeObject generates a valid value for the schema, where all of the properties
+// are set, so that each can be made invalid. Objects that contain themselves are only
+// completed once, and their nested values are left to the generator, which limits their
+// depth.
+func completeObject(gen *restfake.Generator, s *openapi3.Schema, visited map[*openapi3.Schema]bool) (map[string]any, error) {
+	visited[s] = true
+	defer delete(visited, s)
+	v, err := gen.Schema(openapi3.NewSchemaRef("", s), nil)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		m = make(map[string]any)
+	}
+	for name, ref := range s.Properties {
+		if ref.Value == nil || ref.Value.ReadOnly {
+			continue
+		}
+		if ref.Value.Type.Is(openapi3.TypeObject) && len(ref.Value.Properties) > 0 && !visited[ref.Value] {
+			if m[name], err = completeObject(gen, ref.Value, visited); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if _, ok := m[name]; !ok {
+			if m[name], err = gen.Schema(ref, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+// getParent returns the object that contains the last of the keys.
+func getParent(body map[string]any, keys []string) (parent map[string]any, key string) {
+	parent = body
+	for _, k := range keys[:len(keys)-1] {
+		child, ok := parent[k].(map[string]any)
+		if !ok {
+			child = make(map[string]any)
+			parent[k] = child
+		}
+		parent = child
+	}
+	return parent, keys[len(keys)-1]
+}
+
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			m[k] = deepCopy(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = deepCopy(e)
+		}
+		return s
+	}
+	return v
+}
+
+func getSortedKeys[V any](m map[string]V) (keys []string) {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,128 @@
+package resttest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/go-cmp/cmp"
+	"github.com/heimspiel/rest"
+	"github.com/heimspiel/rest/resttest"
+)
+
+type Address struct {
+	Country string `json:"country"`
+}
+
+type CreateAccount struct {
+	Name    string  `json:"name"`
+	Plan    string  `json:"plan"`
+	Seats   int     `json:"seats"`
+	Address Address `json:"address"`
+}
+
+func (CreateAccount) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Properties["name"].Value.WithMinLength(1).WithMaxLength(20)
+	s.Properties["plan"].Value.WithEnum("free", "pro")
+	s.Properties["seats"].Value.WithMin(1).WithMax(100)
+}
+
+func newAccountsAPI() *rest.API {
+	api := rest.NewAPI("test")
+	api.Post("/accounts").
+		HasQueryParameter("dryRun", rest.QueryParam{Type: rest.PrimitiveTypeBool, Required: true}).
+		HasRequestModel(rest.ModelOf[CreateAccount]()).
+		HasResponseModel(http.StatusCreated, rest.ModelOf[CreateAccount]())
+	return api
+}
+
+func TestNegativeCases(t *testing.T) {
+	cases, err := resttest.NegativeCases(newAccountsAPI())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, c := range cases {
+		names = append(names, c.Name)
+	}
+	expected := []string{
+		"POST /accounts/query.dryRun/missing",
+		"POST /accounts/body.address/missing",
+		"POST /accounts/body.address.country/missing",
+		"POST /accounts/body.name/missing",
+		"POST /accounts/body.name/too-long",
+		"POST /accounts/body.name/too-short",
+		"POST /accounts/body.plan/missing",
+		"POST /accounts/body.plan/not-in-enum",
+		"POST /accounts/body.seats/missing",
+		"POST /accounts/body.seats/below-minimum",
+		"POST /accounts/body.seats/above-maximum",
+	}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Error(diff)
+	}
+
+	i := slices.IndexFunc(cases, func(c resttest.NegativeCase) bool { return c.Name == "POST /accounts/body.plan/not-in-enum" })
+	var body CreateAccount
+	if err := json.Unmarshal([]byte(cases[i].Body), &body); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if body.Plan != "not-in-enum" {
+		t.Errorf("expected the plan to be invalid, got %q", body.Plan)
+	}
+}
+
+type Node struct {
+	Name   string `json:"name"`
+	Parent *Node  `json:"parent"`
+}
+
+func (Node) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Properties["name"].Value.WithMaxLength(10)
+}
+
+func TestNegativeCasesRecursiveModel(t *testing.T) {
+	api := rest.NewAPI("test")
+	api.Post("/nodes").
+		HasRequestModel(rest.ModelOf[Node]()).
+		HasResponseModel(http.StatusCreated, rest.ModelOf[Node]())
+	cases, err := resttest.NegativeCases(api)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, c := range cases {
+		names = append(names, c.Name)
+	}
+	expected := []string{
+		"POST /nodes/body.name/missing",
+		"POST /nodes/body.name/too-long",
+	}
+	if diff := cmp.Diff(expected, names); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestRunNegativeCases(t *testing.T) {
+	// A handler that validates the request against the rules in the spec.
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		address, _ := body["address"].(map[string]any)
+		name, _ := body["name"].(string)
+		seats, _ := body["seats"].(float64)
+		valid := r.URL.Query().Has("dryRun") &&
+			address != nil && address["country"] != nil &&
+			len(name) >= 1 && len(name) <= 20 &&
+			(body["plan"] == "free" || body["plan"] == "pro") &&
+			seats >= 1 && seats <= 100
+		if !valid {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	resttest.RunNegativeCases(t, newAccountsAPI(), handler)
+}
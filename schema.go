@@ -1,10 +1,14 @@
 package rest
 
 import (
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
+	"net/http"
 	"reflect"
 	"slices"
-	"sort"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -13,13 +17,21 @@ import (
 	"golang.org/x/exp/constraints"
 )
 
-func newSpec(name string) *openapi3.T {
+func newSpec(api *API) *openapi3.T {
+	version := api.Version
+	if version == "" {
+		version = "0.0.0"
+	}
 	return &openapi3.T{
 		OpenAPI: "3.0.0",
 		Info: &openapi3.Info{
-			Title:      name,
-			Version:    "0.0.0",
-			Extensions: map[string]interface{}{},
+			Title:          api.Name,
+			Version:        version,
+			Description:    api.Description,
+			Contact:        api.Contact,
+			License:        api.License,
+			TermsOfService: api.TermsOfService,
+			Extensions:     map[string]interface{}{},
 		},
 		Components: &openapi3.Components{
 			Schemas:    make(openapi3.Schemas),
@@ -30,54 +42,112 @@ func newSpec(name string) *openapi3.T {
 	}
 }
 
-func getSortedKeys[V any](m map[string]V) (op []string) {
+func getSortedKeys[K constraints.Ordered, V any](m map[K]V) (op []K) {
 	for k := range m {
 		op = append(op, k)
 	}
-	sort.Slice(op, func(i, j int) bool {
-		return op[i] < op[j]
-	})
+	slices.Sort(op)
 	return op
 }
 
-func newPrimitiveSchema(paramType PrimitiveType) *openapi3.Schema {
+// methodOrder is the canonical order of HTTP methods in the specification.
+var methodOrder = []Method{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+// getSortedMethods returns the methods in canonical order, followed by any
+// non-standard methods in alphabetical order.
+func getSortedMethods(m MethodToRoute) (op []Method) {
+	for _, method := range methodOrder {
+		if _, ok := m[method]; ok {
+			op = append(op, method)
+		}
+	}
+	for _, method := range getSortedKeys(m) {
+		if !slices.Contains(methodOrder, method) {
+			op = append(op, method)
+		}
+	}
+	return op
+}
+
+func newPrimitiveSchema(paramType PrimitiveType) (s *openapi3.Schema, ok bool) {
 	switch paramType {
 	case PrimitiveTypeString:
-		return openapi3.NewStringSchema()
+		return openapi3.NewStringSchema(), true
 	case PrimitiveTypeBool:
-		return openapi3.NewBoolSchema()
+		return openapi3.NewBoolSchema(), true
 	case PrimitiveTypeInteger:
-		return openapi3.NewIntegerSchema()
+		return openapi3.NewIntegerSchema(), true
 	case PrimitiveTypeFloat64:
-		return openapi3.NewFloat64Schema()
+		return openapi3.NewFloat64Schema(), true
 	case "":
-		return openapi3.NewStringSchema()
+		return openapi3.NewStringSchema(), true
 	default:
+		if s, ok := getRegisteredPrimitiveSchema(paramType); ok {
+			return s, true
+		}
 		return &openapi3.Schema{
 			Type: &openapi3.Types{string(paramType)},
-		}
+		}, false
 	}
 }
 
 func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
-	spec = newSpec(api.Name)
-	// Add all the routes.
-	for pattern, methodToRoute := range api.Routes {
-		path := &openapi3.PathItem{}
-		for method, route := range methodToRoute {
+	spec = newSpec(api)
+	// Components derived from models are only part of this spec.
+	api.derived = make(map[string]derivedComponent)
+	// Add all the routes, in a fixed order so that models are registered
+	// in the same order on every run.
+	for _, pattern := range getSortedKeys(api.Routes) {
+		methodToRoute := api.Routes[pattern]
+		// Patterns that only differ by querystring share a path.
+		path := spec.Paths.Value(getPath(pattern))
+		if path == nil {
+			path = &openapi3.PathItem{}
+		}
+		for _, method := range getSortedMethods(methodToRoute) {
+			route := methodToRoute[method]
 			op := &openapi3.Operation{}
+			operation := string(method) + " " + getPath(pattern)
+
+			// Check batch endpoints before their models are used.
+			var batchStatus int
+			if route.Batch {
+				if batchStatus, err = checkBatchEndpoint(route); err != nil {
+					return spec, err
+				}
+			}
 
 			// Add the query params.
 			for _, k := range getSortedKeys(route.Params.Query) {
 				v := route.Params.Query[k]
 
-				ps := newPrimitiveSchema(v.Type).
-					WithPattern(v.Regexp)
+				ps, ok := newPrimitiveSchema(v.Type)
+				if !ok {
+					api.warn(WarningUnknownParameterType, operation, "query parameter %q has unknown type %q", k, v.Type)
+				}
+				// Keep the pattern and default of registered primitive types.
+				if v.Regexp != "" {
+					ps.WithPattern(v.Regexp)
+				}
+				if v.Default != nil {
+					ps.WithDefault(v.Default)
+				}
 				queryParam := openapi3.NewQueryParameter(k).
 					WithDescription(v.Description).
 					WithSchema(ps)
 				queryParam.Required = v.Required
 				queryParam.AllowEmptyValue = v.AllowEmpty
+				queryParam.Example = v.Example
 
 				// Apply schema customisation.
 				if v.ApplyCustomSchema != nil {
@@ -87,15 +157,40 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 				op.AddParameter(queryParam)
 			}
 
+			// Add the fields parameter.
+			if route.FieldsModel != nil {
+				if err = api.addFieldsParameter(op, *route.FieldsModel); err != nil {
+					return spec, err
+				}
+			}
+
+			// Add the sort parameter.
+			if route.Sort != nil {
+				if err = api.addSortParameter(op, *route.Sort); err != nil {
+					return spec, err
+				}
+			}
+
 			// Add the route params.
 			for _, k := range getSortedKeys(route.Params.Path) {
 				v := route.Params.Path[k]
 
-				ps := newPrimitiveSchema(v.Type).
-					WithPattern(v.Regexp)
+				ps, ok := newPrimitiveSchema(v.Type)
+				if !ok {
+					api.warn(WarningUnknownParameterType, operation, "path parameter %q has unknown type %q", k, v.Type)
+				}
+				// Keep the pattern and default of registered primitive types. Patterns
+				// that the integer type was inferred from, e.g. \d+, only apply to strings.
+				if v.Regexp != "" && !(v.Type == PrimitiveTypeInteger && getPrimitiveTypeOfRegexp(v.Regexp) == PrimitiveTypeInteger) {
+					ps.WithPattern(v.Regexp)
+				}
+				if v.Default != nil {
+					ps.WithDefault(v.Default)
+				}
 				pathParam := openapi3.NewPathParameter(k).
 					WithDescription(v.Description).
 					WithSchema(ps)
+				pathParam.Example = v.Example
 
 				// Apply schema customisation.
 				if v.ApplyCustomSchema != nil {
@@ -107,34 +202,63 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 
 			// Handle request types.
 			if route.Models.Request.Type != nil {
-				name, schema, err := api.RegisterModel(route.Models.Request)
+				ctx := SchemaContext{API: api, Route: route, Position: SchemaPositionRequest}
+				ref, err := api.getOperationSchemaRef(ctx, route.Models.Request)
 				if err != nil {
 					return spec, err
 				}
 				op.RequestBody = &openapi3.RequestBodyRef{
-					Value: openapi3.NewRequestBody().WithContent(map[string]*openapi3.MediaType{
-						"application/json": {
-							Schema: getSchemaReferenceOrValue(name, schema),
-						},
-					}),
+					Value: openapi3.NewRequestBody().
+						WithDescription(route.RequestBody.Description).
+						WithRequired(route.RequestBody.Required).
+						WithContent(getContent(route, route.Models.Request, ref)),
 				}
 			}
 
 			// Handle response types.
-			for status, model := range route.Models.Responses {
-				name, schema, err := api.RegisterModel(model)
+			for _, status := range getSortedKeys(route.Models.Responses) {
+				model := route.Models.Responses[status]
+				ctx := SchemaContext{API: api, Route: route, Position: SchemaPositionResponse, Status: status}
+				ref, err := api.getOperationSchemaRef(ctx, model)
 				if err != nil {
 					return spec, err
 				}
+				if api.HALLinks {
+					if ref, err = api.withHALLinks(ref); err != nil {
+						return spec, err
+					}
+				}
+				if envelope, ok := api.getResponseEnvelope(route, status); ok {
+					if ref, err = api.withResponseEnvelope(*envelope, ref); err != nil {
+						return spec, err
+					}
+				}
+				description := route.ResponseDescriptions[status]
+				if description == "" {
+					api.warn(WarningMissingResponseDescription, operation, "response %d has no description", status)
+				}
 				resp := openapi3.NewResponse().
-					WithDescription("").
-					WithContent(map[string]*openapi3.MediaType{
-						"application/json": {
-							Schema: getSchemaReferenceOrValue(name, schema),
-						},
-					})
+					WithDescription(description).
+					WithContent(getContent(route, model, ref))
 				op.AddResponse(status, resp)
 			}
+			if route.Batch {
+				addMultiStatusResponse(op, route, batchStatus)
+			}
+
+			// Handle error codes.
+			if len(route.ErrorCodes) > 0 {
+				if err = api.addErrorCodeResponses(op, route, operation); err != nil {
+					return spec, err
+				}
+			}
+
+			// Document the response of validation middleware.
+			if api.ValidationErrorResponse != nil && hasRequestConstraints(route) {
+				if err = api.addValidationErrorResponse(spec, op); err != nil {
+					return spec, err
+				}
+			}
 
 			// Handle tags.
 			op.Tags = append(op.Tags, route.Tags...)
@@ -145,19 +269,76 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 			// Handle description.
 			op.Description = route.Description
 
+			// Handle rate limits.
+			if route.RateLimit != nil {
+				addRateLimitHeaders(op, *route.RateLimit)
+			}
+
+			// Handle idempotency keys.
+			if route.IdempotencyKeyRequired {
+				addIdempotencyKey(spec, op)
+			}
+
+			// Handle conditional requests.
+			if route.ConditionalRequests {
+				addConditionalRequests(spec, method, op)
+			}
+
+			// Handle security.
+			if len(route.Security) > 0 {
+				if op.Security, err = api.getSecurityRequirements(operation, route.Security); err != nil {
+					return spec, err
+				}
+			}
+
+			// Handle extensions.
+			if len(route.Extensions) > 0 {
+				op.Extensions = maps.Clone(route.Extensions)
+			}
+
 			// Register the method.
 			path.SetOperation(string(method), op)
 		}
 
-		// Populate the OpenAPI schemas from the models.
-		for name, schema := range api.models {
-			spec.Components.Schemas[name] = openapi3.NewSchemaRef("", schema)
-		}
+		spec.Paths.Set(getPath(pattern), path)
+	}
+
+	// Document the CORS policy.
+	if api.CORS != nil {
+		api.applyCORS(spec)
+	}
+
+	api.addSecuritySchemes(spec)
+
+	// Describe tags using package comments.
+	if err = api.addTagDescriptions(spec); err != nil {
+		return spec, err
+	}
+
+	// Add the catalog of error codes.
+	if err = api.addErrorCatalog(spec); err != nil {
+		return spec, err
+	}
+
+	// Populate the OpenAPI schemas from the models.
+	for _, name := range getSortedKeys(api.models) {
+		spec.Components.Schemas[name] = openapi3.NewSchemaRef("", api.models[name])
+	}
+	if err = api.addDerivedComponents(spec); err != nil {
+		return spec, err
+	}
 
-		spec.Paths.Set(string(pattern), path)
+	// Split schemas into request and response variants.
+	if api.SplitReadWriteSchemas {
+		if err = splitReadWriteSchemas(spec); err != nil {
+			return spec, err
+		}
 	}
 
 	loader := openapi3.NewLoader()
+	if len(api.externalRefs) > 0 {
+		api.allowExternalRefs(loader)
+	}
 	if err = loader.ResolveRefsIn(spec, nil); err != nil {
 		return spec, fmt.Errorf("failed to resolve, due to external references: %w", err)
 	}
@@ -169,31 +350,191 @@ func (api *API) createOpenAPI() (spec *openapi3.T, err error) {
 }
 
 func (api *API) getModelName(t reflect.Type) string {
+	if name, ok := api.modelNames[t]; ok {
+		return name
+	}
+	// Names are requested for every use of a type, so they're only computed once.
+	if api.modelNameCache == nil {
+		api.resetModelNameCache()
+	}
+	if name, ok := api.modelNameCache[t]; ok {
+		return name
+	}
+	name := api.createModelName(t)
+	api.modelNameCache[t] = name
+	return name
+}
+
+// resetModelNameCache discards the cached schema names if StripPkgPaths has changed since
+// they were created. It's called when models are registered, rather than for each name.
+func (api *API) resetModelNameCache() {
+	if api.modelNameCache != nil && slices.Equal(api.modelNameCachePkgPaths, api.StripPkgPaths) {
+		return
+	}
+	api.modelNameCache = make(map[reflect.Type]string)
+	api.modelNameCachePkgPaths = slices.Clone(api.StripPkgPaths)
+}
+
+func (api *API) createModelName(t reflect.Type) string {
 	pkgPath, typeName := t.PkgPath(), t.Name()
 	if t.Kind() == reflect.Pointer {
 		pkgPath = t.Elem().PkgPath()
 		typeName = t.Elem().Name() + "Ptr"
 	}
-	if t.Kind() == reflect.Map {
+	if t.Kind() == reflect.Map && t.Name() == "" {
 		typeName = fmt.Sprintf("map[%s]%s", t.Key().Name(), t.Elem().Name())
 	}
 	schemaName := api.normalizeTypeName(pkgPath, typeName)
 	if typeName == "" {
-		schemaName = fmt.Sprintf("AnonymousType%d", len(api.models))
+		// Name anonymous types after their structure, so that registering
+		// other models doesn't rename them.
+		h := fnv.New32a()
+		writeTypeID(h, t)
+		schemaName = fmt.Sprintf("AnonymousType%08x", h.Sum32())
 	}
 	return schemaName
 }
 
-func getSchemaReferenceOrValue(name string, schema *openapi3.Schema) *openapi3.SchemaRef {
-	if shouldBeReferenced(schema) {
+// writeTypeID writes a description of the structure of the type. Unlike t.String(),
+// it includes the package paths of named types and unexported fields, so that
+// structures that use types from different packages with the same name differ.
+func writeTypeID(w io.Writer, t reflect.Type) {
+	if t.Name() != "" {
+		fmt.Fprintf(w, "%s.%s", t.PkgPath(), t.Name())
+		return
+	}
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice:
+		fmt.Fprintf(w, "%v(", t.Kind())
+		writeTypeID(w, t.Elem())
+		io.WriteString(w, ")")
+	case reflect.Array:
+		fmt.Fprintf(w, "array[%d](", t.Len())
+		writeTypeID(w, t.Elem())
+		io.WriteString(w, ")")
+	case reflect.Map:
+		io.WriteString(w, "map(")
+		writeTypeID(w, t.Key())
+		io.WriteString(w, ",")
+		writeTypeID(w, t.Elem())
+		io.WriteString(w, ")")
+	case reflect.Struct:
+		io.WriteString(w, "struct{")
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			fmt.Fprintf(w, "%s.%s %t ", f.PkgPath, f.Name, f.Anonymous)
+			writeTypeID(w, f.Type)
+			fmt.Fprintf(w, " %q;", f.Tag)
+		}
+		io.WriteString(w, "}")
+	default:
+		io.WriteString(w, t.String())
+	}
+}
+
+func (api *API) getSchemaReferenceOrValue(name string, schema *openapi3.Schema) *openapi3.SchemaRef {
+	if ref, ok := api.getExternalRef(name); ok {
+		return openapi3.NewSchemaRef(ref, nil)
+	}
+	// Schemas registered as components are referenced.
+	if api.models[name] == schema {
 		return openapi3.NewSchemaRef(fmt.Sprintf("#/components/schemas/%s", name), nil)
 	}
 	return openapi3.NewSchemaRef("", schema)
 }
 
+// getOperationSchemaRef returns the schema of a model used by a route. If the route,
+// or the model's ApplyContextualSchema method, customises the model, the customisation
+// is applied to a copy of the schema, which is inlined, so that it doesn't affect other
+// uses of the model.
+func (api *API) getOperationSchemaRef(ctx SchemaContext, model Model) (*openapi3.SchemaRef, error) {
+	name, schema, err := api.RegisterModel(model)
+	if err != nil {
+		return nil, err
+	}
+	ref := api.getSchemaReferenceOrValue(name, schema)
+	visited := map[string]bool{name: true}
+	if contextualFields := api.hasContextualFields(schema, visited); len(model.opts) > 0 || model.sCtx != nil || contextualFields {
+		if schema, err = cloneSchema(schema); err != nil {
+			return nil, fmt.Errorf("failed to copy schema %q: %w", name, err)
+		}
+		if model.sCtx != nil {
+			model.sCtx(ctx, schema)
+		}
+		if contextualFields {
+			if err = api.applyContextualFields(ctx, schema, "", visited); err != nil {
+				return nil, err
+			}
+		}
+		if err = api.applyModelOpts(schema, model.opts); err != nil {
+			return nil, err
+		}
+		ref = openapi3.NewSchemaRef("", schema)
+	}
+	if model.wrap != nil {
+		return model.wrap(api, ref)
+	}
+	return ref, nil
+}
+
+// wrapRef wraps a referenced schema in allOf, so that keywords such as enum or x-unit can
+// be added to it, since they're ignored next to a $ref. Inline schemas are returned as
+// they are.
+func wrapRef(ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+	if ref.Ref == "" {
+		return ref
+	}
+	return openapi3.NewSchemaRef("", &openapi3.Schema{
+		AllOf: openapi3.SchemaRefs{ref},
+	})
+}
+
+// getComponentName returns the name of the component schema that the reference points to.
+func getComponentName(ref string) string {
+	return strings.TrimPrefix(ref, "#/components/schemas/")
+}
+
 // ModelOpts defines options that can be set when registering a model.
 type ModelOpts func(s *openapi3.Schema)
 
+func (o ModelOpts) applyToSchema(_ *API, s *openapi3.Schema) error {
+	o(s)
+	return nil
+}
+
+func (o ModelOpts) applyToRequest(r *Route) {
+	r.Models.Request.opts = append(slices.Clip(r.Models.Request.opts), o)
+}
+
+// ModelOptsErr defines options that can be set when registering a model, and that can
+// fail, e.g. if the schema doesn't have the property that they customise. The API is
+// used to resolve referenced schemas. They're passed to OverrideModel, or to the models
+// of routes, whose errors are returned by Spec.
+type ModelOptsErr func(api *API, s *openapi3.Schema) error
+
+func (o ModelOptsErr) applyToSchema(api *API, s *openapi3.Schema) error {
+	return o(api, s)
+}
+
+func (o ModelOptsErr) applyToRequest(r *Route) {
+	r.Models.Request.opts = append(slices.Clip(r.Models.Request.opts), o)
+}
+
+// SchemaOpts customise the schema of a model. ModelOpts and ModelOptsErr are SchemaOpts.
+type SchemaOpts interface {
+	applyToSchema(api *API, s *openapi3.Schema) error
+}
+
+// applyModelOpts applies the options to the schema in order.
+func (api *API) applyModelOpts(s *openapi3.Schema, opts []SchemaOpts) error {
+	for _, opt := range opts {
+		if err := opt.applyToSchema(api, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // WithNullable sets the nullable field to true.
 func WithNullable() ModelOpts {
 	return func(s *openapi3.Schema) {
@@ -208,6 +549,20 @@ func WithDescription(desc string) ModelOpts {
 	}
 }
 
+// WithExample sets the example field on the schema.
+func WithExample(example any) ModelOpts {
+	return func(s *openapi3.Schema) {
+		s.Example = example
+	}
+}
+
+// WithTitle sets the title field on the schema.
+func WithTitle(title string) ModelOpts {
+	return func(s *openapi3.Schema) {
+		s.Title = title
+	}
+}
+
 // WithEnumValues sets the property to be an enum value with the specific values.
 func WithEnumValues[T ~string | constraints.Integer](values ...T) ModelOpts {
 	return func(s *openapi3.Schema) {
@@ -225,6 +580,7 @@ func WithEnumValues[T ~string | constraints.Integer](values ...T) ModelOpts {
 }
 
 // WithEnumConstants sets the property to be an enum containing the values of the type found in the package.
+// The comments of the constants are added as x-enum-descriptions.
 func WithEnumConstants[T ~string | constraints.Integer]() ModelOpts {
 	return func(s *openapi3.Schema) {
 		var t T
@@ -233,15 +589,183 @@ func WithEnumConstants[T ~string | constraints.Integer]() ModelOpts {
 		if ty.Kind() != reflect.String {
 			s.Type = &openapi3.Types{openapi3.TypeInteger}
 		}
-		enum, err := enums.Get(ty)
+		constants, err := enums.GetConstants(ty)
 		if err != nil {
 			panic(err)
 		}
-		s.Enum = enum
+		s.Enum = nil
+		descriptions := make([]string, len(constants))
+		var documented bool
+		for i, c := range constants {
+			s.Enum = append(s.Enum, c.Value)
+			descriptions[i] = c.Doc
+			documented = documented || c.Doc != ""
+		}
+		// Describe the meaning of each value, e.g. for integer enums.
+		if documented {
+			if s.Extensions == nil {
+				s.Extensions = make(map[string]any)
+			}
+			s.Extensions[enumDescriptionsExtension] = descriptions
+		}
+	}
+}
+
+// enumDescriptionsExtension contains the descriptions of the values of an enum, in the
+// same order as the values.
+const enumDescriptionsExtension = "x-enum-descriptions"
+
+// structField is a field of a struct, or a field promoted from an embedded struct.
+type structField struct {
+	name string
+	// tagged is true if the name is set by a json tag.
+	tagged bool
+	// index of the field, as used by reflect.Type.FieldByIndex.
+	index []int
+}
+
+// getJSONFieldName returns the name of the field in JSON, and whether it was set by a json tag.
+func getJSONFieldName(f reflect.StructField) (name string, tagged bool) {
+	name, _, _ = strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name, false
+	}
+	return name, true
+}
+
+// getTagDescription returns the description set by the description or doc tag of
+// the field, which takes precedence over the field's comments.
+func getTagDescription(f reflect.StructField) (description string, ok bool) {
+	if description, ok = f.Tag.Lookup("description"); ok {
+		return description, ok
+	}
+	return f.Tag.Lookup("doc")
+}
+
+// wrapDocTagRef wraps referenced schemas in allOf if the field has a title or description
+// tag, since they can't be added next to a $ref.
+func wrapDocTagRef(f reflect.StructField, ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+	_, hasTitle := f.Tag.Lookup("title")
+	if _, hasDescription := getTagDescription(f); !hasTitle && !hasDescription {
+		return ref
+	}
+	return wrapRef(ref)
+}
+
+// isSkipped returns true if the field is ignored by encoding/json.
+func isSkipped(f reflect.StructField) bool {
+	return f.Tag.Get("json") == "-"
+}
+
+// isPromoted returns true if the fields of the embedded struct, or struct pointer
+// field are promoted to the parent struct in JSON.
+func isPromoted(f reflect.StructField) bool {
+	_, tagged := getJSONFieldName(f)
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
 	}
+	return f.Anonymous && !tagged && t.Kind() == reflect.Struct
 }
 
-func isFieldRequired(isPointer, hasOmitEmpty bool) bool {
+// getStructFields returns the fields of the struct, including fields promoted from
+// embedded structs, ordered by depth.
+func (api *API) getStructFields(t reflect.Type) (fields []structField) {
+	type embedded struct {
+		t     reflect.Type
+		index []int
+	}
+	current := []embedded{{t: t}}
+	visited := map[reflect.Type]bool{}
+	for len(current) > 0 {
+		var next []embedded
+		for _, e := range current {
+			if visited[e.t] {
+				continue
+			}
+			visited[e.t] = true
+			for i := 0; i < e.t.NumField(); i++ {
+				f := e.t.Field(i)
+				if !f.IsExported() || isSkipped(f) {
+					continue
+				}
+				if f.Anonymous && f.Type.Kind() == reflect.Interface && api.EmbeddedInterfacePolicy == EmbeddedInterfaceSkip {
+					continue
+				}
+				index := append(slices.Clone(e.index), i)
+				if isPromoted(f) {
+					ft := f.Type
+					if ft.Kind() == reflect.Pointer {
+						ft = ft.Elem()
+					}
+					next = append(next, embedded{t: ft, index: index})
+					continue
+				}
+				name, tagged := getJSONFieldName(f)
+				fields = append(fields, structField{name: name, tagged: tagged, index: index})
+			}
+		}
+		current = next
+	}
+	return fields
+}
+
+// getDominantFields applies Go's rules for embedded fields to find the field that is
+// used for each name. The shallowest field wins, and if there are multiple fields at
+// the same depth, a field named by a json tag wins. Otherwise, the name is ambiguous.
+func getDominantFields(fields []structField) (dominant map[string]structField, ambiguous []string) {
+	dominant = make(map[string]structField)
+	byName := make(map[string][]structField)
+	for _, f := range fields {
+		byName[f.name] = append(byName[f.name], f)
+	}
+	for _, name := range getSortedKeys(byName) {
+		candidates := byName[name]
+		// Fields are ordered by depth, so only the first depth is considered.
+		depth := len(candidates[0].index)
+		var shallowest, tagged []structField
+		for _, f := range candidates {
+			if len(f.index) != depth {
+				break
+			}
+			shallowest = append(shallowest, f)
+			if f.tagged {
+				tagged = append(tagged, f)
+			}
+		}
+		switch {
+		case len(shallowest) == 1:
+			dominant[name] = shallowest[0]
+		case len(tagged) == 1:
+			dominant[name] = tagged[0]
+		default:
+			ambiguous = append(ambiguous, name)
+		}
+	}
+	return dominant, ambiguous
+}
+
+// checkRequired checks that the required properties of an object schema exist, e.g. to
+// catch typos, or json tags that were renamed, in ApplyCustomSchema methods. Schemas that
+// allow additional properties, or are composed from other schemas, are not checked.
+func checkRequired(s *openapi3.Schema) error {
+	if len(s.Required) == 0 || !s.Type.Is(openapi3.TypeObject) ||
+		s.AdditionalProperties.Schema != nil || (s.AdditionalProperties.Has != nil && *s.AdditionalProperties.Has) ||
+		len(s.AllOf) > 0 || len(s.AnyOf) > 0 || len(s.OneOf) > 0 {
+		return nil
+	}
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok {
+			return fmt.Errorf("required property %q is not a property of the schema, properties are %s", name, strings.Join(getSortedKeys(s.Properties), ", "))
+		}
+	}
+	return nil
+}
+
+func (api *API) isFieldRequired(isPointer, hasOmitEmpty bool) bool {
+	if api.OptionalityPolicy == OptionalityOmitEmpty {
+		return !hasOmitEmpty
+	}
 	return !(isPointer || hasOmitEmpty)
 }
 
@@ -256,16 +780,91 @@ func isMarkedAsDeprecated(comment string) bool {
 	return false
 }
 
+// OverrideModel customises the schema of a model after it's generated, wherever the
+// model is used, including when it's only used by the fields of other models.
+// If the model has already been registered, the overrides are applied to it immediately.
+// Errors of ModelOptsErr overrides are returned by RegisterModel, or by Spec.
+func (api *API) OverrideModel(model Model, overrides ...SchemaOpts) {
+	t := model.Type
+	api.overrides[t] = append(api.overrides[t], overrides...)
+	api.resetModelNameCache()
+	name := api.getModelName(t)
+	if schema, ok := api.models[name]; ok && api.modelTypes[name] == t {
+		if err := api.applyModelOpts(schema, overrides); err != nil {
+			api.errs = append(api.errs, &ModelError{Path: t.String(), Type: t, Err: err})
+		}
+	}
+}
+
 // RegisterModel allows a model to be registered manually so that additional configuration can be applied.
 // The schema returned can be modified as required.
 func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, schema *openapi3.Schema, err error) {
+	r := &registration{
+		inProgress: make(map[reflect.Type]bool),
+		path:       []string{model.Type.String()},
+	}
+	api.resetModelNameCache()
+	return api.registerModel(r, model, opts...)
+}
+
+// registration is the state of a single call to RegisterModel, as it recurses
+// into the fields and elements of the model.
+type registration struct {
+	// inProgress contains the types that are being registered. The value is true if
+	// the type has been used recursively, and so must be a reference.
+	inProgress map[reflect.Type]bool
+	// embedded is the type of the embedded struct that's about to be registered, whose
+	// ambiguous fields may be resolved by the struct that embeds it.
+	embedded reflect.Type
+	// anyValues allows interfaces, which are documented as any value, e.g. in the data
+	// property of a response envelope, which is replaced.
+	anyValues bool
+	// path from the root model to the type being registered, e.g. ["models.User", ".Addresses", "[]"].
+	path []string
+}
+
+// pushPath adds a segment to the path of the type being registered.
+func (r *registration) pushPath(segment string) {
+	r.path = append(r.path, segment)
+}
+
+// popPath removes the last segment from the path of the type being registered.
+func (r *registration) popPath() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+func (r *registration) getPath() string {
+	return strings.Join(r.path, "")
+}
+
+func (api *API) registerModel(r *registration, model Model, opts ...ModelOpts) (name string, schema *openapi3.Schema, err error) {
 	// Get the name.
 	t := model.Type
 	name = api.getModelName(t)
 
+	// Errors are returned with the path to the type that caused them.
+	defer func() {
+		var modelErr *ModelError
+		if err != nil && !errors.As(err, &modelErr) {
+			err = &ModelError{Path: r.getPath(), Type: t, Err: err}
+		}
+	}()
+
+	// Models in other documents are referenced, not generated.
+	if _, ok := api.externalRefs[t]; ok {
+		api.modelTypes[name] = t
+		return name, &openapi3.Schema{}, nil
+	}
+
 	// If we've already got the schema, return it.
 	var ok bool
 	if schema, ok = api.models[name]; ok {
+		if registeredType, ok := api.modelTypes[name]; ok && registeredType != t {
+			api.warn(WarningNameCollision, name, "types %q and %q have the same schema name", registeredType, t)
+		}
+		if _, inProgress := r.inProgress[t]; inProgress {
+			r.inProgress[t] = true
+		}
 		return name, schema, nil
 	}
 
@@ -273,37 +872,67 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 	if knownSchema, ok := api.KnownTypes[t]; ok {
 		// Objects, enums, need to be references, so add it into the
 		// list.
-		if shouldBeReferenced(&knownSchema) {
+		if api.shouldBeReferenced(t, &knownSchema) {
 			api.models[name] = &knownSchema
 		}
 		return name, &knownSchema, nil
 	}
 
-	// We already saw this model but did not add a schema yet: recursion detected
-	// At this moment there is no schema definition yet, but we can leave the handling to getSchemaReferenceOrValue on top level
-	if slices.Contains([]reflect.Kind{
-		reflect.Struct,
-	}, t.Kind()) {
-		if ok := api.visitedModels[t.String()]; ok {
-			scm := openapi3.Schema{
-				Type: &openapi3.Types{openapi3.TypeObject},
+	// Nullable database types, e.g. pgtype.Text, are documented like pointers.
+	if api.SQLCModels {
+		if vt, ok := getNullValueType(t); ok {
+			// UUIDs are stored as bytes, e.g. by pgtype.UUID, but marshalled as strings.
+			if vt == reflect.TypeOf([16]byte{}) {
+				return name, openapi3.NewUUIDSchema().WithNullable(), nil
 			}
-			return name, &scm, nil
-		} else {
-			api.visitedModels[t.String()] = true
+			if name, schema, err = api.registerModel(r, modelFromType(vt)); err != nil {
+				return name, schema, err
+			}
+			if api.models[name] != schema {
+				schema.Nullable = true
+			}
+			return name, schema, nil
 		}
 	}
 
+	// Recursive types that aren't structs, e.g. type List []List, are not registered
+	// until they're complete. Register a placeholder so that the recursive use is a
+	// reference, and replace it with the complete schema once it's available.
+	if _, inProgress := r.inProgress[t]; inProgress {
+		r.inProgress[t] = true
+		schema = &openapi3.Schema{}
+		api.models[name] = schema
+		api.modelTypes[name] = t
+		return name, schema, nil
+	}
+	r.inProgress[t] = false
+	defer delete(r.inProgress, t)
+
+	// Remove partially registered structs on error.
+	defer func() {
+		if err != nil && t.Kind() == reflect.Struct {
+			delete(api.models, name)
+		}
+	}()
+
 	var elementName string
 	var elementSchema *openapi3.Schema
 	switch t.Kind() {
 	case reflect.Slice, reflect.Array:
-		elementName, elementSchema, err = api.RegisterModel(modelFromType(t.Elem()))
+		r.pushPath("[]")
+		elementName, elementSchema, err = api.registerModel(r, modelFromType(t.Elem()))
+		r.popPath()
 		if err != nil {
-			return name, schema, fmt.Errorf("error getting schema of slice element %v: %w", t.Elem(), err)
+			return name, schema, err
+		}
+		schema = openapi3.NewArraySchema()
+		schema.Items = api.getSchemaReferenceOrValue(elementName, elementSchema)
+		if t.Kind() == reflect.Array {
+			// Arrays have a fixed length, and can't be nil.
+			schema.WithMinItems(int64(t.Len())).WithMaxItems(int64(t.Len()))
+		} else {
+			schema.WithNullable() // Slices are always nilable in Go.
 		}
-		schema = openapi3.NewArraySchema().WithNullable() // Arrays are always nilable in Go.
-		schema.Items = getSchemaReferenceOrValue(elementName, elementSchema)
 	case reflect.String:
 		schema = openapi3.NewStringSchema()
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
@@ -312,8 +941,19 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 		schema = openapi3.NewFloat64Schema()
 	case reflect.Bool:
 		schema = openapi3.NewBoolSchema()
+	case reflect.Interface:
+		if r.anyValues {
+			schema = openapi3.NewSchema()
+		}
 	case reflect.Pointer:
-		name, schema, err = api.RegisterModel(modelFromType(t.Elem()), WithNullable())
+		name, schema, err = api.registerModel(r, modelFromType(t.Elem()))
+		if err != nil {
+			return name, schema, err
+		}
+		// Components are shared with the element type, so only inline schemas are made nullable.
+		if api.models[name] != schema {
+			schema.Nullable = true
+		}
 	case reflect.Map:
 		// Check that the key is a string.
 		if t.Key().Kind() != reflect.String {
@@ -321,65 +961,189 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 		}
 
 		// Get the element schema.
-		elementName, elementSchema, err = api.RegisterModel(modelFromType(t.Elem()))
+		r.pushPath("[]")
+		elementName, elementSchema, err = api.registerModel(r, modelFromType(t.Elem()))
+		r.popPath()
 		if err != nil {
-			return name, schema, fmt.Errorf("error getting schema of map value element %v: %w", t.Elem(), err)
+			return name, schema, err
 		}
 		schema = openapi3.NewObjectSchema().WithNullable()
-		schema.AdditionalProperties.Schema = getSchemaReferenceOrValue(elementName, elementSchema)
+		schema.AdditionalProperties.Schema = api.getSchemaReferenceOrValue(elementName, elementSchema)
 	case reflect.Struct:
 		schema = openapi3.NewObjectSchema()
+		// Register the schema before adding the fields, so that recursive
+		// references to the type resolve to it.
+		api.models[name] = schema
+		api.modelTypes[name] = t
 		if schema.Description, schema.Deprecated, err = api.getTypeComment(t.PkgPath(), t.Name()); err != nil {
 			return name, schema, fmt.Errorf("failed to get comments for type %q: %w", name, err)
 		}
 		schema.Properties = make(openapi3.Schemas)
+		schema.XML = getXMLName(t)
+		// Find the fields that are promoted from embedded structs, following the rules of encoding/json.
+		dominantFields, ambiguousNames := getDominantFields(api.getStructFields(t))
+		// Only the promoted fields of the embedded struct itself may be resolved by its
+		// parent, not those of the types of its fields.
+		isEmbedded := r.embedded == t
+		r.embedded = nil
+		if len(ambiguousNames) > 0 && !isEmbedded {
+			return name, schema, fmt.Errorf("type %q has ambiguous fields %q, add json tags to resolve the ambiguity", t, ambiguousNames)
+		}
 		for i := 0; i < t.NumField(); i++ {
 			f := t.Field(i)
 			if !f.IsExported() {
+				if _, hasJSONTag := f.Tag.Lookup("json"); hasJSONTag {
+					api.warn(WarningSkippedField, t.String(), "field %q has a json tag, but is not exported", f.Name)
+				}
 				continue
 			}
 			// Get JSON fieldName.
 			jsonTags := strings.Split(f.Tag.Get("json"), ",")
-			fieldName := jsonTags[0]
-			if fieldName == "" {
-				fieldName = f.Name
+			if isSkipped(f) || (api.EntModels && isEntEdges(t, f)) {
+				continue
 			}
-			// If the model doesn't exist.
-			_, alreadyExists := api.models[api.getModelName(f.Type)]
-			fieldSchemaName, fieldSchema, err := api.RegisterModel(modelFromType(f.Type))
-			if err != nil {
-				return name, schema, fmt.Errorf("error getting schema for type %q, field %q, failed to get schema for embedded type %q: %w", t, fieldName, f.Type, err)
+			fieldName, _ := getJSONFieldName(f)
+			if f.Anonymous && f.Type.Kind() == reflect.Interface {
+				switch api.EmbeddedInterfacePolicy {
+				case EmbeddedInterfaceSkip:
+					continue
+				case EmbeddedInterfaceAny:
+					if _, ok := dominantFields[fieldName]; ok {
+						schema.Properties[fieldName] = openapi3.NewSchemaRef("", openapi3.NewSchema().WithNullable())
+					}
+					continue
+				default:
+					return name, schema, fmt.Errorf("type %q embeds interface %q, use WithEmbeddedInterfacePolicy to skip it, or allow any value", t, f.Type)
+				}
 			}
-			if f.Anonymous {
+			if isPromoted(f) {
+				embeddedType := f.Type
+				isPtr := embeddedType.Kind() == reflect.Pointer
+				if isPtr {
+					embeddedType = embeddedType.Elem()
+				}
+				// If the model doesn't exist.
+				_, alreadyExists := api.models[api.getModelName(embeddedType)]
+				// Ambiguous fields in embedded structs may be resolved by this struct.
+				r.embedded = embeddedType
+				r.pushPath("." + f.Name)
+				fieldSchemaName, fieldSchema, err := api.registerModel(r, modelFromType(embeddedType))
+				r.popPath()
+				r.embedded = nil
+				if err != nil {
+					return name, schema, withField(err, f)
+				}
 				// It's an anonymous type, no need for a reference to it,
 				// since we're copying the fields.
 				if !alreadyExists {
 					delete(api.models, fieldSchemaName)
+					delete(api.modelTypes, fieldSchemaName)
 				}
-				// Add all embedded fields to this type.
+				// Add the embedded fields that aren't shadowed to this type.
 				for name, ref := range fieldSchema.Properties {
-					schema.Properties[name] = ref
+					if df, ok := dominantFields[name]; ok && df.index[0] == i {
+						schema.Properties[name] = ref
+					}
 				}
-				schema.Required = append(schema.Required, fieldSchema.Required...)
+				// The fields of nil embedded pointers are omitted from JSON.
+				if isPtr && api.EmbeddedPointerPolicy == EmbeddedPointerOptional {
+					continue
+				}
+				for _, name := range fieldSchema.Required {
+					if df, ok := dominantFields[name]; ok && df.index[0] == i {
+						schema.Required = append(schema.Required, name)
+					}
+				}
+				continue
+			}
+			if df, ok := dominantFields[fieldName]; !ok || df.index[0] != i {
+				// The field is ambiguous.
 				continue
 			}
-			ref := getSchemaReferenceOrValue(fieldSchemaName, fieldSchema)
+			r.pushPath("." + f.Name)
+			fieldSchemaName, fieldSchema, err := api.registerModel(r, modelFromType(f.Type))
+			r.popPath()
+			var unsupported *UnsupportedTypeError
+			if api.SkipUnsupportedFields && errors.As(err, &unsupported) {
+				api.warn(WarningUnsupportedField, t.String(), "field %q skipped: %v", f.Name, err)
+				continue
+			}
+			if err != nil {
+				return name, schema, withField(err, f)
+			}
+			hasOmitEmptySet := slices.Contains(jsonTags, "omitempty")
+			ref := api.getSchemaReferenceOrValue(fieldSchemaName, fieldSchema)
+			// Nil pointers without omitempty are null, but OpenAPI 3.0 can't make a reference nullable.
+			if api.NullableReferences && ref.Ref != "" && f.Type.Kind() == reflect.Pointer && !hasOmitEmptySet {
+				ref = wrapRef(ref)
+				ref.Value.Nullable = true
+			}
+			if api.FormatInference {
+				ref = inferFormat(f, ref)
+			}
+			if ref, err = applyConstTag(f, ref); err != nil {
+				return name, schema, r.newFieldError(t, f, err)
+			}
+			if ref, err = applySetTag(f, ref); err != nil {
+				return name, schema, r.newFieldError(t, f, err)
+			}
+			if err = api.applyInt64Encoding(f, jsonTags, ref); err != nil {
+				return name, schema, r.newFieldError(t, f, err)
+			}
+			if ref, err = api.applySensitiveTag(f, ref); err != nil {
+				return name, schema, r.newFieldError(t, f, err)
+			}
+			ref = applyXMLTag(f, fieldName, ref)
+			ref = wrapUnitTagRef(f, ref)
+			ref = wrapDocTagRef(f, ref)
+			var dbc dbConstraints
+			if api.GormTagMapping {
+				if dbc, err = parseDBConstraints(f); err != nil {
+					return name, schema, r.newFieldError(t, f, err)
+				}
+			}
 			if ref.Value != nil {
+				// Nil values of omitempty fields are omitted, rather than being null.
+				if api.OptionalityPolicy == OptionalityOmitEmpty && hasOmitEmptySet {
+					ref.Value.Nullable = false
+				}
 				if ref.Value.Description, ref.Value.Deprecated, err = api.getTypeFieldComment(t.PkgPath(), t.Name(), f.Name); err != nil {
 					return name, schema, fmt.Errorf("failed to get comments for field %q in type %q: %w", fieldName, name, err)
 				}
+				if description, ok := getTagDescription(f); ok {
+					ref.Value.Description = description
+				}
+				if title, ok := f.Tag.Lookup("title"); ok {
+					ref.Value.Title = title
+				}
+				if err = applyContentTags(f, ref.Value); err != nil {
+					return name, schema, r.newFieldError(t, f, err)
+				}
+				applyFormatTag(f, ref.Value)
+				if err = applyUnitTag(f, ref.Value); err != nil {
+					return name, schema, r.newFieldError(t, f, err)
+				}
+				dbc.apply(ref.Value)
+				if api.EntModels && isEntEntity(t) {
+					if err = applyEntEnum(t, f, ref.Value); err != nil {
+						return name, schema, r.newFieldError(t, f, err)
+					}
+				}
+				// Apply global field customisation.
+				if api.ApplyCustomSchemaToField != nil {
+					api.ApplyCustomSchemaToField(t, f, ref.Value)
+				}
 			}
 			schema.Properties[fieldName] = ref
 			isPtr := f.Type.Kind() == reflect.Pointer
-			hasOmitEmptySet := slices.Contains(jsonTags, "omitempty")
-			if isFieldRequired(isPtr, hasOmitEmptySet) {
+			if api.isFieldRequired(isPtr, hasOmitEmptySet) || dbc.notNull {
 				schema.Required = append(schema.Required, fieldName)
 			}
 		}
 	}
 
 	if schema == nil {
-		return name, schema, fmt.Errorf("unsupported type: %v/%v", t.PkgPath(), t.Name())
+		return name, schema, &UnsupportedTypeError{Type: t, Path: r.getPath()}
 	}
 
 	// Apply global customisation.
@@ -387,19 +1151,41 @@ func (api *API) RegisterModel(model Model, opts ...ModelOpts) (name string, sche
 		api.ApplyCustomSchemaToType(t, schema)
 	}
 
-	// Customise the model using its ApplyCustomSchema method.
+	// Customise the model using its ApplyCustomSchema or ApplyCustomSchemaErr method.
 	// This allows any type to customise its schema.
-	model.ApplyCustomSchema(schema)
+	if err = model.applyCustomSchema(schema); err != nil {
+		return name, schema, fmt.Errorf("failed to customise schema: %w", err)
+	}
 
 	for _, opt := range opts {
 		opt(schema)
 	}
 
+	if err = api.applyModelOpts(schema, api.overrides[t]); err != nil {
+		return name, schema, err
+	}
+
+	if !api.SkipRequiredCheck {
+		if err = checkRequired(schema); err != nil {
+			return name, schema, err
+		}
+	}
+
 	// After all processing, register the type if required.
-	if shouldBeReferenced(schema) {
+	// Recursive types must be registered, since they reference themselves.
+	if api.shouldBeReferenced(t, schema) || r.inProgress[t] {
 		api.models[name] = schema
+		if t.Kind() != reflect.Pointer {
+			// Pointers share the schema of their element type.
+			api.modelTypes[name] = t
+		}
 		return
 	}
+	if t.Kind() == reflect.Struct {
+		// The schema was customised, or the InlinePolicy means it's no longer a reference.
+		delete(api.models, name)
+		delete(api.modelTypes, name)
+	}
 
 	return
 }
@@ -436,6 +1222,28 @@ func (api *API) getTypeFieldComment(pkg string, name string, field string) (comm
 	return
 }
 
+// inlineSmallMaxProperties is the maximum number of properties a struct can have
+// to be inlined by the InlineSmall policy.
+const inlineSmallMaxProperties = 3
+
+// shouldBeReferenced returns true if the schema of the type should be added to the
+// components of the specification, and referenced, according to the InlinePolicy.
+func (api *API) shouldBeReferenced(t reflect.Type, schema *openapi3.Schema) bool {
+	switch api.InlinePolicy {
+	case AlwaysReference:
+		return shouldBeReferenced(schema) || t.PkgPath() != "" || schema.Type.Is(openapi3.TypeObject)
+	case InlineSmall:
+		if t.Kind() == reflect.Struct && len(schema.Properties) <= inlineSmallMaxProperties && len(schema.Enum) == 0 {
+			return false
+		}
+	case InlineAnonymous:
+		if t.Kind() == reflect.Struct && t.Name() == "" {
+			return false
+		}
+	}
+	return shouldBeReferenced(schema)
+}
+
 func shouldBeReferenced(schema *openapi3.Schema) bool {
 	if schema.Type.Is(openapi3.TypeObject) && schema.AdditionalProperties.Schema == nil {
 		return true
@@ -443,6 +1251,10 @@ func shouldBeReferenced(schema *openapi3.Schema) bool {
 	if len(schema.Enum) > 0 {
 		return true
 	}
+	// Flag enums are enums, even though their values aren't listed in the enum.
+	if _, ok := schema.Extensions[flagValuesExtension]; ok {
+		return true
+	}
 	return false
 }
 
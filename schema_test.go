@@ -1,11 +1,18 @@
 package rest
 
 import (
+	"database/sql"
 	"embed"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"math/big"
+	"math/rand"
+	randv2 "math/rand/v2"
 	"net/http"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -14,6 +21,8 @@ import (
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/go-cmp/cmp"
+	"github.com/heimspiel/rest/getcomments/parser/tests/packagedoc"
+	"github.com/heimspiel/rest/internal/ent"
 	"gopkg.in/yaml.v2"
 )
 
@@ -116,6 +125,83 @@ type User struct {
 	Name string `json:"name"`
 }
 
+type WithOptionalFields struct {
+	Name           string   `json:"name"`
+	Nickname       string   `json:"nickname,omitempty"`
+	Manager        *User    `json:"manager"`
+	ManagerOmitted *User    `json:"managerOmitted,omitempty"`
+	Age            *int     `json:"age"`
+	AgeOmitted     *int     `json:"ageOmitted,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+}
+
+type Account struct {
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (Account) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Properties["id"].Value.ReadOnly = true
+	s.Properties["password"].Value.WriteOnly = true
+}
+
+func (Account) ApplyContextualSchema(ctx SchemaContext, s *openapi3.Schema) {
+	position := ctx.Position
+	if position == SchemaPositionField {
+		position = ctx.Body
+	}
+	for name, p := range s.Properties {
+		if (position == SchemaPositionRequest && p.Value.ReadOnly) || (position == SchemaPositionResponse && p.Value.WriteOnly) {
+			delete(s.Properties, name)
+			s.Required = slices.DeleteFunc(s.Required, func(r string) bool { return r == name })
+		}
+	}
+}
+
+type Team struct {
+	Name    string    `json:"name"`
+	Owner   Account   `json:"owner"`
+	Members []Account `json:"members"`
+}
+
+type Secret struct {
+	ID    string `json:"id"`
+	Value string `json:"value"`
+}
+
+func (Secret) ApplyCustomSchema(s *openapi3.Schema) {
+	s.Properties["id"].Value.ReadOnly = true
+	s.Properties["value"].Value.WriteOnly = true
+}
+
+type Vault struct {
+	Name    string   `json:"name"`
+	Secrets []Secret `json:"secrets"`
+	Owner   User     `json:"owner"`
+}
+
+// Admin has the properties that admins have in addition to those of users.
+type Admin struct {
+	Permissions []string `json:"permissions"`
+}
+
+type UUID [16]byte
+
+type WithFormats struct {
+	ID           UUID    `json:"id"`
+	ParentID     *UUID   `json:"parentId"`
+	Email        string  `json:"email"`
+	ContactEmail string  `json:"contactEmail"`
+	AvatarURL    string  `json:"avatarUrl"`
+	CreatedAt    string  `json:"createdAt"`
+	At           string  `json:"at"`
+	Hostname     string  `json:"hostname" format:"hostname"`
+	ReturnURL    string  `json:"returnUrl" format:"-"`
+	Name         string  `json:"name"`
+	Score        float64 `json:"score"`
+}
+
 type OK struct {
 	OK bool `json:"ok"`
 }
@@ -136,6 +222,204 @@ const (
 	IntEnum3 IntEnum = 3
 )
 
+type Priority int
+
+const (
+	// PriorityLow can wait until there's time.
+	PriorityLow    Priority = 1
+	PriorityMedium Priority = 2 // PriorityMedium should be done this week.
+	PriorityHigh   Priority = 3
+)
+
+type Permission uint8
+
+const (
+	PermissionRead Permission = 1 << iota
+	PermissionWrite
+	PermissionDelete
+	PermissionAll = PermissionRead | PermissionWrite | PermissionDelete
+)
+
+type WithConsts struct {
+	Kind     string   `json:"kind" const:"dog"`
+	Version  int      `json:"version" const:"2"`
+	Priority IntEnum  `json:"priority" const:"1"`
+	Ratio    *float64 `json:"ratio,omitempty" const:"0.5"`
+}
+
+type Coordinates [2]float64
+
+type WithArrays struct {
+	Position Coordinates   `json:"position"`
+	Path     []Coordinates `json:"path"`
+	Colour   [3]uint8      `json:"colour"`
+}
+
+type WithSets struct {
+	Permissions []string    `json:"permissions" set:"read,write,delete"`
+	Scopes      string      `json:"scopes" set:"user.read, user.write"`
+	Tags        *[]string   `json:"tags,omitempty" set:"a,b"`
+	Enums       StringEnums `json:"enums" set:"A,B"`
+}
+
+type StringEnums []StringEnum
+
+type WithEncodedContent struct {
+	Avatar    string  `json:"avatar" contentEncoding:"base64" contentMediaType:"image/png"`
+	Signature *string `json:"signature,omitempty" contentEncoding:"base64url"`
+	Document  string  `json:"document" contentMediaType:"application/pdf"`
+}
+
+type XMLOrder struct {
+	XMLName  xml.Name       `json:"-" xml:"urn:orders order"`
+	ID       string         `json:"id" xml:"id,attr"`
+	Customer XMLCustomer    `json:"customer" xml:"buyer"`
+	Lines    []XMLOrderLine `json:"lines" xml:"lines>line"`
+	Notes    string         `json:"notes" xml:",chardata"`
+	Tags     []string       `json:"tags" xml:"tag"`
+}
+
+type XMLCustomer struct {
+	Name string `json:"name" xml:"name"`
+}
+
+type XMLOrderLine struct {
+	SKU      string `json:"sku" xml:"sku,attr"`
+	Quantity int    `json:"quantity"`
+}
+
+type WithInt64s struct {
+	ID       int64         `json:"id"`
+	Checksum uint64        `json:"checksum"`
+	ParentID *int64        `json:"parentId"`
+	Related  []int64       `json:"related"`
+	Count    int64         `json:"count" int64:"integer"`
+	Version  int64         `json:"version" int64:"string-encoded"`
+	Sequence int64         `json:"sequence,string"`
+	Timeout  time.Duration `json:"timeout"`
+	Size     int           `json:"size"`
+}
+
+// Decimal is marshalled as a decimal string, like decimal.Decimal from
+// github.com/shopspring/decimal.
+type Decimal struct {
+	value *big.Int
+	exp   int32
+}
+
+type Invoice struct {
+	Total    Money    `json:"total"`
+	Tax      Decimal  `json:"tax"`
+	Discount *Decimal `json:"discount"`
+	Ratio    *big.Rat `json:"ratio"`
+}
+
+type Bytes int64
+
+type WithUnits struct {
+	// Timeout of the request.
+	Timeout  int     `json:"timeout" unit:"ms"`
+	Size     Bytes   `json:"size" unit:"bytes"`
+	Distance float64 `json:"distance" unit:"km" description:"Distance travelled."`
+	Speed    float64 `json:"speed"`
+}
+
+type Credentials struct {
+	Email    string        `json:"email" sensitive:"true"`
+	Password string        `json:"password" sensitive:"true"`
+	Address  PostalAddress `json:"address" sensitive:"true"`
+	Nickname string        `json:"nickname" sensitive:"false"`
+}
+
+type PostalAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type Product struct {
+	ID          uint64  `json:"id" gorm:"primaryKey"`
+	SKU         string  `json:"sku" gorm:"size:32;not null;uniqueIndex:idx_sku"`
+	Name        string  `json:"name,omitempty" gorm:"type:varchar(100);NOT NULL"`
+	Country     string  `json:"country,omitempty" gorm:"type:char(2)"`
+	Size        string  `json:"size,omitempty" db:"size"`
+	Description *string `json:"description,omitempty" gorm:"not null"`
+	Notes       string  `json:"notes,omitempty" gorm:"size:500" db:"unique"`
+	Internal    string  `json:"-" gorm:"size:10"`
+}
+
+// PGText is nullable text, like pgtype.Text.
+type PGText struct {
+	String string
+	Valid  bool
+}
+
+func (t PGText) MarshalJSON() ([]byte, error) {
+	if !t.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.String)
+}
+
+// PGUUID is a nullable UUID, like pgtype.UUID.
+type PGUUID struct {
+	Bytes [16]byte
+	Valid bool
+}
+
+func (u PGUUID) MarshalJSON() ([]byte, error) {
+	if !u.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(fmt.Sprintf("%x-%x-%x-%x-%x", u.Bytes[0:4], u.Bytes[4:6], u.Bytes[6:8], u.Bytes[8:10], u.Bytes[10:16]))
+}
+
+// NullTime marshals sql.NullTime as its value or null.
+type NullTime struct {
+	sql.NullTime
+}
+
+func (t NullTime) MarshalJSON() ([]byte, error) {
+	if !t.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Time)
+}
+
+type SQLCAuthor struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Bio      PGText   `json:"bio"`
+	TeamID   PGUUID   `json:"teamId"`
+	Born     NullTime `json:"born"`
+	Nickname *string  `json:"nickname"`
+}
+
+type Envelope struct {
+	Data  any            `json:"data"`
+	Error *EnvelopeError `json:"error,omitempty"`
+	Meta  map[string]any `json:"meta,omitempty"`
+}
+
+type EnvelopeError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ListEnvelope struct {
+	Data     any    `json:"data"`
+	NextPage string `json:"nextPage,omitempty"`
+}
+
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type FieldErrorsResponse struct {
+	Code   string            `json:"code"`
+	Fields map[string]string `json:"fields"`
+}
+
 type WithEnums struct {
 	S  StringEnum   `json:"s"`
 	SS []StringEnum `json:"ss"`
@@ -179,6 +463,29 @@ type StructWithTags struct {
 	A string `json:"a" rest:"A is a string."`
 }
 
+type WithTitles struct {
+	FirstName string `json:"firstName" title:"First name"`
+	LastName  string `json:"lastName"`
+	Manager   User   `json:"manager" title:"Manager" description:"The person's manager."`
+}
+
+type WithDescriptionTags struct {
+	// ID is overridden by the description tag.
+	ID   string `json:"id" description:"The ID of the record."`
+	Name string `json:"name" doc:"The name of the record."`
+	// Age of the record.
+	Age int `json:"age"`
+}
+
+type WithDurations struct {
+	Timeout  time.Duration  `json:"timeout"`
+	Interval *time.Duration `json:"interval"`
+}
+
+type WithOwner struct {
+	Owner User `json:"owner"`
+}
+
 type RecursiveModelModel struct {
 	Model *RecursiveModel `json:"model,omitempty"`
 	Bar   string          `json:"bar,omitempty"`
@@ -189,6 +496,93 @@ type RecursiveModel struct {
 	Foo       string               `json:"foo,omitempty"`
 }
 
+type TreeNode struct {
+	Name     string     `json:"name"`
+	Children []TreeNode `json:"children"`
+	Parent   *TreeNode  `json:"parent,omitempty"`
+}
+
+type RecursiveMap map[string]RecursiveMap
+
+type JSONObject map[string]JSONArray
+
+type JSONArray []JSONObject
+
+type MutuallyRecursive struct {
+	Map      RecursiveMap `json:"map"`
+	Object   JSONObject   `json:"object"`
+	Children []*MutuallyRecursiveChild
+}
+
+type MutuallyRecursiveChild struct {
+	Parents map[string]MutuallyRecursive
+}
+
+type ShadowInner struct {
+	ID   int `json:"id"`
+	Name string
+	Note string
+}
+
+type ShadowOther struct {
+	Name int `json:"Name"`
+	Note string
+}
+
+type WithShadowedFields struct {
+	ID string `json:"id"`
+	ShadowInner
+	ShadowOther
+	Note    string
+	Ignored string `json:"-"`
+}
+
+type AmbiguousA struct {
+	X string
+}
+
+type AmbiguousB struct {
+	X int
+}
+
+type WithAmbiguousFields struct {
+	AmbiguousA
+	AmbiguousB
+}
+
+// EmbedsFieldWithAmbiguousFields embeds a struct whose field has ambiguous fields,
+// which can't be resolved by this struct.
+type EmbedsFieldWithAmbiguousFields struct {
+	HasAmbiguousField
+}
+
+type HasAmbiguousField struct {
+	Ambiguous WithAmbiguousFields `json:"ambiguous"`
+}
+
+type WithResolvedAmbiguity struct {
+	WithAmbiguousFields
+	X bool
+}
+
+type WithEmbeddedPointers struct {
+	*EmbeddedStructA
+	*EmbeddedStructB
+	C string
+}
+
+type WithEmbeddedInterface struct {
+	fmt.Stringer
+	C string
+}
+
+type InlinePolicyModel struct {
+	Amount Pence              `json:"amount"`
+	Point  struct{ X, Y int } `json:"point"`
+	User   User               `json:"user"`
+	Names  WithNameStructTags `json:"names"`
+}
+
 func TestSchema(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -199,6 +593,17 @@ func TestSchema(t *testing.T) {
 			name:  "test000.yaml",
 			setup: func(api *API) error { return nil },
 		},
+		{
+			name: "info.yaml",
+			opts: []APIOpts{
+				WithVersion("v1.2.3"),
+				WithAPIDescription("Messages API"),
+				WithTermsOfService("https://example.com/terms"),
+				WithContact("API Support", "https://example.com/support", "support@example.com"),
+				WithLicense("MIT", "https://opensource.org/licenses/MIT"),
+			},
+			setup: func(api *API) error { return nil },
+		},
 		{
 			name: "test001.yaml",
 			setup: func(api *API) error {
@@ -249,6 +654,74 @@ func TestSchema(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "request-body.yaml",
+			setup: func(api *API) error {
+				api.Post("/users").
+					HasRequestModel(ModelOf[User](), RequestRequired(), RequestDescription("The user to create.")).
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+				return nil
+			},
+		},
+		{
+			name: "titles.yaml",
+			setup: func(api *API) error {
+				_, _, err := api.RegisterModel(ModelOf[WithTitles](), WithTitle("Person"))
+				api.Get("/person").
+					HasResponseModel(http.StatusOK, ModelOf[WithTitles]())
+				return err
+			},
+		},
+		{
+			name: "description-tags.yaml",
+			setup: func(api *API) error {
+				api.Get("/record").
+					HasResponseModel(http.StatusOK, ModelOf[WithDescriptionTags]())
+				return nil
+			},
+		},
+		{
+			name: "duration.yaml",
+			setup: func(api *API) error {
+				api.Get("/durations").
+					HasResponseModel(http.StatusOK, ModelOf[WithDurations]())
+				return nil
+			},
+		},
+		{
+			name: "duration-string.yaml",
+			opts: []APIOpts{
+				WithDurationFormat(DurationString),
+			},
+			setup: func(api *API) error {
+				api.Get("/durations").
+					HasResponseModel(http.StatusOK, ModelOf[WithDurations]())
+				return nil
+			},
+		},
+		{
+			name: "route-model-opts.yaml",
+			setup: func(api *API) error {
+				api.Post("/users").
+					HasRequestModel(ModelOf[User](), RequestRequired(), WithDescription("The user to create.")).
+					HasResponseModel(http.StatusOK, ModelOf[User](), WithExample(map[string]any{"id": 1, "name": "Alice"}))
+				api.Get("/users/{id}").
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+				return nil
+			},
+		},
+		{
+			name: "override-model.yaml",
+			setup: func(api *API) error {
+				api.OverrideModel(ModelOf[User](), ModelOpts(func(s *openapi3.Schema) {
+					s.Description = "A user of the system."
+					s.Properties["id"].Value.Example = 1
+				}))
+				api.Get("/owner").
+					HasResponseModel(http.StatusOK, ModelOf[WithOwner]())
+				return nil
+			},
+		},
 		{
 			name: "embedded-structs.yaml",
 			setup: func(api *API) error {
@@ -261,87 +734,232 @@ func TestSchema(t *testing.T) {
 			},
 		},
 		{
-			name: "with-name-struct-tags.yaml",
+			name: "embedded-struct-collisions.yaml",
 			setup: func(api *API) error {
 				api.Post("/test").
-					HasRequestModel(ModelOf[WithNameStructTags]()).
-					HasResponseModel(http.StatusOK, ModelOf[WithNameStructTags]())
+					HasRequestModel(ModelOf[WithShadowedFields]()).
+					HasResponseModel(http.StatusOK, ModelOf[WithResolvedAmbiguity]())
 				return nil
 			},
 		},
 		{
-			name: "known-types.yaml",
+			name: "embedded-pointers.yaml",
 			setup: func(api *API) error {
-				api.Route(http.MethodGet, "/test").
-					HasResponseModel(http.StatusOK, ModelOf[KnownTypes]())
+				api.Get("/embedded").
+					HasResponseModel(http.StatusOK, ModelOf[EmbeddedStructA]())
+				api.Post("/test").
+					HasResponseModel(http.StatusOK, ModelOf[WithEmbeddedPointers]())
 				return nil
 			},
 		},
 		{
-			name: "recursive-models.yaml",
+			name: "embedded-pointers-required.yaml",
+			opts: []APIOpts{
+				WithEmbeddedPointerPolicy(EmbeddedPointerRequired),
+			},
 			setup: func(api *API) error {
-				api.Get("/recursive-models").
-					HasResponseModel(http.StatusOK, ModelOf[RecursiveModel]())
+				api.Post("/test").
+					HasResponseModel(http.StatusOK, ModelOf[WithEmbeddedPointers]())
 				return nil
 			},
 		},
 		{
-			name: "all-methods.yaml",
-			setup: func(api *API) (err error) {
-				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[OK]())
-				api.Head("/head").HasResponseModel(http.StatusOK, ModelOf[OK]())
-				api.Post("/post").HasResponseModel(http.StatusOK, ModelOf[OK]())
-				api.Put("/put").HasResponseModel(http.StatusOK, ModelOf[OK]())
-				api.Patch("/patch").HasResponseModel(http.StatusOK, ModelOf[OK]())
-				api.Delete("/delete").HasResponseModel(http.StatusOK, ModelOf[OK]())
-				api.Connect("/connect").HasResponseModel(http.StatusOK, ModelOf[OK]())
-				api.Options("/options").HasResponseModel(http.StatusOK, ModelOf[OK]())
-				api.Trace("/trace").HasResponseModel(http.StatusOK, ModelOf[OK]())
-				return
+			name: "optionality-pointer-or-omitempty.yaml",
+			setup: func(api *API) error {
+				api.Post("/test").
+					HasResponseModel(http.StatusOK, ModelOf[WithOptionalFields]())
+				return nil
 			},
 		},
 		{
-			name: "enums.yaml",
-			setup: func(api *API) (err error) {
-				// Register the enums and values.
-				api.RegisterModel(ModelOf[StringEnum](), WithEnumValues(StringEnumA, StringEnumB, StringEnumC))
-				api.RegisterModel(ModelOf[IntEnum](), WithEnumValues(IntEnum1, IntEnum2, IntEnum3))
-
-				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[WithEnums]())
-				return
+			name: "optionality-omitempty.yaml",
+			opts: []APIOpts{
+				WithOptionalityPolicy(OptionalityOmitEmpty),
+			},
+			setup: func(api *API) error {
+				api.Post("/test").
+					HasResponseModel(http.StatusOK, ModelOf[WithOptionalFields]())
+				return nil
 			},
 		},
 		{
-			name: "enum-constants.yaml",
-			setup: func(api *API) (err error) {
-				// Register the enums and values.
-				api.RegisterModel(ModelOf[StringEnum](), WithEnumConstants[StringEnum]())
-				api.RegisterModel(ModelOf[IntEnum](), WithEnumConstants[IntEnum]())
-
-				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[WithEnums]())
-				return
+			name: "nullable-references.yaml",
+			opts: []APIOpts{
+				WithNullableReferences(),
+			},
+			setup: func(api *API) error {
+				api.Post("/test").
+					HasResponseModel(http.StatusOK, ModelOf[WithOptionalFields]())
+				return nil
 			},
 		},
 		{
-			name: "with-maps.yaml",
-			setup: func(api *API) (err error) {
-				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[WithMaps]())
-				return
+			name: "format-inference.yaml",
+			opts: []APIOpts{
+				WithFormatInference(),
+			},
+			setup: func(api *API) error {
+				api.Post("/test").
+					HasResponseModel(http.StatusOK, ModelOf[WithFormats]())
+				return nil
 			},
 		},
 		{
-			name: "route-params.yaml",
-			setup: func(api *API) (err error) {
-				api.Get(`/organisation/{orgId:\d+}/user/{userId}`).
-					HasPathParameter("orgId", PathParam{
-						Description: "Organisation ID",
-						Regexp:      `\d+`,
-					}).
-					HasPathParameter("userId", PathParam{
-						Description: "User ID",
-					}).
-					HasResponseModel(http.StatusOK, ModelOf[User]())
-				return
+			name: "embedded-interface-skip.yaml",
+			opts: []APIOpts{
+				WithEmbeddedInterfacePolicy(EmbeddedInterfaceSkip),
+			},
+			setup: func(api *API) error {
+				api.Get("/test").
+					HasResponseModel(http.StatusOK, ModelOf[WithEmbeddedInterface]())
+				return nil
+			},
+		},
+		{
+			name: "embedded-interface-any.yaml",
+			opts: []APIOpts{
+				WithEmbeddedInterfacePolicy(EmbeddedInterfaceAny),
+			},
+			setup: func(api *API) error {
+				api.Get("/test").
+					HasResponseModel(http.StatusOK, ModelOf[WithEmbeddedInterface]())
+				return nil
+			},
+		},
+		{
+			name: "inline-policy-always-reference.yaml",
+			opts: []APIOpts{
+				WithInlinePolicy(AlwaysReference),
+			},
+			setup: func(api *API) error {
+				api.Get("/test").
+					HasResponseModel(http.StatusOK, ModelOf[InlinePolicyModel]())
+				return nil
+			},
+		},
+		{
+			name: "inline-policy-inline-small.yaml",
+			opts: []APIOpts{
+				WithInlinePolicy(InlineSmall),
+			},
+			setup: func(api *API) error {
+				api.Get("/test").
+					HasResponseModel(http.StatusOK, ModelOf[InlinePolicyModel]())
+				return nil
+			},
+		},
+		{
+			name: "inline-policy-inline-anonymous.yaml",
+			opts: []APIOpts{
+				WithInlinePolicy(InlineAnonymous),
+			},
+			setup: func(api *API) error {
+				api.Get("/test").
+					HasResponseModel(http.StatusOK, ModelOf[InlinePolicyModel]())
+				return nil
+			},
+		},
+		{
+			name: "with-name-struct-tags.yaml",
+			setup: func(api *API) error {
+				api.Post("/test").
+					HasRequestModel(ModelOf[WithNameStructTags]()).
+					HasResponseModel(http.StatusOK, ModelOf[WithNameStructTags]())
+				return nil
+			},
+		},
+		{
+			name: "known-types.yaml",
+			setup: func(api *API) error {
+				api.Route(http.MethodGet, "/test").
+					HasResponseModel(http.StatusOK, ModelOf[KnownTypes]())
+				return nil
+			},
+		},
+		{
+			name: "recursive-models.yaml",
+			setup: func(api *API) error {
+				api.Get("/recursive-models").
+					HasResponseModel(http.StatusOK, ModelOf[RecursiveModel]())
+				return nil
+			},
+		},
+		{
+			name: "recursive-tree.yaml",
+			setup: func(api *API) error {
+				api.Get("/tree").
+					HasResponseModel(http.StatusOK, ModelOf[TreeNode]())
+				api.Post("/tree").
+					HasRequestModel(ModelOf[TreeNode]()).
+					HasResponseModel(http.StatusOK, ModelOf[[]TreeNode]())
+				return nil
+			},
+		},
+		{
+			name: "recursive-slices-and-maps.yaml",
+			setup: func(api *API) error {
+				api.Get("/recursive").
+					HasResponseModel(http.StatusOK, ModelOf[MutuallyRecursive]())
+				return nil
+			},
+		},
+		{
+			name: "all-methods.yaml",
+			setup: func(api *API) (err error) {
+				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Head("/head").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Post("/post").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Put("/put").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Patch("/patch").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Delete("/delete").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Connect("/connect").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Options("/options").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				api.Trace("/trace").HasResponseModel(http.StatusOK, ModelOf[OK]())
+				return
+			},
+		},
+		{
+			name: "enums.yaml",
+			setup: func(api *API) (err error) {
+				// Register the enums and values.
+				api.RegisterModel(ModelOf[StringEnum](), WithEnumValues(StringEnumA, StringEnumB, StringEnumC))
+				api.RegisterModel(ModelOf[IntEnum](), WithEnumValues(IntEnum1, IntEnum2, IntEnum3))
+
+				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[WithEnums]())
+				return
+			},
+		},
+		{
+			name: "enum-constants.yaml",
+			setup: func(api *API) (err error) {
+				// Register the enums and values.
+				api.RegisterModel(ModelOf[StringEnum](), WithEnumConstants[StringEnum]())
+				api.RegisterModel(ModelOf[IntEnum](), WithEnumConstants[IntEnum]())
+
+				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[WithEnums]())
+				return
+			},
+		},
+		{
+			name: "with-maps.yaml",
+			setup: func(api *API) (err error) {
+				api.Get("/get").HasResponseModel(http.StatusOK, ModelOf[WithMaps]())
+				return
+			},
+		},
+		{
+			name: "route-params.yaml",
+			setup: func(api *API) (err error) {
+				api.Get(`/organisation/{orgId:\d+}/user/{userId}`).
+					HasPathParameter("orgId", PathParam{
+						Description: "Organisation ID",
+						Regexp:      `\d+`,
+					}).
+					HasPathParameter("userId", PathParam{
+						Description: "User ID",
+					}).
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+				return
 			},
 		},
 		{
@@ -361,6 +979,17 @@ func TestSchema(t *testing.T) {
 				return
 			},
 		},
+		{
+			name: "route-params-from-pattern.yaml",
+			setup: func(api *API) (err error) {
+				api.Get(`/organisation/{orgId:\d+}/user/{userId}/country/{code:[a-z]{2}}`).
+					HasPathParameter("userId", PathParam{
+						Description: "User ID",
+					}).
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+				return
+			},
+		},
 		{
 			name: "query-params.yaml",
 			setup: func(api *API) (err error) {
@@ -403,6 +1032,44 @@ func TestSchema(t *testing.T) {
 				return
 			},
 		},
+		{
+			name: "query-params-from-pattern.yaml",
+			setup: func(api *API) (err error) {
+				api.Get(`/users?orgId=123&orderBy=field`).
+					HasQueryParameter("orgId", QueryParam{
+						Description: "ID of the organisation",
+						Required:    true,
+						Type:        PrimitiveTypeInteger,
+					}).
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+				api.Post(`/users`).
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+				return
+			},
+		},
+		{
+			name: "param-defaults-and-examples.yaml",
+			setup: func(api *API) (err error) {
+				api.Get(`/organisation/{orgId}/users`).
+					HasPathParameter("orgId", PathParam{
+						Description: "Organisation ID",
+						Type:        PrimitiveTypeInteger,
+						Example:     123,
+					}).
+					HasQueryParameter("orderBy", QueryParam{
+						Description: "The field to order the results by",
+						Default:     "id",
+						Example:     "name",
+					}).
+					HasQueryParameter("limit", QueryParam{
+						Description: "Maximum number of results",
+						Type:        PrimitiveTypeInteger,
+						Default:     10,
+					}).
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+				return
+			},
+		},
 		{
 			name: "multiple-dates-with-comments.yaml",
 			setup: func(api *API) (err error) {
@@ -459,6 +1126,517 @@ func TestSchema(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "field-customisation.yaml",
+			opts: []APIOpts{
+				WithApplyCustomSchemaToField(func(t reflect.Type, f reflect.StructField, s *openapi3.Schema) {
+					if desc := f.Tag.Get("rest"); desc != "" {
+						s.Description = desc
+					}
+				}),
+			},
+			setup: func(api *API) error {
+				api.Get("/").
+					HasResponseModel(http.StatusOK, ModelOf[StructWithTags]())
+				return nil
+			},
+		},
+		{
+			name: "rate-limit.yaml",
+			setup: func(api *API) error {
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, ModelOf[[]User]()).
+					HasResponseDescription(http.StatusOK, "The users.").
+					HasRateLimit(RateLimit{Limit: 100, Window: time.Minute, Burst: 10})
+				return nil
+			},
+		},
+		{
+			name: "idempotency-key.yaml",
+			setup: func(api *API) error {
+				api.Post("/payments").
+					HasRequestModel(ModelOf[User]()).
+					HasResponseModel(http.StatusCreated, ModelOf[User]()).
+					HasResponseDescription(http.StatusCreated, "The payment was created.").
+					RequiresIdempotencyKey()
+				return nil
+			},
+		},
+		{
+			name: "cors.yaml",
+			opts: []APIOpts{
+				WithCORS(CORSPolicy{
+					AllowedOrigins:   []string{"https://example.com"},
+					AllowedHeaders:   []string{"Authorization", "Content-Type"},
+					ExposedHeaders:   []string{"ETag"},
+					AllowCredentials: true,
+					MaxAge:           time.Hour,
+					Preflight:        true,
+				}),
+			},
+			setup: func(api *API) error {
+				api.Get("/users/{id}").
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					HasResponseDescription(http.StatusOK, "The user.")
+				api.Delete("/users/{id}").
+					HasResponseModel(http.StatusOK, ModelOf[OK]()).
+					HasResponseDescription(http.StatusOK, "The user was deleted.")
+				return nil
+			},
+		},
+		{
+			name: "hal-links.yaml",
+			opts: []APIOpts{WithHALLinks()},
+			setup: func(api *API) error {
+				api.Get("/users/{id}").
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					HasResponseDescription(http.StatusOK, "The user.")
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, ModelOf[[]User]()).
+					HasResponseDescription(http.StatusOK, "The users.")
+				return nil
+			},
+		},
+		{
+			name: "conditional-requests.yaml",
+			setup: func(api *API) error {
+				api.Get("/users/{id}").
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					HasResponseDescription(http.StatusOK, "The user.").
+					SupportsConditionalRequests()
+				api.Put("/users/{id}").
+					HasRequestModel(ModelOf[User]()).
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					HasResponseDescription(http.StatusOK, "The updated user.").
+					SupportsConditionalRequests()
+				return nil
+			},
+		},
+		{
+			name: "odata.yaml",
+			setup: func(api *API) error {
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, ModelOf[[]User]()).
+					HasResponseDescription(http.StatusOK, "The users.").
+					HasODataQueryOptions(ODataOpts{Filter: true, Select: true, OrderBy: true, Top: true, Skip: true})
+				return nil
+			},
+		},
+		{
+			name: "fields-parameter.yaml",
+			setup: func(api *API) error {
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, ModelOf[[]User]()).
+					HasResponseDescription(http.StatusOK, "The users.").
+					HasFieldsParameter(ModelOf[[]User]())
+				return nil
+			},
+		},
+		{
+			name: "sort-parameter.yaml",
+			setup: func(api *API) error {
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, ModelOf[[]User]()).
+					HasResponseDescription(http.StatusOK, "The users.").
+					HasSortParameter(ModelOf[[]User](), SortableFields("name"))
+				return nil
+			},
+		},
+		{
+			name: "batch.yaml",
+			setup: func(api *API) error {
+				api.Post("/users/batch").
+					HasRequestModel(BatchRequestOf[User]()).
+					HasResponseModel(http.StatusOK, BatchResponseOf[User]()).
+					HasResponseDescription(http.StatusOK, "All of the users were created.").
+					IsBatchEndpoint()
+				return nil
+			},
+		},
+		{
+			name: "metadata-routes.yaml",
+			setup: func(api *API) error {
+				api.HasOpenAPIRoute()
+				api.HasVersionRoute()
+				api.HasMetricsRoute()
+				return nil
+			},
+		},
+		{
+			name: "security.yaml",
+			opts: []APIOpts{
+				WithSecurityScheme("oauth2", OAuth2AuthorizationCode("https://example.com/authorize", "https://example.com/token", Scopes{
+					"users:read":  "Read users.",
+					"users:write": "Create and update users.",
+				})),
+				WithSecurityScheme("oidc", OIDC("https://example.com/.well-known/openid-configuration")),
+			},
+			setup: func(api *API) error {
+				api.Get("/users").
+					HasResponseModel(http.StatusOK, ModelOf[[]User]()).
+					HasResponseDescription(http.StatusOK, "The users.").
+					HasSecurity("oauth2", "users:read").
+					HasSecurity("oidc")
+				api.Post("/users").
+					HasRequestModel(ModelOf[User]()).
+					HasResponseModel(http.StatusCreated, ModelOf[User]()).
+					HasResponseDescription(http.StatusCreated, "The created user.").
+					HasSecurity("oauth2", "users:read", "users:write")
+				return nil
+			},
+		},
+		{
+			name: "contextual-schema.yaml",
+			setup: func(api *API) error {
+				api.Post("/accounts").
+					HasRequestModel(ModelOf[Account]()).
+					HasResponseModel(http.StatusCreated, ModelOf[Account]()).
+					HasResponseDescription(http.StatusCreated, "The created account.")
+				api.Post("/teams").
+					HasRequestModel(ModelOf[Team]()).
+					HasResponseModel(http.StatusCreated, ModelOf[Team]()).
+					HasResponseDescription(http.StatusCreated, "The created team.")
+				return nil
+			},
+		},
+		{
+			name: "split-read-write-schemas.yaml",
+			opts: []APIOpts{WithSplitReadWriteSchemas()},
+			setup: func(api *API) error {
+				api.Post("/vaults").
+					HasRequestModel(ModelOf[Vault]()).
+					HasResponseModel(http.StatusCreated, ModelOf[Vault]()).
+					HasResponseDescription(http.StatusCreated, "The created vault.")
+				api.Get("/vaults").
+					HasResponseModel(http.StatusOK, ModelOf[[]Vault]()).
+					HasResponseDescription(http.StatusOK, "The vaults.")
+				return nil
+			},
+		},
+		{
+			name: "patch.yaml",
+			setup: func(api *API) error {
+				api.Patch("/users/{id}").
+					HasJSONMergePatchRequest(ModelOf[User]()).
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					HasResponseDescription(http.StatusOK, "The updated user.")
+				api.Patch("/users/{id}/settings").
+					HasJSONPatchRequest().
+					HasResponseModel(http.StatusOK, ModelOf[OK]()).
+					HasResponseDescription(http.StatusOK, "The settings were updated.")
+				return nil
+			},
+		},
+		{
+			name: "partial.yaml",
+			setup: func(api *API) error {
+				api.Post("/users/search").
+					HasRequestModel(PartialOf[User]()).
+					HasResponseModel(http.StatusOK, ModelOf[[]User]()).
+					HasResponseDescription(http.StatusOK, "The users that match the filter.")
+				return nil
+			},
+		},
+		{
+			name: "pick-omit.yaml",
+			setup: func(api *API) error {
+				api.Get("/accounts").
+					HasResponseModel(http.StatusOK, PickOf[Vault]("name", "owner")).
+					HasResponseDescription(http.StatusOK, "The accounts.")
+				api.Post("/accounts").
+					HasRequestModel(OmitOf[Vault]("secrets")).
+					HasResponseModel(http.StatusOK, ModelOf[OK]()).
+					HasResponseDescription(http.StatusOK, "The account was created.")
+				return nil
+			},
+		},
+		{
+			name: "extends.yaml",
+			setup: func(api *API) error {
+				api.Get("/admins").
+					HasResponseModel(http.StatusOK, ExtendsOf[User, Admin]()).
+					HasResponseDescription(http.StatusOK, "The admins.")
+				return nil
+			},
+		},
+		{
+			name: "tag-packages.yaml",
+			setup: func(api *API) error {
+				api.Get("/topics").
+					HasTags([]string{"packagedoc", "other"}).
+					HasResponseModel(http.StatusOK, ModelOf[[]packagedoc.Topic]()).
+					HasResponseDescription(http.StatusOK, "The topics.")
+				return nil
+			},
+		},
+		{
+			name: "enum-descriptions.yaml",
+			setup: func(api *API) error {
+				_, _, err := api.RegisterModel(ModelOf[Priority](), WithEnumConstants[Priority]())
+				return err
+			},
+		},
+		{
+			name: "flag-enum-integer.yaml",
+			setup: func(api *API) error {
+				api.OverrideModel(ModelOf[Permission](), WithFlagEnum[Permission](FlagEnumInteger))
+				_, _, err := api.RegisterModel(ModelOf[Permission]())
+				return err
+			},
+		},
+		{
+			name: "flag-enum-array.yaml",
+			setup: func(api *API) error {
+				api.OverrideModel(ModelOf[Permission](), WithFlagEnum[Permission](FlagEnumArray))
+				_, _, err := api.RegisterModel(ModelOf[Permission]())
+				return err
+			},
+		},
+		{
+			name: "enum-subset.yaml",
+			setup: func(api *API) error {
+				api.RegisterModel(ModelOf[StringEnum](), WithEnumConstants[StringEnum]())
+				api.RegisterModel(ModelOf[IntEnum](), WithEnumConstants[IntEnum]())
+				api.Get("/enums").
+					HasResponseModel(http.StatusOK, ModelOf[WithEnums]())
+				api.Post("/enums").
+					HasRequestModel(ModelOf[WithEnums](), WithEnumSubset("ss", StringEnumA), WithEnumSubset[IntEnum]("i", 1, 2)).
+					HasResponseModel(http.StatusOK, ModelOf[WithEnums](), WithEnumSubset("s", StringEnumB))
+				return nil
+			},
+		},
+		{
+			name: "const.yaml",
+			setup: func(api *API) error {
+				api.RegisterModel(ModelOf[IntEnum](), WithEnumConstants[IntEnum]())
+				api.Get("/consts").
+					HasResponseModel(http.StatusOK, ModelOf[WithConsts]())
+				api.Get("/version").
+					HasResponseModel(http.StatusOK, ModelOf[string](), WithConst("1.0"))
+				return nil
+			},
+		},
+		{
+			name: "arrays.yaml",
+			setup: func(api *API) error {
+				api.Get("/arrays").
+					HasResponseModel(http.StatusOK, ModelOf[WithArrays]())
+				api.Get("/position").
+					HasResponseModel(http.StatusOK, ModelOf[Coordinates](), WithTupleItems(
+						&openapi3.Schema{Type: &openapi3.Types{openapi3.TypeNumber}, Description: "Longitude."},
+						&openapi3.Schema{Type: &openapi3.Types{openapi3.TypeNumber}, Description: "Latitude."},
+					))
+				return nil
+			},
+		},
+		{
+			name: "set.yaml",
+			setup: func(api *API) error {
+				api.RegisterModel(ModelOf[StringEnum](), WithEnumConstants[StringEnum]())
+				api.Get("/sets").
+					HasResponseModel(http.StatusOK, ModelOf[WithSets]())
+				return nil
+			},
+		},
+		{
+			name: "content-encoding.yaml",
+			setup: func(api *API) error {
+				api.Get("/encoded").
+					HasResponseModel(http.StatusOK, ModelOf[WithEncodedContent]())
+				api.Get("/thumbnail").
+					HasResponseModel(http.StatusOK, ModelOf[string](), WithContentEncoding("base64"), WithContentMediaType("image/jpeg"))
+				return nil
+			},
+		},
+		{
+			name: "xml.yaml",
+			setup: func(api *API) error {
+				api.Post("/orders").
+					HasXML().
+					HasRequestModel(ModelOf[XMLOrder]()).
+					HasResponseModel(http.StatusCreated, ModelOf[XMLOrder]())
+				api.Get("/order-ids").
+					HasXML().
+					HasResponseModel(http.StatusOK, ModelOf[[]string](), WithXMLName("ids"), WithXMLWrapped())
+				return nil
+			},
+		},
+		{
+			name: "primitive-types.yaml",
+			setup: func(api *API) error {
+				api.Get("/events/{id}").
+					HasPathParameter("id", PathParam{Type: primitiveTypeUUID}).
+					HasQueryParameter("since", QueryParam{Type: primitiveTypeDate, Default: "2024-01-01"}).
+					HasResponseModel(http.StatusOK, ModelOf[string]())
+				return nil
+			},
+		},
+		{
+			name: "int64-as-string.yaml",
+			opts: []APIOpts{
+				WithInt64AsString(),
+			},
+			setup: func(api *API) error {
+				api.Get("/int64s").HasResponseModel(http.StatusOK, ModelOf[WithInt64s]())
+				return nil
+			},
+		},
+		{
+			name: "int64-string-encoded.yaml",
+			opts: []APIOpts{
+				WithInt64Encoding(Int64StringEncoded),
+			},
+			setup: func(api *API) error {
+				api.Get("/int64s").HasResponseModel(http.StatusOK, ModelOf[WithInt64s]())
+				return nil
+			},
+		},
+		{
+			name: "money.yaml",
+			opts: []APIOpts{
+				WithDecimalType[Decimal](),
+			},
+			setup: func(api *API) error {
+				api.Get("/invoices/{id}").HasResponseModel(http.StatusOK, ModelOf[Invoice]())
+				return nil
+			},
+		},
+		{
+			name: "units.yaml",
+			setup: func(api *API) error {
+				api.Get("/trips/{id}").HasResponseModel(http.StatusOK, ModelOf[WithUnits]())
+				return nil
+			},
+		},
+		{
+			name: "sensitive.yaml",
+			setup: func(api *API) error {
+				api.Post("/accounts").
+					HasRequestModel(ModelOf[Credentials]()).
+					HasResponseModel(http.StatusCreated, ModelOf[Credentials]())
+				return nil
+			},
+		},
+		{
+			name: "sensitive-write-only.yaml",
+			opts: []APIOpts{
+				WithSensitiveWriteOnly(),
+			},
+			setup: func(api *API) error {
+				api.Post("/accounts").
+					HasRequestModel(ModelOf[Credentials]()).
+					HasResponseModel(http.StatusCreated, ModelOf[Credentials]())
+				return nil
+			},
+		},
+		{
+			name: "gorm.yaml",
+			opts: []APIOpts{
+				WithGormTagMapping(),
+				WithOptionalityPolicy(OptionalityOmitEmpty),
+			},
+			setup: func(api *API) error {
+				api.Post("/products").
+					HasRequestModel(ModelOf[Product]()).
+					HasResponseModel(http.StatusCreated, ModelOf[Product]())
+				return nil
+			},
+		},
+		{
+			name: "sqlc.yaml",
+			opts: []APIOpts{
+				WithSQLCModels(),
+			},
+			setup: func(api *API) error {
+				api.Get("/authors/{id}").HasResponseModel(http.StatusOK, ModelOf[SQLCAuthor]())
+				return nil
+			},
+		},
+		{
+			name: "ent.yaml",
+			opts: []APIOpts{
+				WithEntModels(),
+			},
+			setup: func(api *API) error {
+				api.Get("/users/{id}").HasResponseModel(http.StatusOK, ModelOf[ent.User]())
+				return nil
+			},
+		},
+		{
+			name: "response-envelope.yaml",
+			opts: []APIOpts{
+				WithResponseEnvelope(ModelOf[Envelope]()),
+			},
+			setup: func(api *API) error {
+				api.Get("/users/{id}").
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					HasResponseModel(http.StatusNotFound, ModelOf[EnvelopeError]())
+				api.Post("/users").
+					HasRequestModel(ModelOf[User]()).
+					HasResponseModel(http.StatusCreated, ModelOf[User]())
+				api.Get("/users").
+					HasResponseEnvelope(ModelOf[ListEnvelope]()).
+					HasResponseModel(http.StatusOK, ModelOf[[]User]())
+				api.Get("/healthz").
+					HasNoResponseEnvelope().
+					HasResponseModel(http.StatusOK, ModelOf[string]())
+				return nil
+			},
+		},
+		{
+			name: "error-codes.yaml",
+			setup: func(api *API) error {
+				api.RegisterErrorCode("user_not_found", http.StatusNotFound, "The user doesn't exist.", ModelOf[ErrorResponse]())
+				api.RegisterErrorCode("invalid_user", http.StatusBadRequest, "The user is invalid.", ModelOf[FieldErrorsResponse]())
+				api.RegisterErrorCode("invalid_json", http.StatusBadRequest, "The body isn't valid JSON.", ModelOf[ErrorResponse]())
+				api.RegisterErrorCode("rate_limited", http.StatusTooManyRequests, "", Model{})
+				api.Get("/users/{id}").
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					MayFailWith("user_not_found")
+				api.Put("/users/{id}").
+					HasRequestModel(ModelOf[User]()).
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					HasResponseModel(http.StatusNotFound, ModelOf[ErrorResponse]()).
+					HasResponseDescription(http.StatusNotFound, "The user wasn't found.").
+					MayFailWith("user_not_found", "invalid_user", "invalid_json", "rate_limited")
+				return nil
+			},
+		},
+		{
+			name: "validation-error-response.yaml",
+			opts: []APIOpts{
+				WithValidationErrorResponse(ValidationErrorResponse{
+					Status: http.StatusUnprocessableEntity,
+					Model:  ModelOf[FieldErrorsResponse](),
+				}),
+			},
+			setup: func(api *API) error {
+				api.Post("/users").
+					HasRequestModel(ModelOf[User]()).
+					HasResponseModel(http.StatusCreated, ModelOf[User]())
+				api.Get("/users/{id}").
+					HasPathParameter("id", PathParam{Type: PrimitiveTypeInteger}).
+					HasResponseModel(http.StatusOK, ModelOf[User]())
+				api.Get("/users").
+					HasQueryParameter("name", QueryParam{}).
+					HasResponseModel(http.StatusOK, ModelOf[[]User]())
+				api.Put("/users/{id}").
+					HasRequestModel(ModelOf[User]()).
+					HasResponseModel(http.StatusOK, ModelOf[User]()).
+					HasResponseModel(http.StatusUnprocessableEntity, ModelOf[ErrorResponse]())
+				return nil
+			},
+		},
+		{
+			name: "jsonapi.yaml",
+			setup: func(api *API) error {
+				api.Post("/users").
+					HasRequestModel(JSONAPIResourceOf[User]("users")).
+					HasResponseModel(http.StatusCreated, JSONAPIResourceOf[User]("users")).
+					HasResponseDescription(http.StatusCreated, "The created user.")
+				return nil
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -522,6 +1700,45 @@ func TestSchema(t *testing.T) {
 	}
 }
 
+func TestAmbiguousEmbeddedFields(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/").HasResponseModel(http.StatusOK, ModelOf[WithAmbiguousFields]())
+	_, err := api.Spec()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	expected := `type "rest.WithAmbiguousFields" has ambiguous fields ["X"], add json tags to resolve the ambiguity`
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected error to contain %q, got %q", expected, err.Error())
+	}
+}
+
+func TestAmbiguousFieldsOfEmbeddedStructField(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/").HasResponseModel(http.StatusOK, ModelOf[EmbedsFieldWithAmbiguousFields]())
+	_, err := api.Spec()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	expected := `type "rest.WithAmbiguousFields" has ambiguous fields ["X"]`
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected error to contain %q, got %q", expected, err.Error())
+	}
+}
+
+func TestEmbeddedInterfaceError(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/").HasResponseModel(http.StatusOK, ModelOf[WithEmbeddedInterface]())
+	_, err := api.Spec()
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	expected := `type "rest.WithEmbeddedInterface" embeds interface "fmt.Stringer"`
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("expected error to contain %q, got %q", expected, err.Error())
+	}
+}
+
 func specToYAML(spec *openapi3.T) (out []byte, err error) {
 	// Use JSON, because kin-openapi doesn't customise the YAML output.
 	// For example, AdditionalProperties only has a MarshalJSON capability.
@@ -537,3 +1754,97 @@ func specToYAML(spec *openapi3.T) (out []byte, err error) {
 	}
 	return yaml.Marshal(m)
 }
+
+func TestAnonymousTypeNamesAreStable(t *testing.T) {
+	anonymous := reflect.TypeOf(struct{ A string }{})
+
+	a := NewAPI("test")
+	a.RegisterModel(ModelOf[struct{ A string }]())
+
+	b := NewAPI("test")
+	b.RegisterModel(ModelOf[User]())
+	b.RegisterModel(ModelOf[struct{ B string }]())
+	b.RegisterModel(ModelOf[struct{ A string }]())
+
+	if a.getModelName(anonymous) != b.getModelName(anonymous) {
+		t.Errorf("expected the same name regardless of registration order, got %q and %q", a.getModelName(anonymous), b.getModelName(anonymous))
+	}
+	if _, ok := b.models[b.getModelName(anonymous)]; !ok {
+		t.Errorf("expected %q to be registered", b.getModelName(anonymous))
+	}
+}
+
+func TestOverrideModelAfterRegistration(t *testing.T) {
+	api := NewAPI("test")
+	_, _, err := api.RegisterModel(ModelOf[WithOwner]())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	api.OverrideModel(ModelOf[User](), WithDescription("A user of the system."))
+
+	_, schema, err := api.RegisterModel(ModelOf[User]())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schema.Description != "A user of the system." {
+		t.Errorf("expected the override to be applied, got description %q", schema.Description)
+	}
+}
+
+func TestRegisterModelIsIdempotent(t *testing.T) {
+	models := []Model{
+		ModelOf[TreeNode](),
+		ModelOf[RecursiveMap](),
+		ModelOf[JSONObject](),
+		ModelOf[MutuallyRecursive](),
+		ModelOf[WithEmbeddedStructs](),
+		ModelOf[WithShadowedFields](),
+		ModelOf[*StructWithCustomisation](),
+		ModelOf[StructWithCustomisation](),
+	}
+	for _, model := range models {
+		t.Run(model.Type.String(), func(t *testing.T) {
+			once := NewAPI("test")
+			_, expected, err := once.RegisterModel(model)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			twice := NewAPI("test")
+			if _, _, err = twice.RegisterModel(model); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, actual, err := twice.RegisterModel(model)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(toJSON(t, expected), toJSON(t, actual)); diff != "" {
+				t.Errorf("schema changed when registered twice: %s", diff)
+			}
+			if diff := cmp.Diff(toJSON(t, once.models), toJSON(t, twice.models)); diff != "" {
+				t.Errorf("models changed when registered twice: %s", diff)
+			}
+		})
+	}
+}
+
+func toJSON(t *testing.T, v any) string {
+	t.Helper()
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal JSON: %v", err)
+	}
+	return string(b)
+}
+
+func TestAnonymousTypeNamesIncludePkgPaths(t *testing.T) {
+	// Both types are struct { R *rand.Rand }, but from different packages.
+	v1 := reflect.TypeOf(struct{ R *rand.Rand }{})
+	v2 := reflect.TypeOf(struct{ R *randv2.Rand }{})
+
+	api := NewAPI("test")
+	if api.getModelName(v1) == api.getModelName(v2) {
+		t.Errorf("expected different names, got %q for both", api.getModelName(v1))
+	}
+}
@@ -0,0 +1,142 @@
+package rest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SchemaPosition is where a model is used in a route.
+type SchemaPosition string
+
+const (
+	// SchemaPositionRequest is the request body of a route.
+	SchemaPositionRequest SchemaPosition = "request"
+	// SchemaPositionResponse is a response body of a route.
+	SchemaPositionResponse SchemaPosition = "response"
+	// SchemaPositionField is a field of a request or response body.
+	SchemaPositionField SchemaPosition = "field"
+)
+
+// SchemaContext describes where a model is used.
+type SchemaContext struct {
+	// API that the model is registered with.
+	API *API
+	// Route that uses the model.
+	Route *Route
+	// Position of the model in the route.
+	Position SchemaPosition
+	// Status of the response, or 0 if the model is used in the request.
+	Status int
+	// Body is the position of the request or response body that contains the field, if
+	// the position is SchemaPositionField.
+	Body SchemaPosition
+	// Field is the path to the field from the body, e.g. "author.addresses[]", if the
+	// position is SchemaPositionField.
+	Field string
+}
+
+// ContextualSchemaApplier is a type that customises its OpenAPI schema depending on where
+// it's used, e.g. to remove read-only properties from request bodies. It's called with a
+// copy of the schema each time the type is used as a request or response body, after
+// ApplyCustomSchema, and before the ModelOpts of the route. The copy is inlined in the
+// operation, and the type's component schema isn't changed.
+//
+// Types used by the fields of a body are called with the position SchemaPositionField,
+// and their copies are inlined in the body. Fields that refer to the type recursively
+// keep their reference to the component.
+type ContextualSchemaApplier interface {
+	ApplyContextualSchema(ctx SchemaContext, s *openapi3.Schema)
+}
+
+// getContextualComponent returns the component schema that the reference points to, if
+// its type implements ContextualSchemaApplier, or it has fields that do.
+func (api *API) getContextualComponent(ref *openapi3.SchemaRef, visited map[string]bool) (name string, schema *openapi3.Schema, ok bool) {
+	if ref == nil || ref.Ref == "" {
+		return "", nil, false
+	}
+	name = getComponentName(ref.Ref)
+	schema, ok = api.models[name]
+	if !ok || visited[name] {
+		return name, nil, false
+	}
+	if t, ok := api.modelTypes[name]; ok && modelFromType(t).sCtx != nil {
+		return name, schema, true
+	}
+	visited[name] = true
+	defer delete(visited, name)
+	return name, schema, api.hasContextualFields(schema, visited)
+}
+
+// hasContextualFields returns true if the fields of the schema, at any depth, refer to a
+// component whose type implements ContextualSchemaApplier.
+func (api *API) hasContextualFields(s *openapi3.Schema, visited map[string]bool) bool {
+	for _, ref := range getFieldSchemaRefs(s) {
+		if _, _, ok := api.getContextualComponent(ref, visited); ok {
+			return true
+		}
+		if ref.Ref == "" && ref.Value != nil && api.hasContextualFields(ref.Value, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// getFieldSchemaRefs returns the schemas of the properties, items and values of the schema,
+// keyed by their path segment, e.g. ".name" or "[]".
+func getFieldSchemaRefs(s *openapi3.Schema) map[string]*openapi3.SchemaRef {
+	refs := make(map[string]*openapi3.SchemaRef)
+	for name, ref := range s.Properties {
+		refs["."+name] = ref
+	}
+	if s.Items != nil {
+		refs["[]"] = s.Items
+	}
+	if s.AdditionalProperties.Schema != nil {
+		refs["{}"] = s.AdditionalProperties.Schema
+	}
+	return refs
+}
+
+// applyContextualFields inlines copies of the components used by the fields of the
+// schema that are customised by ApplyContextualSchema, or have fields that are. The
+// schema must be a copy.
+func (api *API) applyContextualFields(ctx SchemaContext, s *openapi3.Schema, path string, visited map[string]bool) error {
+	refs := getFieldSchemaRefs(s)
+	for _, segment := range getSortedKeys(refs) {
+		ref := refs[segment]
+		fieldPath := strings.TrimPrefix(path+segment, ".")
+		if ref.Ref == "" {
+			if ref.Value != nil {
+				if err := api.applyContextualFields(ctx, ref.Value, fieldPath, visited); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		name, component, ok := api.getContextualComponent(ref, visited)
+		if !ok {
+			continue
+		}
+		copied, err := cloneSchema(component)
+		if err != nil {
+			return fmt.Errorf("failed to copy schema %q: %w", name, err)
+		}
+		if t, ok := api.modelTypes[name]; ok {
+			if sCtx := modelFromType(t).sCtx; sCtx != nil {
+				fieldCtx := ctx
+				fieldCtx.Position, fieldCtx.Body, fieldCtx.Field = SchemaPositionField, ctx.Position, fieldPath
+				sCtx(fieldCtx, copied)
+			}
+		}
+		visited[name] = true
+		err = api.applyContextualFields(ctx, copied, fieldPath, visited)
+		delete(visited, name)
+		if err != nil {
+			return err
+		}
+		*ref = *openapi3.NewSchemaRef("", copied)
+	}
+	return nil
+}
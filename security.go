@@ -0,0 +1,134 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Scope is an OAuth2 scope, e.g. "users:read". Declare the scopes of the API as
+// constants, so that the same values are used in the security scheme and in HasSecurity.
+type Scope string
+
+// Scopes maps the scopes of an OAuth2 flow to their descriptions.
+type Scopes map[Scope]string
+
+func (s Scopes) toOpenAPI() map[string]string {
+	m := make(map[string]string, len(s))
+	for scope, description := range s {
+		m[string(scope)] = description
+	}
+	return m
+}
+
+// SecurityRequirement is a security scheme that a route requires, and the scopes
+// required from it.
+type SecurityRequirement struct {
+	// Scheme is the name of a security scheme added with WithSecurityScheme.
+	Scheme string
+	// Scopes required by the route.
+	Scopes []Scope
+}
+
+// WithSecurityScheme adds a security scheme to the API, e.g. one created by
+// OAuth2AuthorizationCode or OIDC. Routes refer to the scheme by name in HasSecurity.
+func WithSecurityScheme(name string, scheme *openapi3.SecurityScheme) APIOpts {
+	return func(api *API) {
+		if api.SecuritySchemes == nil {
+			api.SecuritySchemes = make(map[string]*openapi3.SecurityScheme)
+		}
+		api.SecuritySchemes[name] = scheme
+	}
+}
+
+// OAuth2AuthorizationCode creates an OAuth2 security scheme that uses the authorization
+// code flow.
+func OAuth2AuthorizationCode(authURL, tokenURL string, scopes Scopes) *openapi3.SecurityScheme {
+	return &openapi3.SecurityScheme{
+		Type: "oauth2",
+		Flows: &openapi3.OAuthFlows{
+			AuthorizationCode: &openapi3.OAuthFlow{
+				AuthorizationURL: authURL,
+				TokenURL:         tokenURL,
+				Scopes:           scopes.toOpenAPI(),
+			},
+		},
+	}
+}
+
+// OAuth2ClientCredentials creates an OAuth2 security scheme that uses the client
+// credentials flow, for machine to machine requests.
+func OAuth2ClientCredentials(tokenURL string, scopes Scopes) *openapi3.SecurityScheme {
+	return &openapi3.SecurityScheme{
+		Type: "oauth2",
+		Flows: &openapi3.OAuthFlows{
+			ClientCredentials: &openapi3.OAuthFlow{
+				TokenURL: tokenURL,
+				Scopes:   scopes.toOpenAPI(),
+			},
+		},
+	}
+}
+
+// OIDC creates an OpenID Connect security scheme, e.g. with a discoveryURL of
+// https://example.com/.well-known/openid-configuration.
+func OIDC(discoveryURL string) *openapi3.SecurityScheme {
+	return &openapi3.SecurityScheme{
+		Type:             "openIdConnect",
+		OpenIdConnectUrl: discoveryURL,
+	}
+}
+
+// HasSecurity documents that the route requires the named security scheme, with the
+// scopes. If it's called more than once, any one of the requirements must be met.
+func (rm *Route) HasSecurity(scheme string, scopes ...Scope) *Route {
+	rm.Security = append(rm.Security, SecurityRequirement{Scheme: scheme, Scopes: scopes})
+	return rm
+}
+
+// getSecurityRequirements checks that the requirements refer to the security schemes of
+// the API, and the scopes of their OAuth2 flows.
+func (api *API) getSecurityRequirements(operation string, requirements []SecurityRequirement) (*openapi3.SecurityRequirements, error) {
+	sr := openapi3.NewSecurityRequirements()
+	for _, r := range requirements {
+		scheme, ok := api.SecuritySchemes[r.Scheme]
+		if !ok {
+			return nil, fmt.Errorf("%s: unknown security scheme %q", operation, r.Scheme)
+		}
+		scopes := make([]string, len(r.Scopes))
+		for i, scope := range r.Scopes {
+			if scheme.Type == "oauth2" && !hasScope(scheme.Flows, string(scope)) {
+				return nil, fmt.Errorf("%s: scope %q isn't defined by security scheme %q", operation, scope, r.Scheme)
+			}
+			scopes[i] = string(scope)
+		}
+		sr.With(openapi3.NewSecurityRequirement().Authenticate(r.Scheme, scopes...))
+	}
+	return sr, nil
+}
+
+func hasScope(flows *openapi3.OAuthFlows, scope string) bool {
+	if flows == nil {
+		return false
+	}
+	for _, flow := range []*openapi3.OAuthFlow{flows.Implicit, flows.Password, flows.ClientCredentials, flows.AuthorizationCode} {
+		if flow == nil {
+			continue
+		}
+		if _, ok := flow.Scopes[scope]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// addSecuritySchemes adds the security schemes of the API to the spec's components.
+func (api *API) addSecuritySchemes(spec *openapi3.T) {
+	if len(api.SecuritySchemes) == 0 {
+		return
+	}
+	spec.Components.SecuritySchemes = make(openapi3.SecuritySchemes, len(api.SecuritySchemes))
+	for _, name := range getSortedKeys(api.SecuritySchemes) {
+		spec.Components.SecuritySchemes[name] = &openapi3.SecuritySchemeRef{Value: api.SecuritySchemes[name]}
+	}
+}
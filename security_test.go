@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSecurityRequirementsAreChecked(t *testing.T) {
+	opts := []APIOpts{
+		WithSecurityScheme("oauth2", OAuth2ClientCredentials("https://example.com/token", Scopes{
+			"users:read": "Read users.",
+		})),
+	}
+	tests := []struct {
+		name        string
+		scheme      string
+		scopes      []Scope
+		expectedErr string
+	}{
+		{
+			name:   "valid",
+			scheme: "oauth2",
+			scopes: []Scope{"users:read"},
+		},
+		{
+			name:        "unknown scheme",
+			scheme:      "apiKey",
+			expectedErr: `GET /users: unknown security scheme "apiKey"`,
+		},
+		{
+			name:        "unknown scope",
+			scheme:      "oauth2",
+			scopes:      []Scope{"users:delete"},
+			expectedErr: `GET /users: scope "users:delete" isn't defined by security scheme "oauth2"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("test", opts...)
+			api.Get("/users").
+				HasResponseModel(http.StatusOK, ModelOf[[]User]()).
+				HasResponseDescription(http.StatusOK, "The users.").
+				HasSecurity(test.scheme, test.scopes...)
+
+			_, err := api.Spec()
+			if test.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+				t.Errorf("expected error %q, got %v", test.expectedErr, err)
+			}
+		})
+	}
+}
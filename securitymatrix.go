@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// SecurityMatrixEntry is the security required by an operation.
+type SecurityMatrixEntry struct {
+	// Operation is the method and path, e.g. "GET /users/{id}".
+	Operation string
+	// OperationID of the route, if set.
+	OperationID string
+	// Requirements of the route, any one of which must be met. If empty, the
+	// operation doesn't require authentication.
+	Requirements []SecurityRequirement
+}
+
+// SecurityMatrix maps the operations of an API to the security schemes and scopes
+// that they require, e.g. for security reviews, or to generate IAM policies.
+type SecurityMatrix []SecurityMatrixEntry
+
+// SecurityMatrix returns the security requirements of each operation, from HasSecurity,
+// ordered by path and method.
+func (api *API) SecurityMatrix() (m SecurityMatrix) {
+	for _, pattern := range getSortedKeys(api.Routes) {
+		methodToRoute := api.Routes[pattern]
+		for _, method := range getSortedMethods(methodToRoute) {
+			route := methodToRoute[method]
+			m = append(m, SecurityMatrixEntry{
+				Operation:    getOperation(route),
+				OperationID:  route.OperationID,
+				Requirements: slices.Clone(route.Security),
+			})
+		}
+	}
+	return m
+}
+
+// Scopes returns the operations that require each scope, e.g. to grant a role the
+// scopes needed to call a set of operations.
+func (m SecurityMatrix) Scopes() map[Scope][]string {
+	scopes := make(map[Scope][]string)
+	for _, e := range m {
+		for _, r := range e.Requirements {
+			for _, scope := range r.Scopes {
+				if !slices.Contains(scopes[scope], e.Operation) {
+					scopes[scope] = append(scopes[scope], e.Operation)
+				}
+			}
+		}
+	}
+	return scopes
+}
+
+// WriteCSV writes the matrix as CSV, with one row per security requirement. Operations
+// that don't require authentication have a row with an empty scheme.
+func (m SecurityMatrix) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"operation", "operation_id", "scheme", "scopes"}); err != nil {
+		return err
+	}
+	for _, e := range m {
+		for _, r := range e.requirementsOrNone() {
+			if err := cw.Write([]string{e.Operation, e.OperationID, r.Scheme, formatScopes(r.Scopes, " ")}); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes the matrix as a Markdown table, with one row per security requirement.
+func (m SecurityMatrix) WriteMarkdown(w io.Writer) error {
+	if _, err := io.WriteString(w, "| Operation | Operation ID | Scheme | Scopes |\n| --- | --- | --- | --- |\n"); err != nil {
+		return err
+	}
+	for _, e := range m {
+		for _, r := range e.requirementsOrNone() {
+			scheme := r.Scheme
+			if scheme == "" {
+				scheme = "none"
+			}
+			_, err := fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", e.Operation, escapeMarkdown(e.OperationID), escapeMarkdown(scheme), escapeMarkdown(formatScopes(r.Scopes, ", ")))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e SecurityMatrixEntry) requirementsOrNone() []SecurityRequirement {
+	if len(e.Requirements) == 0 {
+		return []SecurityRequirement{{}}
+	}
+	return e.Requirements
+}
+
+func formatScopes(scopes []Scope, sep string) string {
+	s := make([]string, len(scopes))
+	for i, scope := range scopes {
+		s[i] = string(scope)
+	}
+	return strings.Join(s, sep)
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
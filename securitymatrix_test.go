@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newSecurityMatrixTestAPI() *API {
+	api := NewAPI("test",
+		WithSecurityScheme("oauth2", OAuth2ClientCredentials("https://example.com/token", Scopes{
+			"users:read":  "Read users.",
+			"users:write": "Create and update users.",
+		})),
+		WithSecurityScheme("oidc", OIDC("https://example.com/.well-known/openid-configuration")),
+	)
+	api.Get("/users").
+		HasOperationID("listUsers").
+		HasSecurity("oauth2", "users:read").
+		HasSecurity("oidc")
+	api.Post("/users").
+		HasOperationID("createUser").
+		HasSecurity("oauth2", "users:read", "users:write")
+	api.Get("/health").
+		HasResponseModel(http.StatusOK, ModelOf[OK]())
+	return api
+}
+
+func TestSecurityMatrix(t *testing.T) {
+	m := newSecurityMatrixTestAPI().SecurityMatrix()
+
+	expected := SecurityMatrix{
+		{Operation: "GET /health"},
+		{
+			Operation:   "GET /users",
+			OperationID: "listUsers",
+			Requirements: []SecurityRequirement{
+				{Scheme: "oauth2", Scopes: []Scope{"users:read"}},
+				{Scheme: "oidc"},
+			},
+		},
+		{
+			Operation:   "POST /users",
+			OperationID: "createUser",
+			Requirements: []SecurityRequirement{
+				{Scheme: "oauth2", Scopes: []Scope{"users:read", "users:write"}},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, m); diff != "" {
+		t.Error(diff)
+	}
+
+	expectedScopes := map[Scope][]string{
+		"users:read":  {"GET /users", "POST /users"},
+		"users:write": {"POST /users"},
+	}
+	if diff := cmp.Diff(expectedScopes, m.Scopes()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestSecurityMatrixExport(t *testing.T) {
+	m := newSecurityMatrixTestAPI().SecurityMatrix()
+
+	var csv strings.Builder
+	if err := m.WriteCSV(&csv); err != nil {
+		t.Fatal(err)
+	}
+	expectedCSV := `operation,operation_id,scheme,scopes
+GET /health,,,
+GET /users,listUsers,oauth2,users:read
+GET /users,listUsers,oidc,
+POST /users,createUser,oauth2,users:read users:write
+`
+	if diff := cmp.Diff(expectedCSV, csv.String()); diff != "" {
+		t.Error(diff)
+	}
+
+	var md strings.Builder
+	if err := m.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	expectedMarkdown := "| Operation | Operation ID | Scheme | Scopes |\n" +
+		"| --- | --- | --- | --- |\n" +
+		"| `GET /health` |  | none |  |\n" +
+		"| `GET /users` | listUsers | oauth2 | users:read |\n" +
+		"| `GET /users` | listUsers | oidc |  |\n" +
+		"| `POST /users` | createUser | oauth2 | users:read, users:write |\n"
+	if diff := cmp.Diff(expectedMarkdown, md.String()); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// sensitiveExtension marks properties that contain sensitive data, e.g. personal data or
+// secrets, so that logging and other middleware can redact them.
+const sensitiveExtension = "x-sensitive"
+
+// WithSensitive marks the schema as containing sensitive data, by adding x-sensitive: true.
+//
+// Struct fields can use the sensitive tag instead, e.g. `sensitive:"true"`.
+func WithSensitive() ModelOpts {
+	return func(s *openapi3.Schema) {
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions[sensitiveExtension] = true
+	}
+}
+
+// WithSensitiveWriteOnly makes fields with the sensitive tag writeOnly, so that they're
+// accepted in requests, but not documented in responses, e.g. passwords.
+func WithSensitiveWriteOnly() APIOpts {
+	return func(api *API) {
+		api.SensitiveWriteOnly = true
+	}
+}
+
+// applySensitiveTag marks the field as sensitive if its sensitive tag is true, e.g.
+//
+//	Password string `json:"password" sensitive:"true"`
+//
+// Referenced schemas can't be changed, so they're wrapped in allOf.
+func (api *API) applySensitiveTag(f reflect.StructField, ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+	tag, ok := f.Tag.Lookup("sensitive")
+	if !ok {
+		return ref, nil
+	}
+	sensitive, err := strconv.ParseBool(tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sensitive tag %q: %w", tag, err)
+	}
+	if !sensitive {
+		return ref, nil
+	}
+	ref = wrapRef(ref)
+	WithSensitive()(ref.Value)
+	if api.SensitiveWriteOnly {
+		ref.Value.WriteOnly = true
+	}
+	return ref, nil
+}
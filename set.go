@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// applySetTag limits the field to the values of its set tag, if it has one, e.g.
+//
+//	Permissions []string `json:"permissions" set:"read,write,delete"`
+//
+// Slices of strings are arrays of unique values from the set. Strings hold the values
+// joined by commas, e.g. "read,write".
+func applySetTag(f reflect.StructField, ref *openapi3.SchemaRef) (*openapi3.SchemaRef, error) {
+	tag, ok := f.Tag.Lookup("set")
+	if !ok {
+		return ref, nil
+	}
+	var values []any
+	for _, v := range strings.Split(tag, ",") {
+		values = append(values, strings.TrimSpace(v))
+	}
+	t := f.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case ref.Value == nil:
+		return nil, fmt.Errorf("set tag is not supported on %v fields", t)
+	case t.Kind() == reflect.String:
+		ref.Value.Pattern = getSetPattern(values)
+		return ref, nil
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.String:
+		items := &openapi3.Schema{Type: &openapi3.Types{openapi3.TypeString}}
+		if ref.Value.Items.Ref != "" {
+			items.AllOf = openapi3.SchemaRefs{ref.Value.Items}
+		}
+		items.Enum = values
+		ref.Value.Items = openapi3.NewSchemaRef("", items)
+		ref.Value.UniqueItems = true
+		return ref, nil
+	}
+	return nil, fmt.Errorf("set tag is not supported on %v fields", t)
+}
+
+// getSetPattern returns a pattern that matches any of the values, joined by commas.
+func getSetPattern(values []any) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = regexp.QuoteMeta(v.(string))
+	}
+	value := "(" + strings.Join(quoted, "|") + ")"
+	return "^" + value + "(," + value + ")*$"
+}
@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SortParameter is the name of the query parameter added by HasSortParameter.
+const SortParameter = "sort"
+
+// Sort configures the sort query parameter of a route, see HasSortParameter.
+type Sort struct {
+	// Model whose properties the results can be sorted by.
+	Model Model
+	// Fields that the results can be sorted by. If empty, all properties of the model
+	// can be used.
+	Fields []string
+}
+
+// SortOpts configures the sort query parameter.
+type SortOpts func(s *Sort)
+
+// SortableFields limits the fields that the results can be sorted by. Each field must be
+// a property of the model.
+func SortableFields(fields ...string) SortOpts {
+	return func(s *Sort) {
+		s.Fields = append(s.Fields, fields...)
+	}
+}
+
+// HasSortParameter documents a sort query parameter, a comma separated list of the fields
+// to sort the results by, e.g. /users?sort=name,-createdAt. Fields prefixed with - are
+// sorted in descending order. The fields are checked against the properties of the model
+// when the spec is generated. If the model is a slice, the properties of its elements are
+// used.
+func (rm *Route) HasSortParameter(model Model, opts ...SortOpts) *Route {
+	s := &Sort{Model: model}
+	for _, opt := range opts {
+		opt(s)
+	}
+	rm.Sort = s
+	return rm
+}
+
+// addSortParameter adds the sort query parameter to the operation.
+func (api *API) addSortParameter(op *openapi3.Operation, s Sort) error {
+	properties, err := api.getPropertyNames(s.Model)
+	if err != nil {
+		return fmt.Errorf("failed to get the sortable fields of %v: %w", s.Model.Type, err)
+	}
+	fields := properties
+	if len(s.Fields) > 0 {
+		for _, f := range s.Fields {
+			if !slices.Contains(properties, f) {
+				return fmt.Errorf("sortable field %q is not a property of %v", f, s.Model.Type)
+			}
+		}
+		fields = s.Fields
+	}
+	values := make([]string, 0, len(fields)*2)
+	for _, f := range fields {
+		values = append(values, f, "-"+f)
+	}
+	op.AddParameter(newListQueryParameter(SortParameter,
+		"Comma separated list of the fields to sort the results by. Fields prefixed with - are sorted in descending order.",
+		values))
+	return nil
+}
@@ -0,0 +1,23 @@
+package rest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSortParameterRequiresModelProperties(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/users").
+		HasResponseModel(http.StatusOK, ModelOf[[]User]()).
+		HasResponseDescription(http.StatusOK, "The users.").
+		HasSortParameter(ModelOf[[]User](), SortableFields("name", "createdAt"))
+
+	_, err := api.Spec()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(err.Error(), `sortable field "createdAt" is not a property`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package rest
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// WithSplitReadWriteSchemas replaces component schemas that have readOnly or writeOnly
+// properties with a Request variant, e.g. UserRequest, which omits the read-only
+// properties, and a Response variant, e.g. UserResponse, which omits the write-only
+// properties. Request bodies use the Request variants, and responses use the Response
+// variants. Schemas that contain other split schemas are split too.
+//
+// This helps client generators that don't handle readOnly and writeOnly, for example
+// by requiring read-only properties in request bodies.
+func WithSplitReadWriteSchemas() APIOpts {
+	return func(api *API) {
+		api.SplitReadWriteSchemas = true
+	}
+}
+
+// schemaVariant is a variant of a component schema created by WithSplitReadWriteSchemas.
+type schemaVariant string
+
+const (
+	schemaVariantRequest  schemaVariant = "Request"
+	schemaVariantResponse schemaVariant = "Response"
+)
+
+// omits returns true if the variant omits the property.
+func (v schemaVariant) omits(s *openapi3.Schema) bool {
+	if v == schemaVariantRequest {
+		return s.ReadOnly
+	}
+	return s.WriteOnly
+}
+
+// splitReadWriteSchemas replaces the component schemas that have readOnly or writeOnly
+// properties with their Request and Response variants.
+func splitReadWriteSchemas(spec *openapi3.T) error {
+	components := spec.Components.Schemas
+	split := getSplitSchemas(components)
+	if len(split) == 0 {
+		return nil
+	}
+	names := getSortedKeys(split)
+	for _, name := range names {
+		for _, v := range []schemaVariant{schemaVariantRequest, schemaVariantResponse} {
+			if _, exists := components[name+string(v)]; exists {
+				return fmt.Errorf("can't split schema %q, because %q already exists", name, name+string(v))
+			}
+		}
+	}
+	for _, name := range names {
+		for _, v := range []schemaVariant{schemaVariantRequest, schemaVariantResponse} {
+			variant, err := cloneSchema(components[name].Value)
+			if err != nil {
+				return fmt.Errorf("failed to copy schema %q: %w", name, err)
+			}
+			rewriteSchema(variant, split, v)
+			components[name+string(v)] = openapi3.NewSchemaRef("", variant)
+		}
+	}
+	for _, pathItem := range spec.Paths.Map() {
+		for _, op := range pathItem.Operations() {
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				if err := rewriteContent(op.RequestBody.Value.Content, split, schemaVariantRequest); err != nil {
+					return err
+				}
+			}
+			if op.Responses == nil {
+				continue
+			}
+			for _, response := range op.Responses.Map() {
+				if response.Value == nil {
+					continue
+				}
+				if err := rewriteContent(response.Value.Content, split, schemaVariantResponse); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, name := range names {
+		delete(components, name)
+	}
+	return nil
+}
+
+// getSplitSchemas returns the names of the component schemas that have readOnly or
+// writeOnly properties, or that reference schemas that do.
+func getSplitSchemas(components openapi3.Schemas) map[string]bool {
+	split := make(map[string]bool)
+	refs := make(map[string][]string)
+	for name, ref := range components {
+		if ref.Value == nil {
+			continue
+		}
+		if hasReadWriteOnly(ref.Value, func(ref string) { refs[name] = append(refs[name], ref) }) {
+			split[name] = true
+		}
+	}
+	// Schemas that reference split schemas must reference the variants, so they're split too.
+	for changed := true; changed; {
+		changed = false
+		for name, referenced := range refs {
+			if !split[name] && slices.ContainsFunc(referenced, func(r string) bool { return split[r] }) {
+				split[name] = true
+				changed = true
+			}
+		}
+	}
+	return split
+}
+
+// hasReadWriteOnly returns true if the schema, or its inline subschemas, have readOnly
+// or writeOnly properties. The names of referenced components are passed to onRef.
+func hasReadWriteOnly(s *openapi3.Schema, onRef func(name string)) (found bool) {
+	var visit func(ref *openapi3.SchemaRef)
+	visit = func(ref *openapi3.SchemaRef) {
+		if ref == nil {
+			return
+		}
+		if name, ok := strings.CutPrefix(ref.Ref, "#/components/schemas/"); ok {
+			onRef(name)
+			return
+		}
+		if ref.Value == nil {
+			return
+		}
+		for _, prop := range ref.Value.Properties {
+			if prop.Value != nil && prop.Ref == "" && (prop.Value.ReadOnly || prop.Value.WriteOnly) {
+				found = true
+			}
+			visit(prop)
+		}
+		for _, child := range getChildSchemas(ref.Value) {
+			visit(child)
+		}
+	}
+	visit(openapi3.NewSchemaRef("", s))
+	return found
+}
+
+// rewriteContent rewrites the schemas of request or response content to use the variant.
+func rewriteContent(content openapi3.Content, split map[string]bool, v schemaVariant) error {
+	for _, mt := range content {
+		if mt.Schema == nil {
+			continue
+		}
+		if mt.Schema.Ref != "" {
+			mt.Schema = rewriteRef(mt.Schema, split, v)
+			continue
+		}
+		// Inline schemas may be shared with other operations, so they're copied.
+		s, err := cloneSchema(mt.Schema.Value)
+		if err != nil {
+			return fmt.Errorf("failed to copy schema: %w", err)
+		}
+		rewriteSchema(s, split, v)
+		mt.Schema = openapi3.NewSchemaRef("", s)
+	}
+	return nil
+}
+
+// rewriteRef returns a reference to the variant of a split schema, or rewrites an
+// inline schema in place.
+func rewriteRef(ref *openapi3.SchemaRef, split map[string]bool, v schemaVariant) *openapi3.SchemaRef {
+	if ref == nil {
+		return nil
+	}
+	if name, ok := strings.CutPrefix(ref.Ref, "#/components/schemas/"); ok && split[name] {
+		return openapi3.NewSchemaRef(ref.Ref+string(v), nil)
+	}
+	if ref.Ref == "" && ref.Value != nil {
+		rewriteSchema(ref.Value, split, v)
+	}
+	return ref
+}
+
+// rewriteSchema removes the properties that the variant omits, and references the
+// variants of split schemas.
+func rewriteSchema(s *openapi3.Schema, split map[string]bool, v schemaVariant) {
+	for name, prop := range s.Properties {
+		if prop.Ref == "" && prop.Value != nil && v.omits(prop.Value) {
+			delete(s.Properties, name)
+			s.Required = slices.DeleteFunc(s.Required, func(r string) bool { return r == name })
+			continue
+		}
+		s.Properties[name] = rewriteRef(prop, split, v)
+	}
+	s.Items = rewriteRef(s.Items, split, v)
+	s.Not = rewriteRef(s.Not, split, v)
+	s.AdditionalProperties.Schema = rewriteRef(s.AdditionalProperties.Schema, split, v)
+	for _, refs := range [][]*openapi3.SchemaRef{s.AllOf, s.AnyOf, s.OneOf} {
+		for i := range refs {
+			refs[i] = rewriteRef(refs[i], split, v)
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package swaggerui
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
@@ -12,18 +16,48 @@ import (
 //go:embed swagger-ui/*
 var swaggerUI embed.FS
 
+// New creates a handler that serves the Swagger UI, and the specification at
+// /swagger-ui/swagger.json. The specification is marshalled once, and served with
+// ETag and Last-Modified headers, so that clients which poll it can use conditional
+// requests.
 func New(spec *openapi3.T) (h http.Handler, err error) {
-	specBytes, err := json.MarshalIndent(spec, "", " ")
+	f, err := newSpecFile(spec)
 	if err != nil {
-		return h, fmt.Errorf("swaggerui: failed to marshal specification: %w", err)
+		return h, err
 	}
 
 	m := http.NewServeMux()
 	m.Handle("/", http.FileServer(http.FS(swaggerUI)))
-	m.HandleFunc("/swagger-ui/swagger.json", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Add("Content-Type", "application/json")
-		w.Write(specBytes)
+	m.HandleFunc("/swagger-ui/swagger.json", f.serve)
+	m.HandleFunc("/swagger-ui/events", func(w http.ResponseWriter, r *http.Request) {
+		// The specification doesn't change, so tell the UI not to listen for changes.
+		w.WriteHeader(http.StatusNoContent)
 	})
 
 	return m, nil
 }
+
+// specFile is a marshalled specification.
+type specFile struct {
+	bytes    []byte
+	etag     string
+	modified time.Time
+}
+
+func newSpecFile(spec *openapi3.T) (f specFile, err error) {
+	f.bytes, err = json.MarshalIndent(spec, "", " ")
+	if err != nil {
+		return f, fmt.Errorf("swaggerui: failed to marshal specification: %w", err)
+	}
+	hash := sha256.Sum256(f.bytes)
+	f.etag = `"` + hex.EncodeToString(hash[:]) + `"`
+	f.modified = time.Now()
+	return f, nil
+}
+
+func (f specFile) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", f.etag)
+	// ServeContent handles If-None-Match and If-Modified-Since.
+	http.ServeContent(w, r, "swagger.json", f.modified, bytes.NewReader(f.bytes))
+}
@@ -0,0 +1,40 @@
+package swaggerui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestSpecConditionalRequests(t *testing.T) {
+	h, err := New(&openapi3.T{OpenAPI: "3.0.0", Info: &openapi3.Info{Title: "test", Version: "0.0.0"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger-ui/swagger.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag header")
+	}
+	if w.Header().Get("Last-Modified") == "" {
+		t.Error("expected a Last-Modified header")
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected content type application/json, got %q", contentType)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/swagger-ui/swagger.json", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected status 304, got %d", w.Code)
+	}
+}
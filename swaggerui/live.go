@@ -0,0 +1,194 @@
+package swaggerui
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Live serves the Swagger UI like New, but regenerates the specification when Reload
+// is called, and pushes changes to open browsers using server-sent events, so that
+// the docs update as the API is edited.
+//
+// Browsers compare the ETag of the specification when they reconnect, so the UI also
+// updates when the process that serves it is restarted by an external file watcher.
+type Live struct {
+	// Logger used to report failures to regenerate the specification while watching.
+	// If nil, failures are not logged.
+	Logger *slog.Logger
+
+	generate func() (*openapi3.T, error)
+	mux      *http.ServeMux
+
+	m       sync.Mutex
+	spec    specFile
+	clients map[chan string]struct{}
+}
+
+// NewLive creates a Live handler that calls generate to create the specification.
+func NewLive(generate func() (*openapi3.T, error)) (l *Live, err error) {
+	l = &Live{
+		generate: generate,
+		mux:      http.NewServeMux(),
+		clients:  make(map[chan string]struct{}),
+	}
+	if err = l.Reload(); err != nil {
+		return nil, err
+	}
+	l.mux.Handle("/", http.FileServer(http.FS(swaggerUI)))
+	l.mux.HandleFunc("/swagger-ui/swagger.json", func(w http.ResponseWriter, r *http.Request) {
+		l.getSpec().serve(w, r)
+	})
+	l.mux.HandleFunc("/swagger-ui/events", l.serveEvents)
+	return l, nil
+}
+
+func (l *Live) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mux.ServeHTTP(w, r)
+}
+
+func (l *Live) getSpec() specFile {
+	l.m.Lock()
+	defer l.m.Unlock()
+	return l.spec
+}
+
+// Reload regenerates the specification, and notifies browsers if it has changed.
+// If generation fails, the previous specification continues to be served.
+func (l *Live) Reload() error {
+	spec, err := l.generate()
+	if err != nil {
+		return fmt.Errorf("swaggerui: failed to generate specification: %w", err)
+	}
+	f, err := newSpecFile(spec)
+	if err != nil {
+		return err
+	}
+	l.m.Lock()
+	defer l.m.Unlock()
+	if f.etag == l.spec.etag {
+		return nil
+	}
+	l.spec = f
+	for c := range l.clients {
+		select {
+		case c <- f.etag:
+		default:
+			// The client hasn't read the previous change yet.
+		}
+	}
+	return nil
+}
+
+// serveEvents sends the ETag of the specification when the browser connects, and
+// each time that the specification changes.
+func (l *Live) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	c := make(chan string, 1)
+	l.m.Lock()
+	l.clients[c] = struct{}{}
+	c <- l.spec.etag
+	l.m.Unlock()
+	defer func() {
+		l.m.Lock()
+		delete(l.clients, c)
+		l.m.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case etag := <-c:
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", etag); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// Watch polls the files in dirs every interval, and calls Reload when any of them
+// change, until the context is cancelled.
+//
+// Changes to Go types require the program to be rebuilt, but changes to comments
+// are picked up if generate creates a new API each time that it's called.
+func (l *Live) Watch(ctx context.Context, interval time.Duration, dirs ...string) error {
+	previous, err := getModTimes(dirs)
+	if err != nil {
+		return err
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		current, err := getModTimes(dirs)
+		if err != nil {
+			l.log("failed to read files", err)
+			continue
+		}
+		if sameModTimes(previous, current) {
+			continue
+		}
+		previous = current
+		if err = l.Reload(); err != nil {
+			l.log("failed to reload specification", err)
+		}
+	}
+}
+
+func (l *Live) log(msg string, err error) {
+	if l.Logger != nil {
+		l.Logger.Warn(msg, slog.Any("error", err))
+	}
+}
+
+func getModTimes(dirs []string) (modTimes map[string]time.Time, err error) {
+	modTimes = make(map[string]time.Time)
+	for _, dir := range dirs {
+		err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			modTimes[path] = info.ModTime()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return modTimes, nil
+}
+
+func sameModTimes(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, t := range a {
+		if !t.Equal(b[path]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package swaggerui
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestLiveReload(t *testing.T) {
+	var version atomic.Int64
+	l, err := NewLive(func() (*openapi3.T, error) {
+		return &openapi3.T{OpenAPI: "3.0.0", Info: &openapi3.Info{Title: "test", Version: string(rune('0' + version.Load()))}}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := httptest.NewServer(l)
+	defer s.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(s.URL + "/swagger-ui/events")
+	if err != nil {
+		t.Fatalf("failed to connect to events: %v", err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("expected content type text/event-stream, got %q", contentType)
+	}
+	events := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatalf("failed to read event: %v", err)
+		}
+		events.ReadString('\n')
+		return strings.TrimPrefix(strings.TrimSpace(line), "data: ")
+	}
+
+	initial := readEvent()
+	if initial != l.getSpec().etag {
+		t.Errorf("expected the initial event to be the ETag %s, got %s", l.getSpec().etag, initial)
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "api.go")
+	if err := os.WriteFile(file, []byte("package api"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go l.Watch(ctx, 10*time.Millisecond, dir)
+
+	// Wait for the watcher to read the initial state of the directory.
+	time.Sleep(50 * time.Millisecond)
+	version.Store(1)
+	if err := os.Chtimes(file, time.Now(), time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := readEvent()
+	if updated == initial {
+		t.Error("expected a new ETag after the specification changed")
+	}
+	if updated != l.getSpec().etag {
+		t.Errorf("expected the event to be the current ETag %s, got %s", l.getSpec().etag, updated)
+	}
+}
+
+func TestEventsWithoutLive(t *testing.T) {
+	h, err := New(&openapi3.T{OpenAPI: "3.0.0", Info: &openapi3.Info{Title: "test", Version: "0.0.0"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/swagger-ui/events", nil))
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected status 204, got %d", w.Code)
+	}
+}
@@ -17,4 +17,16 @@ window.onload = function() {
   });
 
   //</editor-fold>
+
+  // Reload the specification when it changes, see swaggerui.Live.
+  if (window.EventSource) {
+    var etag;
+    var events = new EventSource("./events");
+    events.onmessage = function(e) {
+      if (etag !== undefined && etag !== e.data) {
+        window.ui.specActions.download("./swagger.json");
+      }
+      etag = e.data;
+    };
+  }
 };
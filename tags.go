@@ -0,0 +1,70 @@
+package rest
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// WithTagPackages uses the package comments of the packages, usually in doc.go, as the
+// descriptions of tags with the same name as the package, e.g. the comment of
+// example.com/handlers/topics describes the "topics" tag. The packages that models are
+// defined in are used automatically, so this is only needed for other packages, such
+// as handlers.
+func WithTagPackages(pkgs ...string) APIOpts {
+	return func(api *API) {
+		api.TagPackages = append(api.TagPackages, pkgs...)
+	}
+}
+
+// addTagDescriptions adds tags to the spec for the tags of operations that have the same
+// name as a package with a package comment.
+func (api *API) addTagDescriptions(spec *openapi3.T) error {
+	used := make(map[string]bool)
+	for _, pathItem := range spec.Paths.Map() {
+		for _, op := range pathItem.Operations() {
+			for _, tag := range op.Tags {
+				used[tag] = true
+			}
+		}
+	}
+	if len(used) == 0 {
+		return nil
+	}
+	for _, pkg := range api.TagPackages {
+		if _, err := api.getCommentsForPackage(pkg); err != nil {
+			return err
+		}
+	}
+	descriptions := make(map[string]string)
+	for _, pkg := range getSortedKeys(api.comments) {
+		name := path.Base(pkg)
+		doc := api.comments[pkg][pkg]
+		if doc == "" || !used[name] {
+			continue
+		}
+		if _, exists := descriptions[name]; !exists {
+			descriptions[name] = getPackageDescription(name, doc)
+		}
+	}
+	for _, name := range getSortedKeys(descriptions) {
+		if spec.Tags.Get(name) == nil {
+			spec.Tags = append(spec.Tags, &openapi3.Tag{Name: name, Description: descriptions[name]})
+		}
+	}
+	return nil
+}
+
+// getPackageDescription removes the "Package name" prefix from a package comment, so
+// that "Package topics manages discussion topics." becomes "Manages discussion topics."
+func getPackageDescription(name, doc string) string {
+	rest, ok := strings.CutPrefix(doc, "Package "+name+" ")
+	if !ok {
+		return doc
+	}
+	r, size := utf8.DecodeRuneInString(rest)
+	return string(unicode.ToUpper(r)) + rest[size:]
+}
@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+)
+
+// Telemetry attribute keys. http.route follows the OpenTelemetry semantic conventions.
+const (
+	AttributeHTTPRoute   = "http.route"
+	AttributeOperationID = "rest.operation_id"
+)
+
+// Attribute is a telemetry attribute, e.g. of a span or metric.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// TelemetryAttributes returns the attributes that identify the route in telemetry,
+// i.e. the path of the route as http.route, and its OperationID, if it has one.
+func (rm *Route) TelemetryAttributes() (attrs []Attribute) {
+	attrs = append(attrs, Attribute{Key: AttributeHTTPRoute, Value: getPath(rm.Pattern)})
+	if rm.OperationID != "" {
+		attrs = append(attrs, Attribute{Key: AttributeOperationID, Value: rm.OperationID})
+	}
+	return attrs
+}
+
+type routeContextKey struct{}
+
+// RouteFromContext returns the route added to the context by TelemetryMiddleware.
+func RouteFromContext(ctx context.Context) (route *Route, ok bool) {
+	route, ok = ctx.Value(routeContextKey{}).(*Route)
+	return route, ok
+}
+
+// TelemetryMiddleware finds the documented route of each request, adds it to the
+// request context, and calls setAttributes with the route's TelemetryAttributes,
+// so that the API's routes name spans and metrics. For example, with OpenTelemetry:
+//
+//	api.TelemetryMiddleware(func(r *http.Request, attrs []rest.Attribute) {
+//		span := trace.SpanFromContext(r.Context())
+//		for _, attr := range attrs {
+//			span.SetAttributes(attribute.String(attr.Key, attr.Value))
+//		}
+//	})
+//
+// setAttributes isn't called for requests that don't match a route.
+func (api *API) TelemetryMiddleware(setAttributes func(r *http.Request, attrs []Attribute)) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, ok := api.matchRoute(r.Method, r.URL.Path)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), routeContextKey{}, route))
+			if setAttributes != nil {
+				setAttributes(r, route.TelemetryAttributes())
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
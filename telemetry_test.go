@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTelemetryMiddleware(t *testing.T) {
+	api := NewAPI("test")
+	api.Get("/users/{id}").HasOperationID("getUser")
+
+	var attrs []Attribute
+	var route *Route
+	mw := api.TelemetryMiddleware(func(r *http.Request, a []Attribute) {
+		attrs = a
+	})
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, _ = RouteFromContext(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/123", nil))
+
+	expected := []Attribute{
+		{Key: "http.route", Value: "/users/{id}"},
+		{Key: "rest.operation_id", Value: "getUser"},
+	}
+	if diff := cmp.Diff(expected, attrs); diff != "" {
+		t.Error(diff)
+	}
+	if route == nil || route.OperationID != "getUser" {
+		t.Errorf("expected the route to be in the request context, got %v", route)
+	}
+}
@@ -0,0 +1,37 @@
+package rest
+
+import (
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// prefixItemsExtension lists the schema of each item of a tuple, in order. OpenAPI 3.0
+// has no prefixItems keyword, so tools that understand tuples can read it from here.
+const prefixItemsExtension = "x-prefix-items"
+
+// WithTupleItems sets the array to be a tuple, where each item has its own schema, e.g.
+// a coordinate pair:
+//
+//	type Coordinates [2]float64
+//
+//	api.RegisterModel(rest.ModelOf[Coordinates](), rest.WithTupleItems(
+//		&openapi3.Schema{Type: &openapi3.Types{openapi3.TypeNumber}, Description: "Longitude."},
+//		&openapi3.Schema{Type: &openapi3.Types{openapi3.TypeNumber}, Description: "Latitude."},
+//	))
+//
+// The length of the array is fixed to the number of items, and each item must match
+// any of the schemas, since OpenAPI 3.0 can't describe the position of each item.
+func WithTupleItems(items ...*openapi3.Schema) ModelOpts {
+	return func(s *openapi3.Schema) {
+		s.Type = &openapi3.Types{openapi3.TypeArray}
+		s.WithMinItems(int64(len(items))).WithMaxItems(int64(len(items)))
+		refs := make(openapi3.SchemaRefs, len(items))
+		for i, item := range items {
+			refs[i] = openapi3.NewSchemaRef("", item)
+		}
+		s.Items = openapi3.NewSchemaRef("", &openapi3.Schema{AnyOf: refs})
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions[prefixItemsExtension] = refs
+	}
+}
@@ -0,0 +1,54 @@
+package rest
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// unitExtension is the unit of a quantity, e.g. ms or bytes, which clients can use to
+// format the value.
+const unitExtension = "x-unit"
+
+// WithUnit sets the unit of the quantity, e.g. ms or bytes, as x-unit, and appends it to
+// the description, so that it's shown in documentation that ignores extensions.
+//
+// Struct fields can use the unit tag instead, e.g. `unit:"ms"`.
+func WithUnit(unit string) ModelOpts {
+	return func(s *openapi3.Schema) {
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions[unitExtension] = unit
+		if s.Description == "" {
+			s.Description = fmt.Sprintf("Unit: %s.", unit)
+			return
+		}
+		s.Description += fmt.Sprintf("\n\nUnit: %s.", unit)
+	}
+}
+
+// wrapUnitTagRef wraps referenced schemas in allOf if the field has a unit tag, since
+// the unit and description of the field can't be added next to a $ref.
+func wrapUnitTagRef(f reflect.StructField, ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+	if _, ok := f.Tag.Lookup("unit"); !ok {
+		return ref
+	}
+	return wrapRef(ref)
+}
+
+// applyUnitTag sets the unit of a field from its unit tag, if it has one, e.g.
+//
+//	Timeout int `json:"timeout" unit:"ms"`
+func applyUnitTag(f reflect.StructField, s *openapi3.Schema) error {
+	unit, ok := f.Tag.Lookup("unit")
+	if !ok {
+		return nil
+	}
+	if unit == "" {
+		return fmt.Errorf("unit tag of field %q is empty", f.Name)
+	}
+	WithUnit(unit)(s)
+	return nil
+}
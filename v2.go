@@ -0,0 +1,149 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// SpecV2 creates a Swagger 2.0 (OpenAPI 2) specification document for the API, for
+// tools that don't support OpenAPI 3.0. The conversion is best-effort: models are
+// moved to definitions, request bodies become body parameters, and features that
+// Swagger 2.0 can't represent are removed and returned as warnings. In strict mode,
+// an error is returned instead.
+//
+// Nullable schemas are marked with the x-nullable extension, which is supported by
+// many Swagger 2.0 tools.
+func (api *API) SpecV2() (spec *openapi2.T, warnings []Warning, err error) {
+	spec3, err := api.Spec()
+	if err != nil {
+		return nil, nil, err
+	}
+	// Copy the spec, since it shares schemas with the API's models.
+	spec3, err = api.cloneSpec(spec3)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to copy spec: %w", err)
+	}
+	d := &v2Downgrade{seen: make(map[*openapi3.Schema]bool)}
+	d.downgrade(spec3)
+	if api.StrictMode && len(d.warnings) > 0 {
+		errs := make([]error, len(d.warnings))
+		for i, w := range d.warnings {
+			errs[i] = w
+		}
+		return nil, d.warnings, fmt.Errorf("strict mode: %w", errors.Join(errs...))
+	}
+	spec, err = openapi2conv.FromV3(spec3)
+	if err != nil {
+		return nil, d.warnings, fmt.Errorf("failed to convert spec to Swagger 2.0: %w", err)
+	}
+	if len(spec.Paths) > 0 {
+		spec.Consumes = []string{"application/json"}
+		spec.Produces = []string{"application/json"}
+	}
+	return spec, d.warnings, nil
+}
+
+// v2Downgrade removes the parts of a spec that can't be represented in Swagger 2.0.
+// The warnings are kept apart from the API's, since they don't apply to Spec.
+type v2Downgrade struct {
+	warnings []Warning
+	// seen is the set of schemas that have been downgraded.
+	seen map[*openapi3.Schema]bool
+}
+
+func (d *v2Downgrade) warn(location string, format string, args ...any) {
+	w := Warning{
+		Kind:     WarningUnrepresentableInV2,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if !slices.Contains(d.warnings, w) {
+		d.warnings = append(d.warnings, w)
+	}
+}
+
+func (d *v2Downgrade) downgrade(spec *openapi3.T) {
+	if len(spec.Servers) > 1 {
+		d.warn("servers", "only the first server is used as the host and base path")
+	}
+	if spec.Components != nil {
+		for _, name := range getSortedKeys(spec.Components.Schemas) {
+			d.downgradeSchema(name, spec.Components.Schemas[name])
+		}
+	}
+	for _, path := range spec.Paths.InMatchingOrder() {
+		pathItem := spec.Paths.Value(path)
+		for method, op := range pathItem.Operations() {
+			location := method + " " + path
+			for _, p := range append(pathItem.Parameters, op.Parameters...) {
+				if p.Value == nil {
+					continue
+				}
+				if p.Value.In == openapi3.ParameterInCookie {
+					d.warn(location, "cookie parameter %q is not supported", p.Value.Name)
+				}
+				d.downgradeSchema(location, p.Value.Schema)
+			}
+			if op.RequestBody != nil && op.RequestBody.Value != nil {
+				d.downgradeContent(location, op.RequestBody.Value.Content)
+			}
+			if op.Responses == nil {
+				continue
+			}
+			for _, response := range op.Responses.Map() {
+				if response.Value != nil {
+					d.downgradeContent(location, response.Value.Content)
+				}
+			}
+		}
+	}
+}
+
+func (d *v2Downgrade) downgradeContent(location string, content openapi3.Content) {
+	if len(content) > 1 {
+		d.warn(location, "only one media type is supported for each request and response")
+	}
+	for _, mediaType := range content {
+		if mediaType != nil {
+			d.downgradeSchema(location, mediaType.Schema)
+		}
+	}
+}
+
+func (d *v2Downgrade) downgradeSchema(location string, ref *openapi3.SchemaRef) {
+	if ref == nil || ref.Value == nil || d.seen[ref.Value] {
+		return
+	}
+	s := ref.Value
+	d.seen[s] = true
+	if s.Nullable {
+		s.Nullable = false
+		if s.Extensions == nil {
+			s.Extensions = make(map[string]any)
+		}
+		s.Extensions["x-nullable"] = true
+	}
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		d.warn(location, "oneOf and anyOf are not supported, so the schema allows any value")
+		s.OneOf, s.AnyOf = nil, nil
+	}
+	if s.Not != nil {
+		d.warn(location, "not is not supported")
+		s.Not = nil
+	}
+	if s.WriteOnly {
+		d.warn(location, "writeOnly is not supported")
+		s.WriteOnly = false
+	}
+	for _, name := range getSortedKeys(s.Properties) {
+		d.downgradeSchema(location, s.Properties[name])
+	}
+	for _, child := range getChildSchemas(s) {
+		d.downgradeSchema(location, child)
+	}
+}
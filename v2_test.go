@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestSpecV2(t *testing.T) {
+	api := NewAPI("test")
+	api.Post("/users").
+		HasRequestModel(ModelOf[User]()).
+		HasResponseModel(http.StatusOK, ModelOf[KnownTypes]())
+	api.Get("/any").
+		HasResponseModel(http.StatusOK, ModelOf[OK](), ModelOpts(func(s *openapi3.Schema) {
+			s.OneOf = openapi3.SchemaRefs{openapi3.NewSchemaRef("", openapi3.NewStringSchema())}
+		}))
+
+	spec, warnings, err := api.SpecV2()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	if spec.Swagger != "2.0" {
+		t.Errorf("expected swagger 2.0, got %q", spec.Swagger)
+	}
+	if _, ok := spec.Definitions["github_com_heimspiel_rest_User"]; !ok {
+		t.Errorf("expected the User model to be a definition, got %v", getSortedKeys(spec.Definitions))
+	}
+
+	op := spec.Paths["/users"].Post
+	if op == nil || len(op.Parameters) != 1 || op.Parameters[0].In != "body" {
+		t.Fatalf("expected the request body to be a body parameter, got %#v", op)
+	}
+	if ref := op.Parameters[0].Schema.Ref; ref != "#/definitions/github_com_heimspiel_rest_User" {
+		t.Errorf("expected the body parameter to reference the User definition, got %q", ref)
+	}
+
+	timePtr := spec.Definitions["github_com_heimspiel_rest_KnownTypes"].Value.Properties["timePtr"].Value
+	if timePtr.Nullable || timePtr.Extensions["x-nullable"] != true {
+		t.Errorf("expected nullable to be replaced with x-nullable, got %#v", timePtr)
+	}
+
+	var found bool
+	for _, w := range warnings {
+		if w.Kind == WarningUnrepresentableInV2 && w.Location == "GET /any" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a warning about oneOf, got %v", warnings)
+	}
+	for _, w := range api.Warnings() {
+		if w.Kind == WarningUnrepresentableInV2 {
+			t.Errorf("expected the API's warnings to be unchanged, got %v", w)
+		}
+	}
+
+	// The API's own spec is unchanged.
+	spec3, err := api.Spec()
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	if !spec3.Components.Schemas["github_com_heimspiel_rest_KnownTypes"].Value.Properties["timePtr"].Value.Nullable {
+		t.Error("expected the OpenAPI 3 spec to be unchanged")
+	}
+}
+
+func TestSpecV2StrictMode(t *testing.T) {
+	api := NewAPI("test", WithStrictMode())
+	api.Get("/any").
+		HasResponseModel(http.StatusOK, ModelOf[OK](), ModelOpts(func(s *openapi3.Schema) {
+			s.Not = openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+		})).
+		HasResponseDescription(http.StatusOK, "Any value except a string.")
+
+	if _, _, err := api.SpecV2(); err == nil {
+		t.Error("expected an error in strict mode")
+	}
+	// The Swagger 2.0 warnings don't apply to the OpenAPI 3 spec.
+	if _, err := api.Spec(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// validationErrorResponse is the name of the response component added to routes whose
+// requests are validated.
+const validationErrorResponse = "ValidationError"
+
+// ValidationErrorResponse is the response returned by validation middleware for invalid
+// requests, see WithValidationErrorResponse.
+type ValidationErrorResponse struct {
+	// Status of the response, e.g. http.StatusUnprocessableEntity. Defaults to 400.
+	Status int
+	// Model of the body of the response. If the type is nil, the response has no body.
+	Model Model
+	// Description of the response. Defaults to "The request is invalid.".
+	Description string
+}
+
+// WithValidationErrorResponse documents the response of validation middleware on routes
+// with constraints on their requests, so that the specification matches what clients
+// receive. It's added as the ValidationError response component, and referenced by
+// routes that have a request body, path or query parameters with a pattern or a type
+// other than string, required query parameters, or fields and sort parameters. Routes
+// that already have a response with the status keep it.
+func WithValidationErrorResponse(r ValidationErrorResponse) APIOpts {
+	return func(api *API) {
+		if r.Status == 0 {
+			r.Status = http.StatusBadRequest
+		}
+		if r.Description == "" {
+			r.Description = "The request is invalid."
+		}
+		api.ValidationErrorResponse = &r
+	}
+}
+
+// hasRequestConstraints returns true if requests to the route can be rejected by
+// validation middleware.
+func hasRequestConstraints(route *Route) bool {
+	if route.Models.Request.Type != nil || route.FieldsModel != nil || route.Sort != nil {
+		return true
+	}
+	for _, p := range route.Params.Path {
+		if p.Regexp != "" || !isStringPrimitiveType(p.Type) {
+			return true
+		}
+	}
+	for _, p := range route.Params.Query {
+		if p.Regexp != "" || p.Required || !isStringPrimitiveType(p.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func isStringPrimitiveType(t PrimitiveType) bool {
+	return t == "" || t == PrimitiveTypeString
+}
+
+// addValidationErrorResponse adds the validation error response to the operation, and
+// its component to the specification.
+func (api *API) addValidationErrorResponse(spec *openapi3.T, op *openapi3.Operation) error {
+	r := api.ValidationErrorResponse
+	if op.Responses == nil {
+		op.Responses = openapi3.NewResponses()
+	}
+	if op.Responses.Status(r.Status) != nil {
+		return nil
+	}
+	if spec.Components.Responses == nil {
+		spec.Components.Responses = make(openapi3.ResponseBodies)
+	}
+	if _, ok := spec.Components.Responses[validationErrorResponse]; !ok {
+		resp := openapi3.NewResponse().WithDescription(r.Description)
+		if r.Model.Type != nil {
+			name, schema, err := api.RegisterModel(r.Model)
+			if err != nil {
+				return err
+			}
+			resp.WithContent(openapi3.Content{
+				r.Model.getContentType(): {Schema: api.getSchemaReferenceOrValue(name, schema)},
+			})
+		}
+		spec.Components.Responses[validationErrorResponse] = &openapi3.ResponseRef{Value: resp}
+	}
+	op.Responses.Set(strconv.Itoa(r.Status), &openapi3.ResponseRef{
+		Ref: "#/components/responses/" + validationErrorResponse,
+	})
+	return nil
+}
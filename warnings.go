@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+// WithStrictMode returns an error from Spec if any warnings are found while
+// creating the specification.
+func WithStrictMode() APIOpts {
+	return func(api *API) {
+		api.StrictMode = true
+	}
+}
+
+// WarningKind is the category of a Warning.
+type WarningKind string
+
+const (
+	// WarningUnknownParameterType is used when a parameter has a PrimitiveType
+	// that isn't known, so the type is copied into the specification as-is.
+	// Use RegisterPrimitiveType to add types.
+	WarningUnknownParameterType WarningKind = "unknown-parameter-type"
+	// WarningSkippedField is used when a struct field has a json tag, but is not
+	// included in the schema because it is not exported.
+	WarningSkippedField WarningKind = "skipped-field"
+	// WarningUnsupportedField is used when a struct field is omitted from the schema
+	// because its type is not supported, see WithSkipUnsupportedFields.
+	WarningUnsupportedField WarningKind = "unsupported-field"
+	// WarningNameCollision is used when two different types have the same schema
+	// name, e.g. because their package paths are stripped.
+	WarningNameCollision WarningKind = "name-collision"
+	// WarningMissingResponseDescription is used when a response has no description.
+	WarningMissingResponseDescription WarningKind = "missing-response-description"
+	// WarningUnrepresentableInV2 is used when SpecV2 removes a feature that can't
+	// be represented in Swagger 2.0. These warnings are returned by SpecV2, and not
+	// by Warnings.
+	WarningUnrepresentableInV2 WarningKind = "unrepresentable-in-v2"
+)
+
+// Warning is a problem found while creating the specification that doesn't
+// prevent the specification from being created, unless strict mode is enabled.
+type Warning struct {
+	// Kind of problem.
+	Kind WarningKind
+	// Location of the problem, e.g. "GET /users" or a type name.
+	Location string
+	// Message describing the problem.
+	Message string
+}
+
+func (w Warning) Error() string {
+	return fmt.Sprintf("%s: %s: %s", w.Kind, w.Location, w.Message)
+}
+
+// Warnings returns the problems found while registering models and creating
+// the specification.
+func (api *API) Warnings() []Warning {
+	return slices.Clone(api.warnings)
+}
+
+// warn records and logs a problem found while creating the specification.
+func (api *API) warn(kind WarningKind, location string, format string, args ...any) {
+	w := Warning{
+		Kind:     kind,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	}
+	if api.warned[w] {
+		return
+	}
+	if api.warned == nil {
+		api.warned = make(map[Warning]bool)
+	}
+	api.warned[w] = true
+	api.warnings = append(api.warnings, w)
+	if api.Logger != nil {
+		api.Logger.Warn(w.Message, slog.String("kind", string(w.Kind)), slog.String("location", w.Location))
+	}
+}
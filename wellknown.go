@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// InternalExtension marks operations that are excluded from public documentation, by
+// generating the spec with Filter{ExcludeExtensions: []string{InternalExtension}}.
+const InternalExtension = "x-internal"
+
+// Content types of the metadata routes.
+const (
+	OpenAPIContentType = "application/vnd.oai.openapi+json;version=3.0"
+	MetricsContentType = "text/plain; version=0.0.4"
+)
+
+// IsInternal marks the route as internal, so that it can be excluded from public documentation.
+func (rm *Route) IsInternal() *Route {
+	return rm.HasExtension(InternalExtension, true)
+}
+
+// OpenAPIDocument is an OpenAPI specification.
+type OpenAPIDocument struct{}
+
+// ApplyCustomSchema allows the properties of the specification.
+func (OpenAPIDocument) ApplyCustomSchema(s *openapi3.Schema) {
+	allow := true
+	s.AdditionalProperties = openapi3.AdditionalProperties{Has: &allow}
+}
+
+// HasOpenAPIRoute adds the /.well-known/openapi route, which returns the OpenAPI
+// specification of the API.
+func (api *API) HasOpenAPIRoute() *Route {
+	model := ModelOf[OpenAPIDocument]()
+	model.contentType = OpenAPIContentType
+	return api.Get("/.well-known/openapi").
+		HasOperationID("getOpenAPI").
+		HasDescription("Returns the OpenAPI specification of the API.").
+		HasResponseModel(http.StatusOK, model).
+		HasResponseDescription(http.StatusOK, "The OpenAPI specification.")
+}
+
+// HasVersionRoute adds the /version route, which returns the version of the service.
+func (api *API) HasVersionRoute() *Route {
+	return api.addVersionRoute("/version")
+}
+
+func (api *API) addVersionRoute(path string) *Route {
+	return api.Get(path).
+		HasOperationID("getVersion").
+		HasDescription("Returns the version of the service.").
+		HasResponseModel(http.StatusOK, ModelOf[VersionResponse]()).
+		HasResponseDescription(http.StatusOK, "The version of the service.")
+}
+
+// HasMetricsRoute adds the /metrics route, which returns metrics in the Prometheus text
+// exposition format, e.g. from MetricsHandler. The route is internal, see IsInternal.
+func (api *API) HasMetricsRoute() *Route {
+	model := ModelOf[string]()
+	model.contentType = MetricsContentType
+	return api.Get("/metrics").
+		HasOperationID("getMetrics").
+		HasDescription("Returns metrics in the Prometheus text exposition format.").
+		HasResponseModel(http.StatusOK, model).
+		HasResponseDescription(http.StatusOK, "The metrics.").
+		IsInternal()
+}
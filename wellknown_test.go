@@ -0,0 +1,25 @@
+package rest
+
+import (
+	"testing"
+)
+
+func TestInternalRoutesAreExcludedFromPublicDocs(t *testing.T) {
+	api := NewAPI("test")
+	api.HasOpenAPIRoute()
+	api.HasVersionRoute()
+	api.HasMetricsRoute()
+
+	spec, err := api.SpecWith(Filter{ExcludeExtensions: []string{InternalExtension}})
+	if err != nil {
+		t.Fatalf("failed to create spec: %v", err)
+	}
+	for _, path := range []string{"/.well-known/openapi", "/version"} {
+		if spec.Paths.Value(path) == nil {
+			t.Errorf("expected %s to be public", path)
+		}
+	}
+	if spec.Paths.Value("/metrics") != nil {
+		t.Error("expected /metrics to be excluded")
+	}
+}
@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"encoding/xml"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// XMLContentType is the content type of XML request and response bodies.
+const XMLContentType = "application/xml"
+
+// HasXML documents that the request and response bodies of the route can also be XML,
+// alongside JSON. The names of XML elements and attributes are taken from the xml tags
+// of struct fields, and the XMLName field of structs. Fields without an xml tag are
+// documented with the name of their JSON property, whereas encoding/xml uses the name
+// of the field, so tag the fields of XML models.
+func (rm *Route) HasXML() *Route {
+	rm.XML = true
+	return rm
+}
+
+// getContent returns the content of a request or response body, which can also be XML
+// if the route supports it.
+func getContent(route *Route, model Model, ref *openapi3.SchemaRef) openapi3.Content {
+	content := openapi3.Content{
+		model.getContentType(): {Schema: ref},
+	}
+	if route.XML {
+		content[XMLContentType] = &openapi3.MediaType{Schema: ref}
+	}
+	return content
+}
+
+// WithXMLName sets the name of the XML element or attribute.
+func WithXMLName(name string) ModelOpts {
+	return func(s *openapi3.Schema) {
+		getXML(s).Name = name
+	}
+}
+
+// WithXMLAttribute sets the property to be an XML attribute, rather than an element.
+func WithXMLAttribute() ModelOpts {
+	return func(s *openapi3.Schema) {
+		getXML(s).Attribute = true
+	}
+}
+
+// WithXMLWrapped sets the items of the array to be wrapped in an XML element, e.g.
+// <users><user/><user/></users>, rather than repeated in their parent.
+func WithXMLWrapped() ModelOpts {
+	return func(s *openapi3.Schema) {
+		getXML(s).Wrapped = true
+	}
+}
+
+func getXML(s *openapi3.Schema) *openapi3.XML {
+	if s.XML == nil {
+		s.XML = &openapi3.XML{}
+	}
+	return s.XML
+}
+
+var xmlNameType = reflect.TypeFor[xml.Name]()
+
+// getXMLName returns the XML object of a struct from the tag of its XMLName field, e.g.
+// XMLName xml.Name `xml:"user"`, or nil if it doesn't have one.
+func getXMLName(t reflect.Type) *openapi3.XML {
+	f, ok := t.FieldByName("XMLName")
+	if !ok || f.Type != xmlNameType {
+		return nil
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("xml"), ",")
+	if name == "" {
+		return nil
+	}
+	x := &openapi3.XML{}
+	x.Namespace, x.Name = parseXMLName(name)
+	return x
+}
+
+// parseXMLName splits the namespace from the name, as encoding/xml does, e.g.
+// "http://example.com/ns user".
+func parseXMLName(name string) (namespace, local string) {
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+// applyXMLTag sets the XML object of a field from its xml tag, if it has one. Referenced
+// schemas can't be changed, so they're wrapped in allOf.
+//
+// Slices with a parent element, e.g. `xml:"users>user"`, are wrapped. Tags that
+// OpenAPI can't describe, e.g. chardata and innerxml, are ignored.
+func applyXMLTag(f reflect.StructField, fieldName string, ref *openapi3.SchemaRef) *openapi3.SchemaRef {
+	tag, ok := f.Tag.Lookup("xml")
+	if !ok {
+		return ref
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	flags := strings.Split(options, ",")
+	if name == "-" || slices.ContainsFunc(flags, func(flag string) bool {
+		return flag == "chardata" || flag == "cdata" || flag == "innerxml" || flag == "comment" || flag == "any"
+	}) {
+		return ref
+	}
+	x := &openapi3.XML{Attribute: slices.Contains(flags, "attr")}
+	parent, name, nested := cutLast(name, ">")
+	x.Namespace, x.Name = parseXMLName(name)
+	if x.Name == fieldName {
+		x.Name = ""
+	}
+	t := f.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	isEmpty := x.Name == "" && x.Namespace == "" && !x.Attribute
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && ref.Value != nil {
+		// The name of each item is the name of the element, and the parent element, if
+		// there is one, wraps the items.
+		array := *ref.Value
+		if nested {
+			array.XML = &openapi3.XML{Name: parent, Wrapped: true}
+		}
+		if !isEmpty {
+			array.Items = withXML(array.Items, x)
+		}
+		return openapi3.NewSchemaRef("", &array)
+	}
+	if isEmpty {
+		return ref
+	}
+	return withXML(ref, x)
+}
+
+func withXML(ref *openapi3.SchemaRef, x *openapi3.XML) *openapi3.SchemaRef {
+	s := *wrapRef(ref).Value
+	s.XML = x
+	return openapi3.NewSchemaRef("", &s)
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return "", s, false
+}